@@ -0,0 +1,198 @@
+// Package auth provides optional access control for shared deployments: local
+// accounts with bcrypt-hashed passwords or a shared invite token, signed
+// session cookies for browsers, personal API tokens for JSON and CLI
+// clients, and an admin role for maintenance actions.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/config"
+)
+
+// Mode selects how visitors sign in.
+type Mode string
+
+const (
+	ModeNone     Mode = "none"     // no sign-in; the server is open to anyone who can reach it
+	ModeAccounts Mode = "accounts" // local accounts from the users file
+	ModeInvite   Mode = "invite"   // anyone holding the shared invite token
+)
+
+// Role grants access levels to an account.
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin" // may also refresh the cache and rotate the Riot API key
+)
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
+
+// Principal is an authenticated caller. Guests admitted by the invite token
+// have no name.
+type Principal struct {
+	Name  string
+	Role  Role
+	Token string // ID of the personal API token used, if any
+}
+
+// IsAdmin reports whether p may perform admin actions.
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// DisplayName is how p is shown in the page header.
+func (p Principal) DisplayName() string {
+	if p.Name == "" {
+		return "Guest"
+	}
+	return p.Name
+}
+
+// CookieName names the session cookie.
+const CookieName = "lolmatchup_session"
+
+// Authenticator resolves the caller of each request according to the
+// configured Mode. The zero Mode behaves like ModeNone.
+type Authenticator struct {
+	Mode        Mode
+	Users       *Store // accounts mode
+	InviteToken string // invite mode
+	Sessions    *Sessions
+}
+
+// New builds the Authenticator configured by cfg, opening the users file in
+// accounts mode.
+func New(cfg *config.AppConfig) (*Authenticator, error) {
+	a := &Authenticator{Mode: Mode(cfg.AuthMode)}
+	switch a.Mode {
+	case "", ModeNone:
+		a.Mode = ModeNone
+		return a, nil
+	case ModeAccounts:
+		users, err := OpenStore(cfg.UsersFile)
+		if err != nil {
+			return nil, err
+		}
+		a.Users = users
+	case ModeInvite:
+		if cfg.InviteToken == "" {
+			return nil, fmt.Errorf("auth_mode invite requires invite_token")
+		}
+		a.InviteToken = cfg.InviteToken
+	default:
+		return nil, fmt.Errorf("unknown auth_mode %q (use none, accounts or invite)", cfg.AuthMode)
+	}
+	if cfg.SessionHours <= 0 {
+		return nil, fmt.Errorf("session_hours must be positive, got %d", cfg.SessionHours)
+	}
+	sessions, err := NewSessions(cfg.SessionSecret, time.Duration(cfg.SessionHours)*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	a.Sessions = sessions
+	return a, nil
+}
+
+// Enabled reports whether visitors must sign in. A nil Authenticator is
+// disabled.
+func (a *Authenticator) Enabled() bool {
+	return a != nil && a.Mode != ModeNone && a.Mode != ""
+}
+
+// Accounts reports whether sign-in uses local accounts, which is what makes
+// personal API tokens available.
+func (a *Authenticator) Accounts() bool {
+	return a.Enabled() && a.Mode == ModeAccounts && a.Users != nil
+}
+
+// Authenticate resolves the caller of r from an "Authorization: Bearer"
+// personal API token (or, in invite mode, the invite token itself) or from a
+// session cookie.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, bool) {
+	if !a.Enabled() {
+		return Principal{}, false
+	}
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		token := strings.TrimPrefix(h, "Bearer ")
+		switch {
+		case a.Accounts():
+			if p, ok := a.Users.AuthenticateToken(token); ok {
+				return p, true
+			}
+		case equalSecret(token, a.InviteToken):
+			return Principal{Role: RoleUser}, true
+		}
+	}
+	cookie, err := r.Cookie(CookieName)
+	if err != nil {
+		return Principal{}, false
+	}
+	p, ok := a.Sessions.Verify(cookie.Value)
+	if !ok {
+		return Principal{}, false
+	}
+	if a.Accounts() {
+		// Sessions follow role changes and end when the account is removed.
+		return a.Users.principal(p.Name)
+	}
+	return p, true
+}
+
+// Login checks sign-in credentials: a user name and password in accounts
+// mode, or the invite token (as secret) in invite mode.
+func (a *Authenticator) Login(name, secret string) (Principal, bool) {
+	switch {
+	case a.Accounts():
+		return a.Users.Authenticate(name, secret)
+	case a.Enabled() && a.Mode == ModeInvite && equalSecret(secret, a.InviteToken):
+		return Principal{Role: RoleUser}, true
+	}
+	return Principal{}, false
+}
+
+// SessionCookie returns the cookie signing p in. secure marks it HTTPS-only.
+func (a *Authenticator) SessionCookie(p Principal, secure bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     CookieName,
+		Value:    a.Sessions.Issue(p),
+		Path:     "/",
+		MaxAge:   int(a.Sessions.TTL.Seconds()),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// ClearCookie returns a cookie that removes the session cookie.
+func ClearCookie(secure bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     CookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated caller.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the caller stored by WithPrincipal.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
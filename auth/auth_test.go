@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/klnstprx/lolMatchup/config"
+)
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name    string
+		set     func(*config.AppConfig)
+		wantErr bool
+		enabled bool
+	}{
+		{"default", func(*config.AppConfig) {}, false, false},
+		{"accounts", func(c *config.AppConfig) { c.AuthMode = "accounts" }, false, true},
+		{"invite", func(c *config.AppConfig) { c.AuthMode = "invite"; c.InviteToken = "join-us" }, false, true},
+		{"invite without token", func(c *config.AppConfig) { c.AuthMode = "invite" }, true, false},
+		{"unknown mode", func(c *config.AppConfig) { c.AuthMode = "ldap" }, true, false},
+		{"zero session length", func(c *config.AppConfig) { c.AuthMode = "accounts"; c.SessionHours = 0 }, true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.New()
+			cfg.UsersFile = filepath.Join(dir, "users.json")
+			tc.set(cfg)
+			a, err := New(cfg)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err == nil && a.Enabled() != tc.enabled {
+				t.Errorf("Enabled() = %v, want %v", a.Enabled(), tc.enabled)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_Accounts(t *testing.T) {
+	cfg := config.New()
+	cfg.AuthMode = "accounts"
+	cfg.UsersFile = filepath.Join(t.TempDir(), "users.json")
+	a, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Users.SetUser("alice", "correct horse", RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	raw, _, err := a.Users.CreateToken("alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, ok := a.Authenticate(req); ok {
+		t.Error("an anonymous request should not authenticate")
+	}
+
+	req.Header.Set("Authorization", "Bearer "+raw)
+	if p, ok := a.Authenticate(req); !ok || p.Name != "alice" {
+		t.Errorf("token: %+v, %v", p, ok)
+	}
+
+	p, ok := a.Login("alice", "correct horse")
+	if !ok {
+		t.Fatal("Login() failed")
+	}
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(a.SessionCookie(p, false))
+	if p, ok := a.Authenticate(req); !ok || !p.IsAdmin() {
+		t.Errorf("session: %+v, %v", p, ok)
+	}
+
+	// Sessions follow the account: demotion and removal apply at once.
+	if _, err := a.Users.SetUser("alice", "correct horse", RoleUser); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := a.Authenticate(req); !ok || p.IsAdmin() {
+		t.Errorf("after demotion: %+v, %v", p, ok)
+	}
+	if err := a.Users.RemoveUser("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.Authenticate(req); ok {
+		t.Error("a removed account's session should be rejected")
+	}
+}
+
+func TestAuthenticator_Invite(t *testing.T) {
+	cfg := config.New()
+	cfg.AuthMode = "invite"
+	cfg.InviteToken = "join-us"
+	a, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.Login("", "wrong"); ok {
+		t.Error("a wrong invite token should fail")
+	}
+	p, ok := a.Login("", "join-us")
+	if !ok || p.Name != "" || p.IsAdmin() || p.DisplayName() != "Guest" {
+		t.Errorf("Login() = %+v, %v", p, ok)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer join-us")
+	if _, ok := a.Authenticate(req); !ok {
+		t.Error("the invite token should work as a bearer token")
+	}
+}
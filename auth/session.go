@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sessions issues and verifies signed session cookies. A session is the
+// signed identity and expiry, so any instance sharing the secret can verify
+// it without shared state; signing out clears the cookie.
+type Sessions struct {
+	secret []byte
+	TTL    time.Duration
+	now    func() time.Time
+}
+
+// sessionClaims is the signed content of a session cookie.
+type sessionClaims struct {
+	Name    string `json:"n,omitempty"`
+	Role    Role   `json:"r"`
+	Expires int64  `json:"e"`
+}
+
+// NewSessions returns Sessions signed with secret. An empty secret generates
+// a random one, so sessions end when the process restarts and are not
+// accepted by other instances.
+func NewSessions(secret string, ttl time.Duration) (*Sessions, error) {
+	key := []byte(secret)
+	if secret == "" {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generating session secret: %w", err)
+		}
+	}
+	return &Sessions{secret: key, TTL: ttl, now: time.Now}, nil
+}
+
+// Issue returns a session cookie value for p.
+func (s *Sessions) Issue(p Principal) string {
+	claims := sessionClaims{Name: p.Name, Role: p.Role, Expires: s.now().Add(s.TTL).Unix()}
+	payload, _ := json.Marshal(claims)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded)
+}
+
+// Verify returns the principal of a session cookie value, if it is
+// correctly signed and unexpired.
+func (s *Sessions) Verify(value string) (Principal, bool) {
+	encoded, sig, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(encoded))) {
+		return Principal{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Principal{}, false
+	}
+	var claims sessionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || s.now().Unix() >= claims.Expires {
+		return Principal{}, false
+	}
+	return Principal{Name: claims.Name, Role: claims.Role}, true
+}
+
+// sign returns the base64 HMAC-SHA256 of encoded.
+func (s *Sessions) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessions(t *testing.T) {
+	s, err := NewSessions("secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := s.Issue(Principal{Name: "alice", Role: RoleAdmin})
+	if p, ok := s.Verify(value); !ok || p.Name != "alice" || p.Role != RoleAdmin {
+		t.Fatalf("Verify() = %+v, %v", p, ok)
+	}
+
+	payload, sig, _ := strings.Cut(value, ".")
+	forged := strings.Replace(payload, payload[:4], "AAAA", 1) + "." + sig
+	if _, ok := s.Verify(forged); ok {
+		t.Error("a modified session should be rejected")
+	}
+	other, _ := NewSessions("other secret", time.Hour)
+	if _, ok := other.Verify(value); ok {
+		t.Error("a session signed with another secret should be rejected")
+	}
+
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, ok := s.Verify(value); ok {
+		t.Error("an expired session should be rejected")
+	}
+}
+
+func TestNewSessions_RandomSecret(t *testing.T) {
+	a, _ := NewSessions("", time.Hour)
+	b, _ := NewSessions("", time.Hour)
+	if _, ok := b.Verify(a.Issue(Principal{Role: RoleUser})); ok {
+		t.Error("generated secrets should differ between processes")
+	}
+}
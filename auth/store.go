@@ -0,0 +1,409 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/fileutil"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TokenPrefix starts every personal API token, so leaked tokens are easy to
+// recognise and grep for.
+const TokenPrefix = "lmt_"
+
+// MinPasswordLength is the shortest password SetUser accepts.
+const MinPasswordLength = 8
+
+var (
+	ErrUserNotFound  = errors.New("user not found")
+	ErrTokenNotFound = errors.New("token not found")
+	ErrInvalidName   = errors.New("user names may only contain letters, digits, '.', '-' and '_'")
+	ErrWeakPassword  = fmt.Errorf("passwords must be at least %d characters", MinPasswordLength)
+)
+
+// User is a local account. Passwords are stored as bcrypt hashes and API
+// tokens as SHA-256 hashes, so the users file never holds a usable secret.
+type User struct {
+	Name         string    `json:"name"`
+	Role         Role      `json:"role"`
+	PasswordHash string    `json:"password_hash"`
+	Tokens       []Token   `json:"tokens,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Token describes a personal API token. The token itself is shown once when
+// it is created; only its hash is kept.
+type Token struct {
+	ID        string    `json:"id"`
+	Label     string    `json:"label,omitempty"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// usersFile is the on-disk layout of the users file.
+type usersFile struct {
+	Users []*User `json:"users"`
+}
+
+// reloadInterval bounds how often the users file is checked for changes made
+// by another process, such as the user subcommand.
+const reloadInterval = time.Second
+
+// Store holds local accounts in a JSON file. Changes are written back
+// atomically, and changes made to the file by another process are picked up
+// on the next lookup, so accounts can be managed from the command line while
+// the server runs.
+type Store struct {
+	path string
+
+	mu        sync.Mutex
+	users     map[string]*User
+	tokens    map[string]tokenRef // token hash to owner
+	modTime   time.Time
+	size      int64
+	lastCheck time.Time
+}
+
+// tokenRef locates a token by its hash.
+type tokenRef struct {
+	user string
+	id   string
+}
+
+// dummyHash is compared against when a login names an unknown user, so the
+// response time does not reveal which names exist.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("lolmatchup-dummy-password"), bcrypt.DefaultCost)
+
+// OpenStore loads the users file at path. A missing file is an empty store;
+// it is created on the first change.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Path returns the users file location.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// load reads the users file, replacing the in-memory state. s.mu must be held
+// or s not yet shared.
+func (s *Store) load() error {
+	s.users = make(map[string]*User)
+	s.tokens = make(map[string]tokenRef)
+	s.modTime, s.size = time.Time{}, 0
+
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading users file: %w", err)
+	}
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("reading users file: %w", err)
+	}
+	var f usersFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return fmt.Errorf("decoding users file %s: %w", s.path, err)
+	}
+	for _, u := range f.Users {
+		if u == nil || u.Name == "" {
+			continue
+		}
+		s.users[strings.ToLower(u.Name)] = u
+		for _, t := range u.Tokens {
+			s.tokens[t.Hash] = tokenRef{user: u.Name, id: t.ID}
+		}
+	}
+	s.modTime, s.size = info.ModTime(), info.Size()
+	return nil
+}
+
+// refresh reloads the users file if another process changed it, checking at
+// most once per reloadInterval. s.mu must be held. A file that cannot be
+// read keeps the current state.
+func (s *Store) refresh() {
+	now := time.Now()
+	if now.Sub(s.lastCheck) < reloadInterval {
+		return
+	}
+	s.lastCheck = now
+	info, err := os.Stat(s.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if len(s.users) > 0 {
+			s.users = make(map[string]*User)
+			s.tokens = make(map[string]tokenRef)
+		}
+		return
+	case err != nil:
+		return
+	case info.ModTime().Equal(s.modTime) && info.Size() == s.size:
+		return
+	}
+	users, tokens, modTime, size := s.users, s.tokens, s.modTime, s.size
+	if s.load() != nil {
+		s.users, s.tokens, s.modTime, s.size = users, tokens, modTime, size
+	}
+}
+
+// save writes the users file atomically with owner-only permissions.
+// s.mu must be held.
+func (s *Store) save() error {
+	f := usersFile{Users: make([]*User, 0, len(s.users))}
+	for _, u := range s.users {
+		f.Users = append(f.Users, u)
+	}
+	sort.Slice(f.Users, func(i, j int) bool { return f.Users[i].Name < f.Users[j].Name })
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding users file: %w", err)
+	}
+
+	if err := fileutil.WriteFileAtomic(s.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing users file: %w", err)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime, s.size = info.ModTime(), info.Size()
+	}
+	return nil
+}
+
+// Len returns the number of accounts.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	return len(s.users)
+}
+
+// Users returns copies of every account, sorted by name, without password or
+// token hashes.
+func (s *Store) Users() []User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	out := make([]User, 0, len(s.users))
+	for _, u := range s.users {
+		out = append(out, redact(u))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Lookup returns a copy of the account called name, without secrets.
+func (s *Store) Lookup(name string) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	u, ok := s.users[strings.ToLower(name)]
+	if !ok {
+		return User{}, false
+	}
+	return redact(u), true
+}
+
+// redact copies u without its password and token hashes.
+func redact(u *User) User {
+	c := *u
+	c.PasswordHash = ""
+	c.Tokens = make([]Token, len(u.Tokens))
+	for i, t := range u.Tokens {
+		t.Hash = ""
+		c.Tokens[i] = t
+	}
+	return c
+}
+
+// SetUser creates the account called name, or updates its password and role
+// if it exists. It reports whether the account was created.
+func (s *Store) SetUser(name, password string, role Role) (created bool, err error) {
+	if !validName(name) {
+		return false, ErrInvalidName
+	}
+	if len(password) < MinPasswordLength {
+		return false, ErrWeakPassword
+	}
+	if !role.Valid() {
+		return false, fmt.Errorf("unknown role %q (use %s or %s)", role, RoleUser, RoleAdmin)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return false, fmt.Errorf("hashing password: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastCheck = time.Time{}
+	s.refresh()
+	u, ok := s.users[strings.ToLower(name)]
+	if !ok {
+		u = &User{Name: name, CreatedAt: time.Now().UTC()}
+		s.users[strings.ToLower(name)] = u
+	}
+	u.PasswordHash = string(hash)
+	u.Role = role
+	return !ok, s.save()
+}
+
+// RemoveUser deletes the account called name and its tokens.
+func (s *Store) RemoveUser(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastCheck = time.Time{}
+	s.refresh()
+	u, ok := s.users[strings.ToLower(name)]
+	if !ok {
+		return ErrUserNotFound
+	}
+	for _, t := range u.Tokens {
+		delete(s.tokens, t.Hash)
+	}
+	delete(s.users, strings.ToLower(name))
+	return s.save()
+}
+
+// Authenticate checks a user name and password.
+func (s *Store) Authenticate(name, password string) (Principal, bool) {
+	s.mu.Lock()
+	s.refresh()
+	u, ok := s.users[strings.ToLower(name)]
+	hash := dummyHash
+	var p Principal
+	if ok {
+		hash = []byte(u.PasswordHash)
+		p = Principal{Name: u.Name, Role: u.Role}
+	}
+	s.mu.Unlock()
+
+	// Compare outside the lock: bcrypt is deliberately slow.
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || !ok {
+		return Principal{}, false
+	}
+	return p, true
+}
+
+// CreateToken issues a personal API token for name. The returned token is
+// the only copy; it cannot be recovered later.
+func (s *Store) CreateToken(name, label string) (string, Token, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", Token{}, fmt.Errorf("generating token: %w", err)
+	}
+	raw := TokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	hash := hashToken(raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastCheck = time.Time{}
+	s.refresh()
+	u, ok := s.users[strings.ToLower(name)]
+	if !ok {
+		return "", Token{}, ErrUserNotFound
+	}
+	t := Token{ID: hash[:8], Label: strings.TrimSpace(label), Hash: hash, CreatedAt: time.Now().UTC()}
+	u.Tokens = append(u.Tokens, t)
+	s.tokens[hash] = tokenRef{user: u.Name, id: t.ID}
+	if err := s.save(); err != nil {
+		return "", Token{}, err
+	}
+	t.Hash = ""
+	return raw, t, nil
+}
+
+// RevokeToken deletes name's token with the given ID.
+func (s *Store) RevokeToken(name, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastCheck = time.Time{}
+	s.refresh()
+	u, ok := s.users[strings.ToLower(name)]
+	if !ok {
+		return ErrUserNotFound
+	}
+	for i, t := range u.Tokens {
+		if t.ID == id {
+			delete(s.tokens, t.Hash)
+			u.Tokens = append(u.Tokens[:i], u.Tokens[i+1:]...)
+			return s.save()
+		}
+	}
+	return ErrTokenNotFound
+}
+
+// AuthenticateToken resolves a personal API token to its owner.
+func (s *Store) AuthenticateToken(raw string) (Principal, bool) {
+	if !strings.HasPrefix(raw, TokenPrefix) {
+		return Principal{}, false
+	}
+	hash := hashToken(raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	ref, ok := s.tokens[hash]
+	if !ok {
+		return Principal{}, false
+	}
+	u, ok := s.users[strings.ToLower(ref.user)]
+	if !ok {
+		return Principal{}, false
+	}
+	return Principal{Name: u.Name, Role: u.Role, Token: ref.id}, true
+}
+
+// principal returns the current identity of the account called name, so
+// sessions follow role changes and end when the account is removed.
+func (s *Store) principal(name string) (Principal, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh()
+	u, ok := s.users[strings.ToLower(name)]
+	if !ok {
+		return Principal{}, false
+	}
+	return Principal{Name: u.Name, Role: u.Role}, true
+}
+
+// hashToken returns the hex SHA-256 of a raw token.
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// validName reports whether name is a usable account name.
+func validName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// equalSecret compares two secrets in constant time.
+func equalSecret(got, want string) bool {
+	return got != "" && want != "" && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
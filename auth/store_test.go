@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := OpenStore(filepath.Join(t.TempDir(), "users.json"))
+	if err != nil {
+		t.Fatalf("OpenStore() error: %v", err)
+	}
+	return s
+}
+
+func TestStore_Users(t *testing.T) {
+	s := newTestStore(t)
+	if s.Len() != 0 {
+		t.Fatalf("a missing file should be an empty store, got %d users", s.Len())
+	}
+
+	created, err := s.SetUser("alice", "correct horse", RoleAdmin)
+	if err != nil || !created {
+		t.Fatalf("SetUser() = %v, %v", created, err)
+	}
+	if p, ok := s.Authenticate("Alice", "correct horse"); !ok || p.Name != "alice" || !p.IsAdmin() {
+		t.Errorf("Authenticate() = %+v, %v", p, ok)
+	}
+	if _, ok := s.Authenticate("alice", "wrong password"); ok {
+		t.Error("a wrong password should fail")
+	}
+	if _, ok := s.Authenticate("bob", "correct horse"); ok {
+		t.Error("an unknown user should fail")
+	}
+
+	if created, err := s.SetUser("alice", "battery staple", RoleUser); err != nil || created {
+		t.Fatalf("updating SetUser() = %v, %v", created, err)
+	}
+	if p, ok := s.Authenticate("alice", "battery staple"); !ok || p.IsAdmin() {
+		t.Errorf("after update: %+v, %v", p, ok)
+	}
+
+	raw, err := os.ReadFile(s.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "battery staple") {
+		t.Error("the users file must not contain the password")
+	}
+	if info, _ := os.Stat(s.Path()); info.Mode().Perm() != 0600 {
+		t.Errorf("users file mode: got %v, want 0600", info.Mode().Perm())
+	}
+	if u := s.Users()[0]; u.PasswordHash != "" {
+		t.Error("Users() must not expose password hashes")
+	}
+
+	for _, tc := range []struct {
+		name, password string
+		role           Role
+		want           error
+	}{
+		{"bad name!", "long enough", RoleUser, ErrInvalidName},
+		{"carol", "short", RoleUser, ErrWeakPassword},
+	} {
+		if _, err := s.SetUser(tc.name, tc.password, tc.role); !errors.Is(err, tc.want) {
+			t.Errorf("SetUser(%q): got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	if _, err := s.SetUser("carol", "long enough", "owner"); err == nil {
+		t.Error("an unknown role should be rejected")
+	}
+
+	if err := s.RemoveUser("alice"); err != nil {
+		t.Fatalf("RemoveUser() error: %v", err)
+	}
+	if err := s.RemoveUser("alice"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("removing twice: got %v", err)
+	}
+}
+
+func TestStore_Tokens(t *testing.T) {
+	s := newTestStore(t)
+	if _, err := s.SetUser("alice", "correct horse", RoleUser); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.CreateToken("bob", ""); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("token for unknown user: got %v", err)
+	}
+
+	raw, token, err := s.CreateToken("alice", " laptop ")
+	if err != nil {
+		t.Fatalf("CreateToken() error: %v", err)
+	}
+	if !strings.HasPrefix(raw, TokenPrefix) || token.Label != "laptop" || token.Hash != "" {
+		t.Errorf("CreateToken() = %q, %+v", raw, token)
+	}
+	if p, ok := s.AuthenticateToken(raw); !ok || p.Name != "alice" || p.Token != token.ID {
+		t.Errorf("AuthenticateToken() = %+v, %v", p, ok)
+	}
+	if _, ok := s.AuthenticateToken(raw + "x"); ok {
+		t.Error("a wrong token should fail")
+	}
+	if file, _ := os.ReadFile(s.Path()); strings.Contains(string(file), raw) {
+		t.Error("the users file must not contain the token")
+	}
+
+	if err := s.RevokeToken("alice", "nope"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("revoking an unknown token: got %v", err)
+	}
+	if err := s.RevokeToken("alice", token.ID); err != nil {
+		t.Fatalf("RevokeToken() error: %v", err)
+	}
+	if _, ok := s.AuthenticateToken(raw); ok {
+		t.Error("a revoked token should fail")
+	}
+}
+
+func TestStore_ReloadsExternalChanges(t *testing.T) {
+	server := newTestStore(t)
+	server.Len() // the server has looked at the (missing) file
+
+	cli, err := OpenStore(server.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.SetUser("alice", "correct horse", RoleUser); err != nil {
+		t.Fatal(err)
+	}
+	raw, _, err := cli.CreateToken("alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.mu.Lock()
+	server.lastCheck = time.Time{}
+	server.mu.Unlock()
+	if _, ok := server.AuthenticateToken(raw); !ok {
+		t.Error("the server should pick up a token created by another process")
+	}
+}
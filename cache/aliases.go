@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// builtinAliases maps community shorthand to champion names (or keys). Names
+// that are already reachable by prefix or initials are left out.
+var builtinAliases = map[string]string{
+	"ali":         "Alistar",
+	"asol":        "Aurelion Sol",
+	"sol":         "Aurelion Sol",
+	"bel":         "Bel'Veth",
+	"blitz":       "Blitzcrank",
+	"cait":        "Caitlyn",
+	"cass":        "Cassiopeia",
+	"cho":         "Cho'Gath",
+	"mundo":       "Dr. Mundo",
+	"ez":          "Ezreal",
+	"fiddle":      "Fiddlesticks",
+	"gp":          "Gangplank",
+	"hec":         "Hecarim",
+	"heimer":      "Heimerdinger",
+	"donger":      "Heimerdinger",
+	"j4":          "Jarvan IV",
+	"jarvan":      "Jarvan IV",
+	"karth":       "Karthus",
+	"kass":        "Kassadin",
+	"kat":         "Katarina",
+	"kha":         "Kha'Zix",
+	"kog":         "Kog'Maw",
+	"lb":          "LeBlanc",
+	"lee":         "Lee Sin",
+	"liss":        "Lissandra",
+	"malph":       "Malphite",
+	"mao":         "Maokai",
+	"yi":          "Master Yi",
+	"morg":        "Morgana",
+	"naut":        "Nautilus",
+	"nid":         "Nidalee",
+	"noc":         "Nocturne",
+	"nunu":        "Nunu & Willump",
+	"ori":         "Orianna",
+	"panth":       "Pantheon",
+	"rek":         "Rek'Sai",
+	"renata":      "Renata Glasc",
+	"sej":         "Sejuani",
+	"sera":        "Seraphine",
+	"shyv":        "Shyvana",
+	"tahm":        "Tahm Kench",
+	"kench":       "Tahm Kench",
+	"trist":       "Tristana",
+	"trynd":       "Tryndamere",
+	"vlad":        "Vladimir",
+	"voli":        "Volibear",
+	"ww":          "Warwick",
+	"monkey king": "Wukong",
+	"wu":          "Wukong",
+	"xin":         "Xin Zhao",
+	"zil":         "Zilean",
+}
+
+// ParseAliases parses team-defined aliases written as comma-separated
+// alias=Champion pairs, e.g. "hook=Blitzcrank, gragas=Gragas". Champions may
+// be given by name or key.
+func ParseAliases(s string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		alias, champion, ok := strings.Cut(pair, "=")
+		alias, champion = strings.TrimSpace(alias), strings.TrimSpace(champion)
+		if !ok || preprocessString(alias) == "" || champion == "" {
+			return nil, fmt.Errorf("invalid champion alias %q (want alias=Champion)", strings.TrimSpace(pair))
+		}
+		out[alias] = champion
+	}
+	return out, nil
+}
+
+// aliasTarget is the champion an alias resolves to.
+type aliasTarget struct {
+	name  string // champion name, a key of ChampionMap
+	alias string // the alias as written, for display
+}
+
+// buildAliasIndex indexes aliases by their preprocessed form. Team aliases
+// override built-in ones, which override initials derived from multi-word
+// names; initials shared by several champions are left out as ambiguous. An
+// alias that spells another champion's name never shadows it. Team aliases
+// naming unknown champions are reported to warn.
+func buildAliasIndex(championMap, custom map[string]string, warn func(alias, champion string)) map[string]aliasTarget {
+	// Resolve targets by preprocessed name or key.
+	byName := make(map[string]string, 2*len(championMap))
+	for name, key := range championMap {
+		byName[preprocessString(key)] = name
+	}
+	for name := range championMap {
+		byName[preprocessString(name)] = name
+	}
+
+	index := make(map[string]aliasTarget)
+	add := func(alias, name string) {
+		p := preprocessString(alias)
+		if owner, ok := byName[p]; ok && owner != name {
+			return
+		}
+		index[p] = aliasTarget{name: name, alias: alias}
+	}
+
+	// Derived initials, e.g. "mf" for Miss Fortune.
+	initials := make(map[string][]string)
+	for name := range championMap {
+		words := strings.Fields(name)
+		if len(words) < 2 {
+			continue
+		}
+		var b strings.Builder
+		for _, w := range words {
+			if p := preprocessString(w); p != "" {
+				b.WriteString(p[:1])
+			}
+		}
+		initials[b.String()] = append(initials[b.String()], name)
+	}
+	for alias, names := range initials {
+		if len(names) == 1 && len(alias) > 1 {
+			add(alias, names[0])
+		}
+	}
+
+	for i, aliases := range []map[string]string{builtinAliases, custom} {
+		team := i == 1
+		// Sorted so the result does not depend on map order.
+		keys := make([]string, 0, len(aliases))
+		for alias := range aliases {
+			keys = append(keys, alias)
+		}
+		sort.Strings(keys)
+		for _, alias := range keys {
+			champion := aliases[alias]
+			name, ok := byName[preprocessString(champion)]
+			if !ok {
+				if team && warn != nil && len(championMap) > 0 {
+					warn(alias, champion)
+				}
+				continue
+			}
+			add(alias, name)
+		}
+	}
+	return index
+}
+
+// SetAliases replaces the team-defined champion aliases.
+func (c *Cache) SetAliases(aliases map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Aliases = aliases
+	c.reindex()
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newAliasTestCache() *Cache {
+	c := New("", 3)
+	c.SetChampionMap(map[string]string{
+		"Ahri":           "Ahri",
+		"Aurelion Sol":   "AurelionSol",
+		"Jarvan IV":      "JarvanIV",
+		"Kog'Maw":        "KogMaw",
+		"Master Yi":      "MasterYi",
+		"Miss Fortune":   "MissFortune",
+		"Morgana":        "Morgana",
+		"Nunu & Willump": "Nunu",
+		"Twisted Fate":   "TwistedFate",
+		"Wukong":         "MonkeyKing",
+		"Zed":            "Zed",
+	})
+	return c
+}
+
+func TestSearchChampionName_Aliases(t *testing.T) {
+	c := newAliasTestCache()
+	for input, want := range map[string]string{
+		"mf":          "MissFortune",
+		"TF":          "TwistedFate",
+		"asol":        "AurelionSol",
+		"j4":          "JarvanIV",
+		"kog":         "KogMaw",
+		"nunu":        "Nunu",
+		"nw":          "Nunu",
+		"wukong":      "MonkeyKing",
+		"monkey king": "MonkeyKing",
+		"yi":          "MasterYi",
+		"ahri":        "Ahri",
+	} {
+		got, err := c.SearchChampionName(input)
+		if err != nil || got != want {
+			t.Errorf("SearchChampionName(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+}
+
+func TestAliasIndex_Rules(t *testing.T) {
+	champions := map[string]string{
+		"Lee Sin":    "LeeSin",
+		"Lucky Star": "LuckyStar",
+		"Ahri":       "Ahri",
+		"Zed":        "Zed",
+	}
+	var warned []string
+	index := buildAliasIndex(champions, map[string]string{
+		"ahri":   "Zed",     // spells another champion's name
+		"lee":    "Zed",     // overrides the built-in alias
+		"shadow": "zed",     // target given by key, any case
+		"pyke":   "Pyke",    // unknown champion
+		"LS":     "Lee Sin", // resolves the ambiguous initials
+	}, func(alias, champion string) { warned = append(warned, alias) })
+
+	want := map[string]string{"lee": "Zed", "shadow": "Zed", "ls": "Lee Sin"}
+	for alias, name := range want {
+		if got := index[alias]; got.name != name {
+			t.Errorf("alias %q: got %q, want %q", alias, got.name, name)
+		}
+	}
+	if _, ok := index["ahri"]; ok {
+		t.Error("an alias must not shadow another champion's name")
+	}
+	if !reflect.DeepEqual(warned, []string{"pyke"}) {
+		t.Errorf("warned about %v, want [pyke]", warned)
+	}
+
+	index = buildAliasIndex(champions, nil, nil)
+	if _, ok := index["ls"]; ok {
+		t.Error("initials shared by two champions should be left out")
+	}
+}
+
+func TestAutocompleteRich_Aliases(t *testing.T) {
+	c := newAliasTestCache()
+	c.SetAliases(map[string]string{"mommy": "Miss Fortune"})
+
+	got := c.AutocompleteRich("mo", 10)
+	var names, aliases []string
+	for _, r := range got {
+		names = append(names, r.Name)
+		aliases = append(aliases, r.Alias)
+	}
+	wantNames := []string{"Miss Fortune", "Morgana", "Wukong"}
+	wantAliases := []string{"mommy", "", "monkey king"}
+	if !reflect.DeepEqual(names, wantNames) || !reflect.DeepEqual(aliases, wantAliases) {
+		t.Errorf("AutocompleteRich(mo) = %v %q; want %v %q", names, aliases, wantNames, wantAliases)
+	}
+
+	// An exact alias is listed first.
+	got = c.AutocompleteRich("mf", 10)
+	if len(got) == 0 || got[0].Name != "Miss Fortune" || got[0].Alias != "mf" {
+		t.Errorf("AutocompleteRich(mf) = %+v", got)
+	}
+}
+
+func TestParseAliases(t *testing.T) {
+	got, err := ParseAliases(" hook = Blitzcrank, ,mommy=Miss Fortune ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"hook": "Blitzcrank", "mommy": "Miss Fortune"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAliases() = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"hook", "=Zed", "hook="} {
+		if _, err := ParseAliases(bad); err == nil {
+			t.Errorf("ParseAliases(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+// backendTimeout bounds backend calls made from methods without a context,
+// such as GetChampionByID and SetChampion.
+const backendTimeout = 2 * time.Second
+
+// Snapshot is the patch-level data a Backend shares between instances.
+type Snapshot struct {
+	Patch          string                          `json:"patch"`
+	ChampionMap    map[string]string               `json:"champion_map"`
+	ChampionKeyMap map[string]string               `json:"champion_key_map"`
+	SummonerSpells map[string]models.SummonerSpell `json:"summoner_spells"`
+}
+
+// KV is a generic key/value store with per-entry expiry, used for cached API
+// responses. A ttl of zero or less stores the value without expiry.
+type KV interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Backend is the storage behind a Cache. The in-memory backend keeps data in
+// the process; the Redis backend lets several instances share one copy.
+type Backend interface {
+	KV
+
+	// LoadSnapshot returns the stored patch-level data, or a zero Snapshot if
+	// nothing has been stored yet.
+	LoadSnapshot(ctx context.Context) (Snapshot, error)
+	// SaveSnapshot replaces the stored patch-level data in one step.
+	SaveSnapshot(ctx context.Context, s Snapshot) error
+
+	// GetChampion returns detailed champion data by textual key.
+	GetChampion(ctx context.Context, key string) (models.Champion, bool, error)
+	// SetChampion stores detailed champion data under champion.Key.
+	SetChampion(ctx context.Context, champion models.Champion) error
+	// ClearChampions drops all detailed champion data, e.g. after a patch change.
+	ClearChampions(ctx context.Context) error
+
+	// Close releases any connections held by the backend.
+	Close() error
+}
+
+// Store is the champion data cache as used by handlers and the data loader.
+// *Cache implements it on top of a Backend.
+type Store interface {
+	GetPatch() string
+	SetPatch(patch string)
+
+	GetChampionMap() map[string]string
+	GetChampionMapLen() int
+	SetChampionMap(m map[string]string)
+	GetChampionKeyMap() map[string]string
+	SetChampionKeyMap(m map[string]string)
+	GetSummonerSpells() map[string]models.SummonerSpell
+	GetSummonerSpellsLen() int
+	SetSummonerSpells(m map[string]models.SummonerSpell)
+
+	GetChampionByID(championID string) (models.Champion, bool)
+	SetChampion(champion models.Champion)
+	SetChampions(champions map[string]models.Champion)
+	AllChampions() []models.Champion
+	GetChampionsLen() int
+
+	SearchChampionName(input string) (string, error)
+	AutocompleteRich(input string, limit int) []AutocompleteResult
+
+	Swap(patch string, championMap, keyMap map[string]string, spells map[string]models.SummonerSpell, champions map[string]models.Champion)
+	Invalidate()
+	Save() error
+	Sync(ctx context.Context) (bool, error)
+
+	// KV returns the key/value store for cached API responses.
+	KV() KV
+}
+
+var _ Store = (*Cache)(nil)
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/klnstprx/lolMatchup/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedisBackend starts an in-process Redis stand-in and returns a
+// backend connected to it.
+func newTestRedisBackend(t *testing.T) (*RedisBackend, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	b := NewRedisBackend(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "test")
+	t.Cleanup(func() { b.Close() })
+	return b, mr
+}
+
+// backendCase pairs a backend with a way to move its clock past a TTL.
+type backendCase struct {
+	name    string
+	backend Backend
+	advance func(time.Duration)
+}
+
+func backendCases(t *testing.T) []backendCase {
+	rb, mr := newTestRedisBackend(t)
+	return []backendCase{
+		{"memory", NewMemoryBackend(), func(d time.Duration) { time.Sleep(d) }},
+		{"redis", rb, mr.FastForward},
+	}
+}
+
+func TestBackendSnapshot(t *testing.T) {
+	for _, bc := range backendCases(t) {
+		t.Run(bc.name, func(t *testing.T) {
+			ctx := context.Background()
+			empty, err := bc.backend.LoadSnapshot(ctx)
+			if err != nil || empty.Patch != "" {
+				t.Fatalf("empty LoadSnapshot() = %+v, %v", empty, err)
+			}
+
+			want := Snapshot{
+				Patch:          "15.1.1",
+				ChampionMap:    map[string]string{"Ahri": "Ahri"},
+				ChampionKeyMap: map[string]string{"103": "Ahri"},
+				SummonerSpells: map[string]models.SummonerSpell{"4": {Name: "Flash", Key: "4"}},
+			}
+			if err := bc.backend.SaveSnapshot(ctx, want); err != nil {
+				t.Fatalf("SaveSnapshot() error: %v", err)
+			}
+			got, err := bc.backend.LoadSnapshot(ctx)
+			if err != nil {
+				t.Fatalf("LoadSnapshot() error: %v", err)
+			}
+			if got.Patch != want.Patch || got.ChampionMap["Ahri"] != "Ahri" ||
+				got.ChampionKeyMap["103"] != "Ahri" || got.SummonerSpells["4"].Name != "Flash" {
+				t.Errorf("LoadSnapshot() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestBackendChampions(t *testing.T) {
+	for _, bc := range backendCases(t) {
+		t.Run(bc.name, func(t *testing.T) {
+			ctx := context.Background()
+			ahri := models.Champion{ID: 103, Key: "Ahri", Name: "Ahri", Title: "the Nine-Tailed Fox"}
+			if err := bc.backend.SetChampion(ctx, ahri); err != nil {
+				t.Fatalf("SetChampion() error: %v", err)
+			}
+			got, ok, err := bc.backend.GetChampion(ctx, "Ahri")
+			if err != nil || !ok || got.Title != ahri.Title {
+				t.Errorf("GetChampion() = %+v, %v, %v", got, ok, err)
+			}
+			if err := bc.backend.ClearChampions(ctx); err != nil {
+				t.Fatalf("ClearChampions() error: %v", err)
+			}
+			if _, ok, _ := bc.backend.GetChampion(ctx, "Ahri"); ok {
+				t.Error("champion should be gone after ClearChampions")
+			}
+		})
+	}
+}
+
+func TestBackendKV(t *testing.T) {
+	for _, bc := range backendCases(t) {
+		t.Run(bc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if _, ok, err := bc.backend.Get(ctx, "missing"); ok || err != nil {
+				t.Errorf("Get(missing) = %v, %v", ok, err)
+			}
+
+			if err := bc.backend.Set(ctx, "short", []byte("a"), 20*time.Millisecond); err != nil {
+				t.Fatalf("Set() error: %v", err)
+			}
+			if err := bc.backend.Set(ctx, "forever", []byte("b"), 0); err != nil {
+				t.Fatalf("Set() error: %v", err)
+			}
+			if v, ok, _ := bc.backend.Get(ctx, "short"); !ok || string(v) != "a" {
+				t.Errorf("Get(short) before expiry = %q, %v", v, ok)
+			}
+
+			bc.advance(40 * time.Millisecond)
+			if _, ok, _ := bc.backend.Get(ctx, "short"); ok {
+				t.Error("short-lived key should have expired")
+			}
+			if v, ok, _ := bc.backend.Get(ctx, "forever"); !ok || string(v) != "b" {
+				t.Errorf("Get(forever) = %q, %v", v, ok)
+			}
+
+			if err := bc.backend.Delete(ctx, "forever"); err != nil {
+				t.Fatalf("Delete() error: %v", err)
+			}
+			if _, ok, _ := bc.backend.Get(ctx, "forever"); ok {
+				t.Error("deleted key should be gone")
+			}
+		})
+	}
+}
+
+// TestCacheSharedBackend checks two caches on one Redis backend see each
+// other's data, as two replicas would.
+func TestCacheSharedBackend(t *testing.T) {
+	rb, _ := newTestRedisBackend(t)
+
+	a := New("", 3)
+	a.Backend = rb
+	a.Swap("15.1.1", map[string]string{"Ahri": "Ahri"}, map[string]string{"103": "Ahri"}, nil, nil)
+	if err := a.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	a.SetChampion(models.Champion{ID: 103, Key: "Ahri", Name: "Ahri"})
+
+	b := New("", 3)
+	b.Backend = rb
+	if err := b.Load(); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if b.GetPatch() != "15.1.1" || b.GetChampionMapLen() != 1 {
+		t.Errorf("second cache: patch %q, %d names", b.GetPatch(), b.GetChampionMapLen())
+	}
+	if _, ok := b.GetChampionByID("Ahri"); !ok {
+		t.Error("second cache should read champion details through the backend")
+	}
+	if id, err := b.SearchChampionName("ahr"); err != nil || id != "Ahri" {
+		t.Errorf("SearchChampionName() = %q, %v", id, err)
+	}
+
+	// A patch swap on one instance drops details for everyone.
+	a.Swap("15.2.1", map[string]string{"Ahri": "Ahri"}, map[string]string{"103": "Ahri"}, nil, nil)
+	if _, ok, _ := rb.GetChampion(context.Background(), "Ahri"); ok {
+		t.Error("Swap should clear champion details in the backend")
+	}
+
+	// Champions that come with a swap are shared too.
+	a.Swap("15.3.1", map[string]string{"Ahri": "Ahri"}, map[string]string{"103": "Ahri"}, nil,
+		map[string]models.Champion{"Ahri": {ID: 103, Key: "Ahri", Name: "Ahri"}})
+	if err := a.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	c := New("", 3)
+	c.Backend = rb
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if all := c.AllChampions(); len(all) != 1 || all[0].Name != "Ahri" {
+		t.Errorf("AllChampions() on a third cache = %v, want Ahri from the backend", all)
+	}
+}
@@ -1,13 +1,14 @@
 package cache
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 	"sync"
 
+	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/metrics"
 	"github.com/klnstprx/lolMatchup/models"
 )
 
@@ -49,8 +50,13 @@ type Cache struct {
 	ChampionKeyMap       map[string]string // numeric key to textual champion ID
 	SummonerSpells       map[string]models.SummonerSpell
 	LevenshteinThreshold int
+	Logger               *log.Logger       // optional; reports load recovery and migrations
+	Backend              Backend           // shared storage; New uses a MemoryBackend
+	Aliases              map[string]string // team-defined alias -> champion name or key, see SetAliases
 
-	mu sync.RWMutex
+	mu    sync.RWMutex
+	idxMu sync.Mutex
+	idx   *championIndex // search index over ChampionMap, see reindex
 }
 
 // New creates a Cache with the given file path and Levenshtein threshold.
@@ -62,83 +68,167 @@ func New(path string, threshold int) *Cache {
 		ChampionKeyMap:       make(map[string]string),
 		SummonerSpells:       make(map[string]models.SummonerSpell),
 		LevenshteinThreshold: threshold,
+		Backend:              NewMemoryBackend(),
 	}
 }
 
-// Load reads the persisted cache (patch, champions, and champion map) from file.
-// Missing or invalid files are treated as cache misses.
-func (c *Cache) Load() error {
-	file, err := os.Open(c.Path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
+// Invalidate empties the cache, including the data held by the backend.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	c.Champions = make(map[string]models.Champion)
+	c.ChampionMap = make(map[string]string)
+	c.ChampionKeyMap = make(map[string]string)
+	c.SummonerSpells = make(map[string]models.SummonerSpell)
+	c.reindex()
+	c.mu.Unlock()
+
+	if c.Backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+		if err := c.Backend.SaveSnapshot(ctx, Snapshot{}); err != nil {
+			c.logWarn("Cache backend snapshot reset failed", "error", err)
+		}
+		if err := c.Backend.ClearChampions(ctx); err != nil {
+			c.logWarn("Cache backend champion reset failed", "error", err)
 		}
-		return fmt.Errorf("failed to open cache file: %w", err)
 	}
-	defer file.Close()
+}
 
-	dec := json.NewDecoder(file)
-	var persist struct {
-		Patch          string                          `json:"patch"`
-		Champions      map[string]models.Champion      `json:"champions"`
-		ChampionMap    map[string]string               `json:"champion_map"`
-		ChampionKeyMap map[string]string               `json:"champion_key_map"`
-		SummonerSpells map[string]models.SummonerSpell `json:"summoner_spells"`
-	}
-	if err := dec.Decode(&persist); err != nil {
-		// On decode errors, ignore and start fresh
-		return nil
+// Swap atomically replaces the patch-dependent data with maps built for a new
+// patch. Readers see either the old or the new data, never an empty cache.
+// Detailed champion data is replaced by champions (keyed by champion key);
+// a nil champions map just drops the old patch's data. A nil spells map keeps
+// the current summoner spells.
+func (c *Cache) Swap(patch string, championMap, keyMap map[string]string, spells map[string]models.SummonerSpell, champions map[string]models.Champion) {
+	if champions == nil {
+		champions = make(map[string]models.Champion)
 	}
 	c.mu.Lock()
-	c.Patch = persist.Patch
-	c.ChampionMap = persist.ChampionMap
-	c.ChampionKeyMap = persist.ChampionKeyMap
-	c.Champions = persist.Champions
-	if persist.SummonerSpells != nil {
-		c.SummonerSpells = persist.SummonerSpells
+	c.Patch = patch
+	c.Champions = champions
+	c.ChampionMap = championMap
+	c.ChampionKeyMap = keyMap
+	c.reindex()
+	if spells != nil {
+		c.SummonerSpells = spells
+	}
+	c.mu.Unlock()
+
+	if c.Backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+		if err := c.Backend.ClearChampions(ctx); err != nil {
+			c.logWarn("Cache backend champion reset failed", "error", err)
+		}
+		c.writeChampions(ctx, champions)
 	}
+}
+
+// SetChampions replaces all detailed champion data, keyed by champion key,
+// e.g. after fetching the whole champion list, and writes it through to the
+// backend.
+func (c *Cache) SetChampions(champions map[string]models.Champion) {
+	c.mu.Lock()
+	c.Champions = champions
 	c.mu.Unlock()
-	return nil
+
+	if c.Backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+		c.writeChampions(ctx, champions)
+	}
+}
+
+// writeChampions stores champions in the backend, stopping at the first error.
+func (c *Cache) writeChampions(ctx context.Context, champions map[string]models.Champion) {
+	for _, champion := range champions {
+		if err := c.Backend.SetChampion(ctx, champion); err != nil {
+			c.logWarn("Cache backend champion write failed", "champion", champion.Key, "error", err)
+			return
+		}
+	}
 }
 
-// Save writes the cache (patch, champions, and champion map) to file.
-func (c *Cache) Save() error {
+// AllChampions returns the detailed data of every champion in the champion
+// map that is available locally or in the backend, sorted by name.
+func (c *Cache) AllChampions() []models.Champion {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	out := make([]models.Champion, 0, len(c.ChampionMap))
+	var missing []string
+	for _, key := range c.ChampionMap {
+		if champion, ok := c.Champions[key]; ok {
+			out = append(out, champion)
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	c.mu.RUnlock()
+
+	if len(missing) > 0 && c.Backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+		for _, key := range missing {
+			champion, ok, err := c.Backend.GetChampion(ctx, key)
+			if err != nil {
+				c.logWarn("Cache backend champion lookup failed", "champion", key, "error", err)
+				break
+			}
+			if ok {
+				out = append(out, champion)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
 
-	file, err := os.Create(c.Path)
+// Sync adopts the backend's patch-level data when it holds a different patch
+// than this instance, e.g. because another instance already refreshed it. It
+// reports whether local data was replaced.
+func (c *Cache) Sync(ctx context.Context) (bool, error) {
+	if c.Backend == nil {
+		return false, nil
+	}
+	s, err := c.Backend.LoadSnapshot(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to create cache file: %w", err)
-	}
-	defer file.Close()
-
-	enc := json.NewEncoder(file)
-	persist := struct {
-		Patch          string                          `json:"patch"`
-		Champions      map[string]models.Champion      `json:"champions"`
-		ChampionMap    map[string]string               `json:"champion_map"`
-		ChampionKeyMap map[string]string               `json:"champion_key_map"`
-		SummonerSpells map[string]models.SummonerSpell `json:"summoner_spells"`
-	}{
-		Patch:          c.Patch,
-		Champions:      c.Champions,
-		ChampionMap:    c.ChampionMap,
-		ChampionKeyMap: c.ChampionKeyMap,
-		SummonerSpells: c.SummonerSpells,
-	}
-	if err := enc.Encode(&persist); err != nil {
-		return fmt.Errorf("failed to encode cache data: %w", err)
-	}
-	return nil
-}
+		return false, err
+	}
+	if s.Patch == "" || len(s.ChampionMap) == 0 {
+		return false, nil
+	}
 
-func (c *Cache) Invalidate() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.Champions = make(map[string]models.Champion)
-	c.ChampionMap = make(map[string]string)
-	c.ChampionKeyMap = make(map[string]string)
-	c.SummonerSpells = make(map[string]models.SummonerSpell)
+	if s.Patch == c.Patch && len(c.ChampionMap) > 0 {
+		return false, nil
+	}
+	if s.Patch != c.Patch {
+		c.Champions = make(map[string]models.Champion)
+	}
+	c.Patch = s.Patch
+	c.ChampionMap = s.ChampionMap
+	c.ChampionKeyMap = s.ChampionKeyMap
+	c.reindex()
+	if s.ChampionKeyMap == nil {
+		c.ChampionKeyMap = make(map[string]string)
+	}
+	if s.SummonerSpells != nil {
+		c.SummonerSpells = s.SummonerSpells
+	}
+	return true, nil
+}
+
+// KV returns the backend's key/value store for cached API responses.
+func (c *Cache) KV() KV {
+	return c.Backend
+}
+
+// Close releases the backend's resources.
+func (c *Cache) Close() error {
+	if c.Backend == nil {
+		return nil
+	}
+	return c.Backend.Close()
 }
 
 // GetPatch returns the current cached patch version.
@@ -162,6 +252,13 @@ func (c *Cache) GetChampionMapLen() int {
 	return len(c.ChampionMap)
 }
 
+// GetChampionsLen returns the number of champions with detailed data cached.
+func (c *Cache) GetChampionsLen() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.Champions)
+}
+
 // SetChampionKeyMap sets the mapping from numeric key to textual champion ID.
 func (c *Cache) SetChampionKeyMap(m map[string]string) {
 	c.mu.Lock()
@@ -177,51 +274,45 @@ func (c *Cache) GetChampionKeyMap() map[string]string {
 }
 
 // SearchChampionName returns the champion ID for the best match against "input."
-// It uses Levenshtein to handle fuzzy matching, but also applies a bonus if the
+// Aliases such as "mf" or "j4" are checked first (see buildAliasIndex). Otherwise
+// it uses Levenshtein to handle fuzzy matching, but also applies a bonus if the
 // champion's name starts with (prefix) or contains the user's input (substring).
 // Ties in the same weighted distance are broken alphabetically by champion name.
 func (c *Cache) SearchChampionName(input string) (string, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	ci := c.currentIndex()
 
 	typed := preprocessString(input)
 	if typed == "" {
 		return "", fmt.Errorf("no champion found matching '%s'", input)
 	}
-
-	type candidate struct {
-		name       string
-		championID string
-		weighted   int
+	if t, ok := ci.aliases[typed]; ok {
+		return ci.ids[t.name], nil
 	}
 
-	var results []candidate
+	// Only names containing the input earn a bonus, so any other match lies
+	// within the threshold itself.
+	var candidates []string
+	for _, t := range ci.names.containing(typed) {
+		candidates = append(candidates, t.name)
+	}
+	for _, m := range ci.names.within(typed, c.LevenshteinThreshold) {
+		candidates = append(candidates, m.name)
+	}
 
-	for name, champID := range c.ChampionMap {
-		processedName := preprocessString(name)
-		weighted, ok := fuzzyScore(typed, processedName, c.LevenshteinThreshold)
+	best, bestWeighted := "", -1
+	for _, name := range candidates {
+		weighted, ok := fuzzyScore(typed, preprocessString(name), c.LevenshteinThreshold)
 		if !ok {
 			continue
 		}
-		results = append(results, candidate{
-			name:       name,
-			championID: champID,
-			weighted:   weighted,
-		})
+		if bestWeighted < 0 || weighted < bestWeighted || (weighted == bestWeighted && name < best) {
+			best, bestWeighted = name, weighted
+		}
 	}
-
-	if len(results) == 0 {
+	if bestWeighted < 0 {
 		return "", fmt.Errorf("no champion found matching '%s'", input)
 	}
-
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].weighted != results[j].weighted {
-			return results[i].weighted < results[j].weighted
-		}
-		return results[i].name < results[j].name
-	})
-
-	return results[0].championID, nil
+	return ci.ids[best], nil
 }
 
 // Sets champion map in cache.
@@ -229,6 +320,7 @@ func (c *Cache) SetChampionMap(champions map[string]string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.ChampionMap = champions
+	c.reindex()
 }
 
 // Gets champion map from cache.
@@ -265,95 +357,60 @@ func (c *Cache) ClearChampions() {
 	c.Champions = make(map[string]models.Champion)
 }
 
-// Returns champion data from cache.
+// Returns champion data from cache, falling back to the backend (which may
+// have been filled by another instance) on a local miss. Each call is counted
+// as a champion cache hit or miss in the lookup metrics.
 func (c *Cache) GetChampionByID(championID string) (models.Champion, bool) {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
 	champion, ok := c.Champions[championID]
+	c.mu.RUnlock()
+
+	if !ok && c.Backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+		var err error
+		champion, ok, err = c.Backend.GetChampion(ctx, championID)
+		if err != nil {
+			c.logWarn("Cache backend champion lookup failed", "champion", championID, "error", err)
+		}
+		if ok {
+			c.mu.Lock()
+			c.Champions[championID] = champion
+			c.mu.Unlock()
+		}
+	}
+
+	if ok {
+		metrics.CacheLookups.WithLabelValues("champion", "hit").Inc()
+	} else {
+		metrics.CacheLookups.WithLabelValues("champion", "miss").Inc()
+	}
 	return champion, ok
 }
 
-// Sets champion data in cache.
+// Sets champion data in cache and writes it through to the backend.
 func (c *Cache) SetChampion(champion models.Champion) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	if c.Champions == nil {
 		c.Champions = make(map[string]models.Champion)
 	}
 	c.Champions[champion.Key] = champion
+	c.mu.Unlock()
+
+	if c.Backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+		if err := c.Backend.SetChampion(ctx, champion); err != nil {
+			c.logWarn("Cache backend champion write failed", "champion", champion.Key, "error", err)
+		}
+	}
 }
 
 // Autocomplete returns up to 'limit' champion names that best match the input using
 // a weighted Levenshtein distance, including prefix and substring bonuses.
 func (c *Cache) Autocomplete(input string, limit int) []string {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	typed := preprocessString(input)
-	if typed == "" {
-		return nil
-	}
-	var results []string
-	// 1) prefix matches
-	for name := range c.ChampionMap {
-		if strings.HasPrefix(preprocessString(name), typed) {
-			results = append(results, name)
-		}
-	}
-	if len(results) > 0 {
-		sort.Strings(results)
-		if len(results) > limit && limit > 0 {
-			results = results[:limit]
-		}
-		return results
-	}
-	// 2) substring matches
-	for name := range c.ChampionMap {
-		if strings.Contains(preprocessString(name), typed) {
-			results = append(results, name)
-		}
-	}
-	if len(results) > 0 {
-		sort.Strings(results)
-		if len(results) > limit && limit > 0 {
-			results = results[:limit]
-		}
-		return results
-	}
-	// 3) fuzzy fallback: weighted Levenshtein, pick best match
-	type candidate struct {
-		name     string
-		weighted int
-	}
-	var fuzzy []candidate
-	for name := range c.ChampionMap {
-		processed := preprocessString(name)
-		weighted, ok := fuzzyScore(typed, processed, c.LevenshteinThreshold)
-		if !ok {
-			continue
-		}
-		fuzzy = append(fuzzy, candidate{name: name, weighted: weighted})
-	}
-	if len(fuzzy) == 0 {
-		return nil
-	}
-	sort.Slice(fuzzy, func(i, j int) bool {
-		if fuzzy[i].weighted != fuzzy[j].weighted {
-			return fuzzy[i].weighted < fuzzy[j].weighted
-		}
-		// break ties alphabetically
-		return fuzzy[i].name < fuzzy[j].name
-	})
-	// Build ordered list of fuzzy suggestions
-	var suggestions []string
-	for _, cand := range fuzzy {
-		suggestions = append(suggestions, cand.name)
-	}
-	// Apply limit if specified
-	if limit > 0 && len(suggestions) > limit {
-		suggestions = suggestions[:limit]
-	}
-	return suggestions
+	names, _ := c.currentIndex().match(preprocessString(input), c.LevenshteinThreshold, limit)
+	return names
 }
 
 // AutocompleteResult holds enriched data for one autocomplete suggestion.
@@ -362,87 +419,107 @@ type AutocompleteResult struct {
 	Key       string
 	Positions []string
 	Roles     []string
+	Alias     string // the alias that matched, if the name itself did not
 }
 
 // AutocompleteRich returns up to 'limit' enriched champion suggestions that best
 // match the input, including champion key, positions, and roles.
 func (c *Cache) AutocompleteRich(input string, limit int) []AutocompleteResult {
+	ci := c.currentIndex()
+	names, aliases := ci.match(preprocessString(input), c.LevenshteinThreshold, limit)
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	results := c.enrichNames(ci.ids, names)
+	for i := range results {
+		results[i].Alias = aliases[results[i].Name]
+	}
+	return results
+}
 
-	typed := preprocessString(input)
+// match returns up to limit champion names matching typed, trying in turn
+// prefix and alias matches, substring matches, and a fuzzy fallback within
+// threshold edits. Each step lists names alphabetically, except that a
+// champion named by an exact alias comes first and fuzzy matches are ordered
+// by distance. aliases maps each name found only through an alias to that
+// alias.
+func (ci *championIndex) match(typed string, threshold, limit int) (names []string, aliases map[string]string) {
 	if typed == "" {
-		return nil
+		return nil, nil
+	}
+	truncate := func(names []string) []string {
+		if limit > 0 && len(names) > limit {
+			return names[:limit]
+		}
+		return names
 	}
 
-	var names []string
-
-	// 1) prefix matches
-	for name := range c.ChampionMap {
-		if strings.HasPrefix(preprocessString(name), typed) {
-			names = append(names, name)
+	// 1) prefix matches, plus champions whose alias starts with the input
+	seen := make(map[string]bool)
+	for _, t := range ci.names.prefixed(typed) {
+		names = append(names, t.name)
+		seen[t.name] = true
+	}
+	matched := make(map[string]string) // champion name -> shortest matching alias
+	for _, alias := range ci.aliasesWithPrefix(typed) {
+		t := ci.aliases[alias]
+		if seen[t.name] || (len(typed) < 2 && alias != typed) {
+			continue
+		}
+		// Keys come in order, so the first of each length wins.
+		if prev, ok := matched[t.name]; !ok || len(alias) < len(prev) {
+			matched[t.name] = alias
 		}
 	}
-	if len(names) > 0 {
-		sort.Strings(names)
-		if limit > 0 && len(names) > limit {
-			names = names[:limit]
+	for name, alias := range matched {
+		if aliases == nil {
+			aliases = make(map[string]string)
 		}
-		return c.enrichNames(names)
+		names = append(names, name)
+		aliases[name] = ci.aliases[alias].alias
+	}
+	exact, hasExact := ci.aliases[typed]
+	if len(names) > 0 {
+		sort.Slice(names, func(i, j int) bool {
+			if hasExact && (names[i] == exact.name) != (names[j] == exact.name) {
+				return names[i] == exact.name
+			}
+			return names[i] < names[j]
+		})
+		return truncate(names), aliases
 	}
 
 	// 2) substring matches
-	for name := range c.ChampionMap {
-		if strings.Contains(preprocessString(name), typed) {
-			names = append(names, name)
-		}
+	for _, t := range ci.names.containing(typed) {
+		names = append(names, t.name)
 	}
 	if len(names) > 0 {
 		sort.Strings(names)
-		if limit > 0 && len(names) > limit {
-			names = names[:limit]
-		}
-		return c.enrichNames(names)
+		return truncate(names), nil
 	}
 
-	// 3) fuzzy fallback
-	type candidate struct {
-		name     string
-		weighted int
-	}
-	var fuzzy []candidate
-	for name := range c.ChampionMap {
-		processed := preprocessString(name)
-		weighted, ok := fuzzyScore(typed, processed, c.LevenshteinThreshold)
-		if !ok {
-			continue
-		}
-		fuzzy = append(fuzzy, candidate{name: name, weighted: weighted})
-	}
-	if len(fuzzy) == 0 {
-		return nil
-	}
+	// 3) fuzzy fallback: no name contains the input, so the plain edit
+	// distance is the weighted one; best match first
+	fuzzy := ci.names.within(typed, threshold)
 	sort.Slice(fuzzy, func(i, j int) bool {
-		if fuzzy[i].weighted != fuzzy[j].weighted {
-			return fuzzy[i].weighted < fuzzy[j].weighted
+		if fuzzy[i].dist != fuzzy[j].dist {
+			return fuzzy[i].dist < fuzzy[j].dist
 		}
+		// break ties alphabetically
 		return fuzzy[i].name < fuzzy[j].name
 	})
-	for _, cand := range fuzzy {
-		names = append(names, cand.name)
-	}
-	if limit > 0 && len(names) > limit {
-		names = names[:limit]
+	for _, m := range fuzzy {
+		names = append(names, m.name)
 	}
-	return c.enrichNames(names)
+	return truncate(names), nil
 }
 
 // enrichNames converts a list of champion names into AutocompleteResults
 // by looking up champion data from the cache. Must be called with c.mu held.
-func (c *Cache) enrichNames(names []string) []AutocompleteResult {
+func (c *Cache) enrichNames(ids map[string]string, names []string) []AutocompleteResult {
 	results := make([]AutocompleteResult, 0, len(names))
 	for _, name := range names {
-		key := c.ChampionMap[name]
+		key := ids[name]
 		r := AutocompleteResult{Name: name, Key: key}
 		if champ, ok := c.Champions[key]; ok {
 			r.Positions = champ.Positions
@@ -216,6 +216,57 @@ func TestInvalidateResetsSummonerSpells(t *testing.T) {
 	}
 }
 
+// TestSwap verifies Swap replaces patch data in one step, drops detailed
+// champion data and keeps spells when none are supplied.
+func TestSwap(t *testing.T) {
+	c := New("", 3)
+	c.SetPatch("14.9.1")
+	c.SetChampionMap(map[string]string{"Aatrox": "Aatrox"})
+	c.SetChampion(models.Champion{ID: 266, Key: "Aatrox", Name: "Aatrox"})
+	c.SetSummonerSpells(map[string]models.SummonerSpell{"4": {Name: "Flash"}})
+
+	c.Swap("15.1.1", map[string]string{"Ahri": "Ahri", "Zed": "Zed"}, map[string]string{"103": "Ahri"}, nil, nil)
+
+	if got := c.GetPatch(); got != "15.1.1" {
+		t.Errorf("GetPatch() = %q, want %q", got, "15.1.1")
+	}
+	if got := c.GetChampionMapLen(); got != 2 {
+		t.Errorf("GetChampionMapLen() = %d, want 2", got)
+	}
+	if got := c.GetChampionKeyMap()["103"]; got != "Ahri" {
+		t.Errorf("key map: got %q, want Ahri", got)
+	}
+	if got := c.GetChampionsLen(); got != 0 {
+		t.Errorf("GetChampionsLen() = %d, want 0 (old patch details dropped)", got)
+	}
+	if got := c.GetSummonerSpellsLen(); got != 1 {
+		t.Errorf("GetSummonerSpellsLen() = %d, want 1 (kept when nil)", got)
+	}
+}
+
+// TestAllChampions verifies Swap stores the new patch's champions and
+// AllChampions returns those in the champion map, sorted by name.
+func TestAllChampions(t *testing.T) {
+	c := New("", 3)
+	c.Swap("15.1.1", map[string]string{"Zed": "Zed", "Ahri": "Ahri", "Lux": "Lux"}, nil, nil, map[string]models.Champion{
+		"Zed":  {ID: 238, Key: "Zed", Name: "Zed"},
+		"Ahri": {ID: 103, Key: "Ahri", Name: "Ahri"},
+	})
+
+	var names []string
+	for _, champ := range c.AllChampions() {
+		names = append(names, champ.Name)
+	}
+	if want := []string{"Ahri", "Zed"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("AllChampions() = %v, want %v (Lux has no details)", names, want)
+	}
+
+	c.SetChampions(map[string]models.Champion{"Lux": {ID: 99, Key: "Lux", Name: "Lux"}})
+	if got := c.GetChampionsLen(); got != 1 {
+		t.Errorf("GetChampionsLen() after SetChampions = %d, want 1", got)
+	}
+}
+
 // TestLoadInvalidCache ensures invalid JSON is ignored and existing cache data is retained.
 func TestLoadInvalidCache(t *testing.T) {
 	dir := t.TempDir()
@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+)
+
+// searchIndex is an immutable index over a set of names, built once so that a
+// lookup neither normalizes every name again nor computes an edit distance
+// per name. Prefix lookups binary-search the names sorted by normalized form,
+// substring lookups narrow the candidates by trigram, and fuzzy lookups walk
+// a BK-tree.
+type searchIndex struct {
+	terms []indexTerm      // sorted by norm, then name
+	grams map[string][]int // trigram -> ascending positions in terms
+	bk    *bkNode
+}
+
+// indexTerm is one indexed name and its normalized form.
+type indexTerm struct {
+	norm string // preprocessString(name)
+	name string
+}
+
+// fuzzyMatch is a name within some edit distance of the input.
+type fuzzyMatch struct {
+	name string
+	dist int
+}
+
+// newSearchIndex indexes names. Names that normalize to nothing are skipped.
+func newSearchIndex(names []string) *searchIndex {
+	ix := &searchIndex{grams: make(map[string][]int)}
+	for _, name := range names {
+		if norm := preprocessString(name); norm != "" {
+			ix.terms = append(ix.terms, indexTerm{norm: norm, name: name})
+		}
+	}
+	sort.Slice(ix.terms, func(i, j int) bool {
+		if ix.terms[i].norm != ix.terms[j].norm {
+			return ix.terms[i].norm < ix.terms[j].norm
+		}
+		return ix.terms[i].name < ix.terms[j].name
+	})
+	for i, t := range ix.terms {
+		for _, g := range trigrams(t.norm) {
+			if posting := ix.grams[g]; len(posting) == 0 || posting[len(posting)-1] != i {
+				ix.grams[g] = append(posting, i)
+			}
+		}
+		if ix.bk == nil {
+			ix.bk = &bkNode{norm: t.norm}
+		}
+		ix.bk.insert(t)
+	}
+	return ix
+}
+
+// prefixed returns the terms whose normalized form starts with typed, which
+// must already be normalized.
+func (ix *searchIndex) prefixed(typed string) []indexTerm {
+	lo := sort.Search(len(ix.terms), func(i int) bool { return ix.terms[i].norm >= typed })
+	hi := lo
+	for hi < len(ix.terms) && strings.HasPrefix(ix.terms[hi].norm, typed) {
+		hi++
+	}
+	return ix.terms[lo:hi]
+}
+
+// containing returns the terms whose normalized form contains typed, in
+// index order. Inputs of three or more characters only check the names
+// sharing typed's rarest trigram.
+func (ix *searchIndex) containing(typed string) []indexTerm {
+	var out []indexTerm
+	grams := trigrams(typed)
+	if len(grams) == 0 {
+		for _, t := range ix.terms {
+			if strings.Contains(t.norm, typed) {
+				out = append(out, t)
+			}
+		}
+		return out
+	}
+	var rarest []int
+	for i, g := range grams {
+		posting := ix.grams[g]
+		if len(posting) == 0 {
+			return nil
+		}
+		if i == 0 || len(posting) < len(rarest) {
+			rarest = posting
+		}
+	}
+	for _, i := range rarest {
+		if strings.Contains(ix.terms[i].norm, typed) {
+			out = append(out, ix.terms[i])
+		}
+	}
+	return out
+}
+
+// within returns the names whose normalized form is at most radius edits
+// away from typed, in no particular order.
+func (ix *searchIndex) within(typed string, radius int) []fuzzyMatch {
+	if ix.bk == nil || radius < 0 {
+		return nil
+	}
+	var out []fuzzyMatch
+	stack := []*bkNode{ix.bk}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		d := levenshteinDistance(typed, n.norm)
+		if d <= radius {
+			for _, name := range n.names {
+				out = append(out, fuzzyMatch{name: name, dist: d})
+			}
+		}
+		// By the triangle inequality only children at distance d±radius
+		// from this node can hold matches.
+		for cd, child := range n.children {
+			if cd >= d-radius && cd <= d+radius {
+				stack = append(stack, child)
+			}
+		}
+	}
+	return out
+}
+
+// bkNode is a node of a BK-tree keyed by Levenshtein distance. Each node
+// holds every name sharing its normalized form.
+type bkNode struct {
+	norm     string
+	names    []string
+	children map[int]*bkNode
+}
+
+func (n *bkNode) insert(t indexTerm) {
+	for {
+		d := levenshteinDistance(t.norm, n.norm)
+		if d == 0 {
+			n.names = append(n.names, t.name)
+			return
+		}
+		child, ok := n.children[d]
+		if !ok {
+			if n.children == nil {
+				n.children = make(map[int]*bkNode)
+			}
+			n.children[d] = &bkNode{norm: t.norm, names: []string{t.name}}
+			return
+		}
+		n = child
+	}
+}
+
+// trigrams returns the distinct three-byte substrings of s.
+func trigrams(s string) []string {
+	if len(s) < 3 {
+		return nil
+	}
+	seen := make(map[string]bool, len(s)-2)
+	out := make([]string, 0, len(s)-2)
+	for i := 0; i+3 <= len(s); i++ {
+		if g := s[i : i+3]; !seen[g] {
+			seen[g] = true
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// championIndex is everything champion search needs, built from one champion
+// map: the name index, the alias index and the map itself for resolving
+// names to IDs. It is never modified once built, so readers may use it
+// without holding the cache lock.
+type championIndex struct {
+	ids       map[string]string // champion name -> ID, the ChampionMap it was built from
+	names     *searchIndex
+	aliases   map[string]aliasTarget // preprocessed alias -> champion
+	aliasKeys []string               // sorted keys of aliases
+}
+
+func buildChampionIndex(championMap, custom map[string]string, warn func(alias, champion string)) *championIndex {
+	names := make([]string, 0, len(championMap))
+	for name := range championMap {
+		names = append(names, name)
+	}
+	ci := &championIndex{
+		ids:     championMap,
+		names:   newSearchIndex(names),
+		aliases: buildAliasIndex(championMap, custom, warn),
+	}
+	ci.aliasKeys = make([]string, 0, len(ci.aliases))
+	for alias := range ci.aliases {
+		ci.aliasKeys = append(ci.aliasKeys, alias)
+	}
+	sort.Strings(ci.aliasKeys)
+	return ci
+}
+
+// aliasesWithPrefix returns the aliases starting with typed, in order.
+func (ci *championIndex) aliasesWithPrefix(typed string) []string {
+	lo := sort.SearchStrings(ci.aliasKeys, typed)
+	hi := lo
+	for hi < len(ci.aliasKeys) && strings.HasPrefix(ci.aliasKeys[hi], typed) {
+		hi++
+	}
+	return ci.aliasKeys[lo:hi]
+}
+
+// reindex rebuilds the champion index for the current champion map and
+// aliases. c.mu must be held for writing.
+func (c *Cache) reindex() {
+	ci := c.buildIndex()
+	c.idxMu.Lock()
+	c.idx = ci
+	c.idxMu.Unlock()
+}
+
+// currentIndex returns the champion index, building it if the champion map
+// was assigned directly rather than through a setter. The read lock is held
+// only long enough to fetch it.
+func (c *Cache) currentIndex() *championIndex {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.idxMu.Lock()
+	defer c.idxMu.Unlock()
+	if c.idx == nil {
+		c.idx = c.buildIndex()
+	}
+	return c.idx
+}
+
+func (c *Cache) buildIndex() *championIndex {
+	return buildChampionIndex(c.ChampionMap, c.Aliases, func(alias, champion string) {
+		c.logWarn("Champion alias names an unknown champion", "alias", alias, "champion", champion)
+	})
+}
@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// roster returns the champion map of the bundled offline snapshot, a
+// realistic set of names to search.
+func roster(tb testing.TB) map[string]string {
+	tb.Helper()
+	f, err := os.Open("../data/snapshot.json.gz")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var s struct {
+		ChampionMap map[string]string `json:"champion_map"`
+	}
+	if err := json.NewDecoder(zr).Decode(&s); err != nil {
+		tb.Fatal(err)
+	}
+	// Add display names with spaces and punctuation the snapshot keys lack.
+	for name, key := range map[string]string{"Miss Fortune": "MissFortune", "Kog'Maw": "KogMaw", "Nunu & Willump": "Nunu"} {
+		s.ChampionMap[name] = key
+	}
+	return s.ChampionMap
+}
+
+// linearMatch is the scan the index replaced: every name is normalized and
+// compared on every call. It is the reference for the indexed search.
+func linearMatch(championMap map[string]string, typed string, threshold, limit int) []string {
+	if typed == "" {
+		return nil
+	}
+	truncate := func(names []string) []string {
+		if limit > 0 && len(names) > limit {
+			return names[:limit]
+		}
+		return names
+	}
+	var names []string
+	for name := range championMap {
+		if strings.HasPrefix(preprocessString(name), typed) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		for name := range championMap {
+			if strings.Contains(preprocessString(name), typed) {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return truncate(names)
+	}
+	var fuzzy []fuzzyMatch
+	for name := range championMap {
+		if weighted, ok := fuzzyScore(typed, preprocessString(name), threshold); ok {
+			fuzzy = append(fuzzy, fuzzyMatch{name: name, dist: weighted})
+		}
+	}
+	sort.Slice(fuzzy, func(i, j int) bool {
+		if fuzzy[i].dist != fuzzy[j].dist {
+			return fuzzy[i].dist < fuzzy[j].dist
+		}
+		return fuzzy[i].name < fuzzy[j].name
+	})
+	for _, m := range fuzzy {
+		names = append(names, m.name)
+	}
+	return truncate(names)
+}
+
+// queries returns prefixes, inner substrings and one-letter typos of each
+// name, plus a few inputs that match nothing.
+func queries(championMap map[string]string) []string {
+	out := []string{"zzzz", "q", "xyzzyplugh"}
+	for name := range championMap {
+		n := preprocessString(name)
+		for i := 1; i <= len(n); i++ {
+			out = append(out, n[:i])
+		}
+		if len(n) > 4 {
+			out = append(out, n[1:4], n[:2]+"x"+n[3:], n[1:]+"s")
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestChampionIndex_MatchesLinearScan(t *testing.T) {
+	m := roster(t)
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	ci := &championIndex{ids: m, names: newSearchIndex(names)} // no aliases
+	for _, typed := range queries(m) {
+		got, _ := ci.match(typed, 3, 10)
+		if want := linearMatch(m, typed, 3, 10); !reflect.DeepEqual(got, want) {
+			t.Errorf("match(%q) = %v, want %v", typed, got, want)
+		}
+	}
+}
+
+func TestSearchIndex_Within(t *testing.T) {
+	names := []string{"Ahri", "Akali", "Ashe", "Azir", "Annie", "Anivia", "Nunu & Willump", "Nunu"}
+	ix := newSearchIndex(names)
+	for _, typed := range []string{"ahri", "ari", "ane", "nunu", "nunuwilump", "z"} {
+		for radius := 0; radius <= 3; radius++ {
+			var got, want []string
+			for _, m := range ix.within(typed, radius) {
+				got = append(got, m.name)
+			}
+			for _, name := range names {
+				if levenshteinDistance(typed, preprocessString(name)) <= radius {
+					want = append(want, name)
+				}
+			}
+			sort.Strings(got)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("within(%q, %d) = %v, want %v", typed, radius, got, want)
+			}
+		}
+	}
+}
+
+func TestLevenshteinDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "ab", 2},
+		{"kitten", "sitting", 3},
+		{"sitting", "kitten", 3},
+		{"brom", "braum", 2},
+		{"ahri", "ahri", 0},
+	} {
+		if got := levenshteinDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("levenshteinDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+// Compare with BenchmarkLinearScan to see what the index saves per keystroke.
+func BenchmarkAutocomplete(b *testing.B) {
+	c := New("", 3)
+	c.SetChampionMap(roster(b))
+	for _, typed := range []string{"a", "mis", "ndo", "zeed", "xyzzyplugh"} {
+		b.Run(typed, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Autocomplete(typed, 10)
+			}
+		})
+	}
+}
+
+func BenchmarkLinearScan(b *testing.B) {
+	m := roster(b)
+	for _, typed := range []string{"a", "mis", "ndo", "zeed", "xyzzyplugh"} {
+		b.Run(typed, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				linearMatch(m, typed, 3, 10)
+			}
+		})
+	}
+}
+
+func BenchmarkSearchChampionName(b *testing.B) {
+	c := New("", 3)
+	c.SetChampionMap(roster(b))
+	for _, input := range []string{"Miss Fortune", "zeed", "xyzzyplugh"} {
+		b.Run(input, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.SearchChampionName(input); err != nil && input != "xyzzyplugh" {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBuildIndex(b *testing.B) {
+	m := roster(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buildChampionIndex(m, nil, nil)
+	}
+}
@@ -0,0 +1,429 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/fileutil"
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+// DefaultMaxMatches is the number of matches a Matches store keeps unless
+// told otherwise.
+const DefaultMaxMatches = 20000
+
+// remakeSeconds is the game length below which a match is taken for a remake
+// and not stored: nobody played the lane.
+const remakeSeconds = 300
+
+// StoredParticipant is what a Matches store keeps of a match participant.
+type StoredParticipant struct {
+	PUUID    string `json:"puuid"`
+	GameName string `json:"game_name,omitempty"`
+	TagLine  string `json:"tag_line,omitempty"`
+	TeamID   int    `json:"team_id"`
+	Champion string `json:"champion"`           // champion key
+	Position string `json:"position,omitempty"` // assigned role, see models.MatchParticipant.Position
+	Win      bool   `json:"win,omitempty"`
+	Kills    int    `json:"kills"`
+	Deaths   int    `json:"deaths"`
+	Assists  int    `json:"assists"`
+	Gold     int    `json:"gold"` // earned by the end of the game
+}
+
+// StoredMatch is what a Matches store keeps of a match: enough to pair up
+// the laners of each side.
+type StoredMatch struct {
+	ID           string              `json:"id"`
+	Patch        string              `json:"patch,omitempty"` // major.minor, e.g. 14.10
+	QueueID      int                 `json:"queue_id"`
+	Started      time.Time           `json:"started"`
+	Participants []StoredParticipant `json:"participants"`
+}
+
+// gamePatch returns the major.minor patch of a match's game version, e.g.
+// "14.10" for "14.10.585.1234".
+func gamePatch(version string) string {
+	major, rest, ok := strings.Cut(version, ".")
+	if !ok {
+		return ""
+	}
+	minor, _, _ := strings.Cut(rest, ".")
+	return major + "." + minor
+}
+
+// Matches is a local store of the matches the server has fetched, kept for
+// matchup statistics across every player looked up rather than one match
+// history page. When the store is full, the oldest games are dropped. The
+// methods are safe for concurrent use and on a nil *Matches, which records
+// nothing.
+type Matches struct {
+	Path   string      // file for Load and Save; empty keeps the store in memory
+	Max    int         // capacity; zero or less means DefaultMaxMatches
+	Logger *log.Logger // optional
+
+	mu    sync.RWMutex
+	byID  map[string]StoredMatch
+	dirty bool // changed since the last Save
+	now   func() time.Time
+}
+
+// NewMatches creates an empty store saved at path.
+func NewMatches(path string, max int) *Matches {
+	return &Matches{Path: path, Max: max, byID: make(map[string]StoredMatch), now: time.Now}
+}
+
+// Len returns the number of matches stored.
+func (m *Matches) Len() int {
+	if m == nil {
+		return 0
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.byID)
+}
+
+// Observe stores match unless it is already stored, has no ID or was a
+// remake.
+func (m *Matches) Observe(match models.MatchDTO) {
+	if m == nil || match.Metadata.MatchID == "" || match.Info.GameDuration < remakeSeconds {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.byID[match.Metadata.MatchID]; ok {
+		return
+	}
+	sm := StoredMatch{
+		ID:           match.Metadata.MatchID,
+		Patch:        gamePatch(match.Info.GameVersion),
+		QueueID:      match.Info.QueueID,
+		Started:      time.UnixMilli(match.Info.GameStartTimestamp).UTC(),
+		Participants: make([]StoredParticipant, 0, len(match.Info.Participants)),
+	}
+	for _, p := range match.Info.Participants {
+		sm.Participants = append(sm.Participants, StoredParticipant{
+			PUUID:    p.PUUID,
+			GameName: p.RiotIDGameName,
+			TagLine:  p.RiotIDTagline,
+			TeamID:   p.TeamID,
+			Champion: p.ChampionName,
+			Position: p.Position(),
+			Win:      p.Win,
+			Kills:    p.Kills,
+			Deaths:   p.Deaths,
+			Assists:  p.Assists,
+			Gold:     p.GoldEarned,
+		})
+	}
+	m.byID[sm.ID] = sm
+	m.dirty = true
+	m.evict()
+}
+
+// evict drops the oldest matches once the store is over capacity, down to
+// nine tenths of it so that eviction does not run on every call. m.mu must
+// be held for writing.
+func (m *Matches) evict() {
+	max := m.Max
+	if max <= 0 {
+		max = DefaultMaxMatches
+	}
+	if len(m.byID) <= max {
+		return
+	}
+	all := make([]StoredMatch, 0, len(m.byID))
+	for _, sm := range m.byID {
+		all = append(all, sm)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].Started.Equal(all[j].Started) {
+			return all[i].Started.Before(all[j].Started)
+		}
+		return all[i].ID < all[j].ID
+	})
+	for _, sm := range all[:len(all)-max*9/10] {
+		delete(m.byID, sm.ID)
+	}
+}
+
+// MatchupQuery selects the lane games matchup statistics are computed over.
+// Empty fields match anything.
+type MatchupQuery struct {
+	Champion string   // champion key played
+	Opponent string   // champion key of the laner played against
+	Role     string   // Riot position, e.g. MIDDLE
+	Patch    string   // major.minor
+	Team     []string // Riot IDs ("name#tag") whose games count; empty counts every player
+}
+
+// MatchupStat sums the lane games of Champion against Opponent in Role.
+type MatchupStat struct {
+	Champion string `json:"champion"`
+	Opponent string `json:"opponent"`
+	Role     string `json:"role"`
+	Games    int    `json:"games"`
+	Wins     int    `json:"wins"`
+	GoldDiff int    `json:"goldDiff"` // summed end-of-game gold lead over the opponent
+	Kills    int    `json:"kills"`
+	Deaths   int    `json:"deaths"`
+	Assists  int    `json:"assists"`
+}
+
+// Losses returns the games lost.
+func (s MatchupStat) Losses() int {
+	return s.Games - s.Wins
+}
+
+// WinRate returns the share of games won, from 0 to 1.
+func (s MatchupStat) WinRate() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return float64(s.Wins) / float64(s.Games)
+}
+
+// AvgGoldDiff returns the average end-of-game gold lead over the opponent.
+func (s MatchupStat) AvgGoldDiff() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return float64(s.GoldDiff) / float64(s.Games)
+}
+
+// KDA returns (kills + assists) / deaths, counting no deaths as one.
+func (s MatchupStat) KDA() float64 {
+	return float64(s.Kills+s.Assists) / float64(max(s.Deaths, 1))
+}
+
+func (s *MatchupStat) add(p, opp StoredParticipant) {
+	s.Games++
+	if p.Win {
+		s.Wins++
+	}
+	s.GoldDiff += p.Gold - opp.Gold
+	s.Kills += p.Kills
+	s.Deaths += p.Deaths
+	s.Assists += p.Assists
+}
+
+// Matchups returns the lane games selected by q, summed per champion,
+// opponent and role, most games first.
+func (m *Matches) Matchups(q MatchupQuery) []MatchupStat {
+	type key struct{ champion, opponent, role string }
+	sums := make(map[key]*MatchupStat)
+	m.lanes(q, func(p, opp StoredParticipant) {
+		k := key{p.Champion, opp.Champion, p.Position}
+		s := sums[k]
+		if s == nil {
+			s = &MatchupStat{Champion: p.Champion, Opponent: opp.Champion, Role: p.Position}
+			sums[k] = s
+		}
+		s.add(p, opp)
+	})
+	out := make([]MatchupStat, 0, len(sums))
+	for _, s := range sums {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Games != b.Games {
+			return a.Games > b.Games
+		}
+		if a.Opponent != b.Opponent {
+			return a.Opponent < b.Opponent
+		}
+		if a.Champion != b.Champion {
+			return a.Champion < b.Champion
+		}
+		return a.Role < b.Role
+	})
+	return out
+}
+
+// Total returns every lane game selected by q summed into one record, with
+// the query's champion, opponent and role.
+func (m *Matches) Total(q MatchupQuery) MatchupStat {
+	total := MatchupStat{Champion: q.Champion, Opponent: q.Opponent, Role: q.Role}
+	m.lanes(q, total.add)
+	return total
+}
+
+// Patches returns the patches of the stored matches, newest first.
+func (m *Matches) Patches() []string {
+	if m == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	m.mu.RLock()
+	for _, sm := range m.byID {
+		if sm.Patch != "" {
+			seen[sm.Patch] = true
+		}
+	}
+	m.mu.RUnlock()
+	out := make([]string, 0, len(seen))
+	for p := range seen {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return patchLess(out[j], out[i]) })
+	return out
+}
+
+// patchLess orders major.minor patches numerically.
+func patchLess(a, b string) bool {
+	amaj, amin, _ := strings.Cut(a, ".")
+	bmaj, bmin, _ := strings.Cut(b, ".")
+	if x, y := atoi(amaj), atoi(bmaj); x != y {
+		return x < y
+	}
+	return atoi(amin) < atoi(bmin)
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// lanes calls fn with each participant selected by q and the laner they
+// faced: the player of the same position on the other team. Participants
+// without a position, or whose lane opponent is missing, are skipped.
+func (m *Matches) lanes(q MatchupQuery, fn func(p, opp StoredParticipant)) {
+	if m == nil {
+		return
+	}
+	var team map[string]bool
+	if len(q.Team) > 0 {
+		team = make(map[string]bool, len(q.Team))
+		for _, id := range q.Team {
+			name, tag, _ := strings.Cut(id, "#")
+			team[playerKey(name, tag)] = true
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, sm := range m.byID {
+		if q.Patch != "" && sm.Patch != q.Patch {
+			continue
+		}
+		for _, p := range sm.Participants {
+			if p.Position == "" || p.Position == "Invalid" ||
+				(q.Role != "" && p.Position != q.Role) ||
+				(q.Champion != "" && !strings.EqualFold(p.Champion, q.Champion)) ||
+				(team != nil && !team[playerKey(p.GameName, p.TagLine)]) {
+				continue
+			}
+			for _, opp := range sm.Participants {
+				if opp.TeamID != p.TeamID && opp.Position == p.Position {
+					if q.Opponent == "" || strings.EqualFold(opp.Champion, q.Opponent) {
+						fn(p, opp)
+					}
+					break
+				}
+			}
+		}
+	}
+}
+
+// persistedMatches is the on-disk form of a Matches store.
+type persistedMatches struct {
+	SavedAt time.Time     `json:"saved_at"`
+	Matches []StoredMatch `json:"matches"`
+}
+
+// Load replaces the store with the matches saved at Path. A missing file
+// leaves the store empty.
+func (m *Matches) Load() error {
+	if m == nil || m.Path == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(m.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading match store: %w", err)
+	}
+	var persisted persistedMatches
+	if err := json.Unmarshal(raw, &persisted); err != nil {
+		return fmt.Errorf("decoding match store %s: %w", m.Path, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byID = make(map[string]StoredMatch, len(persisted.Matches))
+	for _, sm := range persisted.Matches {
+		if sm.ID != "" {
+			m.byID[sm.ID] = sm
+		}
+	}
+	m.evict()
+	m.dirty = false
+	if m.Logger != nil {
+		m.Logger.Info("Match store loaded", "matches", len(m.byID))
+	}
+	return nil
+}
+
+// Save writes the store to Path atomically if it changed since it was
+// loaded or last saved.
+func (m *Matches) Save() error {
+	if m == nil || m.Path == "" {
+		return nil
+	}
+	m.mu.Lock()
+	if !m.dirty {
+		m.mu.Unlock()
+		return nil
+	}
+	persisted := persistedMatches{SavedAt: m.now().UTC(), Matches: make([]StoredMatch, 0, len(m.byID))}
+	for _, sm := range m.byID {
+		persisted.Matches = append(persisted.Matches, sm)
+	}
+	m.dirty = false
+	m.mu.Unlock()
+
+	sort.Slice(persisted.Matches, func(i, j int) bool { return persisted.Matches[i].ID < persisted.Matches[j].ID })
+	data, err := json.Marshal(&persisted)
+	if err == nil {
+		err = fileutil.WriteFileAtomic(m.Path, data, 0o644)
+	}
+	if err != nil {
+		m.mu.Lock()
+		m.dirty = true
+		m.mu.Unlock()
+		return fmt.Errorf("saving match store: %w", err)
+	}
+	return nil
+}
+
+// Autosave saves the store every interval while it keeps changing, until ctx
+// is done.
+func (m *Matches) Autosave(ctx context.Context, interval time.Duration) {
+	if m == nil || m.Path == "" {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := m.Save(); err != nil && m.Logger != nil {
+				m.Logger.Warn("Match store not saved", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+// laneMatch builds a match of id on version where blue plays blueChamps and
+// red plays redChamps in lanes, blue winning if blueWins. Blue players are
+// "Blue<lane>#EUW", red players "Red<lane>#EUW"; each earns 10000 gold plus
+// its gold argument.
+func laneMatch(id, version string, started int64, blueWins bool, lanes []string, blueChamps, redChamps []string, blueGold, redGold int) models.MatchDTO {
+	m := models.MatchDTO{
+		Metadata: models.MatchMetadata{MatchID: id},
+		Info:     models.MatchInfo{GameDuration: 1800, GameVersion: version, GameStartTimestamp: started, QueueID: 420},
+	}
+	for i, lane := range lanes {
+		m.Info.Participants = append(m.Info.Participants,
+			models.MatchParticipant{
+				PUUID: "blue-" + lane, RiotIDGameName: "Blue" + lane, RiotIDTagline: "EUW", TeamID: 100,
+				ChampionName: blueChamps[i], IndividualPosition: lane, Win: blueWins,
+				Kills: 4, Deaths: 2, Assists: 6, GoldEarned: 10000 + blueGold,
+			},
+			models.MatchParticipant{
+				PUUID: "red-" + lane, RiotIDGameName: "Red" + lane, RiotIDTagline: "EUW", TeamID: 200,
+				ChampionName: redChamps[i], IndividualPosition: lane, Win: !blueWins,
+				Kills: 2, Deaths: 4, Assists: 1, GoldEarned: 10000 + redGold,
+			})
+	}
+	return m
+}
+
+func TestMatches_Matchups(t *testing.T) {
+	m := NewMatches("", 0)
+	lanes := []string{"TOP", "MIDDLE"}
+	m.Observe(laneMatch("EUW1_1", "14.10.585.1", 1, true, lanes, []string{"Darius", "Ahri"}, []string{"Garen", "Zed"}, 1000, 0))
+	m.Observe(laneMatch("EUW1_2", "14.10.585.1", 2, false, lanes, []string{"Darius", "Zed"}, []string{"Garen", "Ahri"}, 0, 500))
+	m.Observe(laneMatch("EUW1_3", "14.11.590.2", 3, true, lanes, []string{"Garen", "Ahri"}, []string{"Darius", "Zed"}, 200, 0))
+	m.Observe(laneMatch("EUW1_3", "14.11.590.2", 3, true, lanes, []string{"Garen", "Ahri"}, []string{"Darius", "Zed"}, 200, 0)) // already stored
+	remake := laneMatch("EUW1_4", "14.11.590.2", 4, true, lanes, []string{"Darius", "Ahri"}, []string{"Garen", "Zed"}, 0, 0)
+	remake.Info.GameDuration = 200
+	m.Observe(remake)
+	if m.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", m.Len())
+	}
+
+	got := m.Matchups(MatchupQuery{Champion: "darius"})
+	want := []MatchupStat{{Champion: "Darius", Opponent: "Garen", Role: "TOP", Games: 3, Wins: 1, GoldDiff: 1000 - 500 - 200, Kills: 10, Deaths: 8, Assists: 13}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Matchups(Darius) = %+v, want %+v", got, want)
+	}
+	if s := got[0]; s.Losses() != 2 || s.AvgGoldDiff() != 100 || s.KDA() != 23.0/8 {
+		t.Errorf("Losses, AvgGoldDiff, KDA = %d, %g, %g", s.Losses(), s.AvgGoldDiff(), s.KDA())
+	}
+
+	if got := m.Total(MatchupQuery{Champion: "Darius", Patch: "14.10"}); got.Games != 2 || got.Wins != 1 {
+		t.Errorf("Total(Darius, 14.10) = %+v, want 1-1", got)
+	}
+	if got := m.Total(MatchupQuery{Champion: "Darius", Role: "MIDDLE"}); got.Games != 0 {
+		t.Errorf("Total(Darius, MIDDLE) = %+v, want no games", got)
+	}
+	if got := m.Total(MatchupQuery{Opponent: "Zed", Team: []string{"Blue MIDDLE#euw"}}); got.Games != 2 || got.Wins != 2 {
+		t.Errorf("Total(vs Zed, blue mid) = %+v, want 2-0", got)
+	}
+	if got := m.Patches(); !reflect.DeepEqual(got, []string{"14.11", "14.10"}) {
+		t.Errorf("Patches = %v", got)
+	}
+}
+
+func TestMatches_SkipsLanesWithoutOpponent(t *testing.T) {
+	m := NewMatches("", 0)
+	match := laneMatch("EUW1_1", "14.10.1", 1, true, []string{"TOP"}, []string{"Darius"}, []string{"Garen"}, 0, 0)
+	match.Info.Participants[1].IndividualPosition = "Invalid"
+	m.Observe(match)
+	if got := m.Matchups(MatchupQuery{}); len(got) != 0 {
+		t.Errorf("Matchups = %+v, want none", got)
+	}
+}
+
+func TestMatches_PairsOnTeamPosition(t *testing.T) {
+	m := NewMatches("", 0)
+	match := laneMatch("EUW1_1", "14.10.1", 1, true, []string{"TOP", "MIDDLE"}, []string{"Darius", "Ahri"}, []string{"Garen", "Zed"}, 0, 0)
+	for i := range match.Info.Participants {
+		p := &match.Info.Participants[i]
+		p.TeamPosition = p.IndividualPosition
+	}
+	// Riot guessed blue's top laner was mid; the assigned role still says top.
+	match.Info.Participants[0].IndividualPosition = "MIDDLE"
+	m.Observe(match)
+
+	if got := m.Total(MatchupQuery{Champion: "Darius", Opponent: "Garen", Role: "TOP"}); got.Games != 1 {
+		t.Errorf("Darius vs Garen top = %+v, want 1 game", got)
+	}
+	if got := m.Total(MatchupQuery{Opponent: "Darius"}); got.Games != 1 {
+		t.Errorf("games against Darius = %+v, want only Garen's", got)
+	}
+	if got := m.Total(MatchupQuery{Champion: "Darius", Opponent: "Zed"}); got.Games != 0 {
+		t.Errorf("Darius paired with Zed on Riot's guess: %+v", got)
+	}
+}
+
+func TestPatchLess(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"14.9", "14.10", true},
+		{"14.10", "14.9", false},
+		{"9.24", "10.1", true},
+		{"14.10", "14.10", false},
+	} {
+		if got := patchLess(tc.a, tc.b); got != tc.want {
+			t.Errorf("patchLess(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestMatches_EvictsOldest(t *testing.T) {
+	m := NewMatches("", 10)
+	for i := range 11 {
+		m.Observe(laneMatch("EUW1_"+string(rune('a'+i)), "14.10.1", int64(i)*1000, true, []string{"TOP"}, []string{"Darius"}, []string{"Garen"}, 0, 0))
+	}
+	if m.Len() != 9 {
+		t.Fatalf("Len = %d, want 9 after eviction", m.Len())
+	}
+	if got := m.Total(MatchupQuery{}); got.Games != 18 {
+		t.Errorf("Total games = %d, want 18", got.Games)
+	}
+}
+
+func TestMatches_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "matches.json")
+	m := NewMatches(path, 0)
+	m.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	m.Observe(laneMatch("EUW1_1", "14.10.1", 1, true, []string{"TOP"}, []string{"Darius"}, []string{"Garen"}, 300, 0))
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewMatches(path, 0)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	want := m.Matchups(MatchupQuery{})
+	if got := loaded.Matchups(MatchupQuery{}); !reflect.DeepEqual(got, want) || len(got) != 2 {
+		t.Errorf("loaded Matchups = %+v, want %+v", got, want)
+	}
+	if got := loaded.Patches(); !reflect.DeepEqual(got, []string{"14.10"}) {
+		t.Errorf("loaded Patches = %v", got)
+	}
+}
+
+func TestMatches_Nil(t *testing.T) {
+	var m *Matches
+	m.Observe(laneMatch("EUW1_1", "14.10.1", 1, true, []string{"TOP"}, []string{"Darius"}, []string{"Garen"}, 0, 0))
+	if m.Len() != 0 || len(m.Matchups(MatchupQuery{})) != 0 || m.Total(MatchupQuery{}).Games != 0 {
+		t.Error("nil store recorded a match")
+	}
+	if err := m.Save(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+// memorySweepEvery controls how often Set scans for expired KV entries.
+const memorySweepEvery = 256
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time // zero means no expiry
+}
+
+// MemoryBackend is a process-local Backend. It is the default and suits a
+// single instance; data is lost on restart unless the Cache file is used.
+type MemoryBackend struct {
+	mu        sync.RWMutex
+	snapshot  Snapshot
+	champions map[string]models.Champion
+	kv        map[string]memoryEntry
+	sets      int
+}
+
+// NewMemoryBackend returns an empty MemoryBackend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		champions: make(map[string]models.Champion),
+		kv:        make(map[string]memoryEntry),
+	}
+}
+
+func (m *MemoryBackend) LoadSnapshot(ctx context.Context) (Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.snapshot, nil
+}
+
+func (m *MemoryBackend) SaveSnapshot(ctx context.Context, s Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.snapshot = s
+	return nil
+}
+
+func (m *MemoryBackend) GetChampion(ctx context.Context, key string) (models.Champion, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	champ, ok := m.champions[key]
+	return champ, ok, nil
+}
+
+func (m *MemoryBackend) SetChampion(ctx context.Context, champion models.Champion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.champions[champion.Key] = champion
+	return nil
+}
+
+func (m *MemoryBackend) ClearChampions(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.champions = make(map[string]models.Champion)
+	return nil
+}
+
+func (m *MemoryBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	e, ok := m.kv[key]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		m.mu.Lock()
+		if cur, ok := m.kv[key]; ok && cur.expires.Equal(e.expires) {
+			delete(m.kv, key)
+		}
+		m.mu.Unlock()
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+func (m *MemoryBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	e := memoryEntry{value: append([]byte(nil), value...)}
+	now := time.Now()
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.kv[key] = e
+	m.sets++
+	if m.sets%memorySweepEvery == 0 {
+		for k, v := range m.kv {
+			if !v.expires.IsZero() && now.After(v.expires) {
+				delete(m.kv, k)
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MemoryBackend) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.kv, key)
+	return nil
+}
+
+func (m *MemoryBackend) Close() error { return nil }
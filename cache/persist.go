@@ -0,0 +1,243 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/fileutil"
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+// SchemaVersion is the version of the cache file format written by Save.
+//
+// Version history:
+//
+//	1: unversioned JSON object (patch, champions, champion_map,
+//	   champion_key_map, summoner_spells)
+//	2: adds the "version" field and "saved_at" timestamp
+const SchemaVersion = 2
+
+// ErrUnsupportedVersion is returned by Load for files written by a newer
+// schema than this build understands. Such files are left untouched.
+var ErrUnsupportedVersion = errors.New("unsupported cache file version")
+
+// persistedCache is the on-disk layout of the current schema version.
+type persistedCache struct {
+	Version        int                             `json:"version"`
+	SavedAt        time.Time                       `json:"saved_at,omitzero"`
+	Patch          string                          `json:"patch"`
+	Champions      map[string]models.Champion      `json:"champions"`
+	ChampionMap    map[string]string               `json:"champion_map"`
+	ChampionKeyMap map[string]string               `json:"champion_key_map"`
+	SummonerSpells map[string]models.SummonerSpell `json:"summoner_spells"`
+}
+
+// migration upgrades a decoded cache document from version N to N+1 in place.
+type migration func(doc map[string]json.RawMessage) error
+
+// migrations maps a source version to the function upgrading it to the next
+// version. Load applies them in sequence up to SchemaVersion.
+var migrations = map[int]migration{
+	1: migrateV1ToV2,
+}
+
+// migrateV1ToV2 stamps legacy files with a version; the field layout is unchanged.
+func migrateV1ToV2(doc map[string]json.RawMessage) error {
+	doc["version"] = json.RawMessage("2")
+	return nil
+}
+
+// Load reads the persisted cache from file and then adopts newer data from the
+// backend, if it holds any (see Sync).
+func (c *Cache) Load() error {
+	if err := c.loadFile(); err != nil {
+		return err
+	}
+	if c.Backend == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+	defer cancel()
+	synced, err := c.Sync(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load cache from backend: %w", err)
+	}
+	if synced {
+		c.logInfo("Cache loaded from backend", "patch", c.GetPatch(), "champion_names", c.GetChampionMapLen())
+	}
+	return nil
+}
+
+// loadFile reads the persisted cache from file, migrating older schema
+// versions forward. A missing file (or empty Path) is a cache miss. A file that
+// cannot be decoded is renamed to "<path>.corrupt-<timestamp>" so it can be
+// inspected later, and the in-memory cache is left as it was.
+func (c *Cache) loadFile() error {
+	if c.Path == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(c.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read cache file: %w", err)
+	}
+
+	persist, fromVersion, err := decodeCache(raw)
+	if errors.Is(err, ErrUnsupportedVersion) {
+		return err
+	}
+	if err != nil {
+		dest, qerr := c.quarantine()
+		if qerr != nil {
+			return fmt.Errorf("cache file is corrupt (%v) and could not be quarantined: %w", err, qerr)
+		}
+		c.logWarn("Cache file is corrupt; moved aside and starting empty", "error", err, "quarantined", dest)
+		return nil
+	}
+
+	c.mu.Lock()
+	c.Patch = persist.Patch
+	if persist.ChampionMap != nil {
+		c.ChampionMap = persist.ChampionMap
+		c.reindex()
+	}
+	if persist.ChampionKeyMap != nil {
+		c.ChampionKeyMap = persist.ChampionKeyMap
+	}
+	if persist.Champions != nil {
+		c.Champions = persist.Champions
+	}
+	if persist.SummonerSpells != nil {
+		c.SummonerSpells = persist.SummonerSpells
+	}
+	c.mu.Unlock()
+
+	if fromVersion != SchemaVersion {
+		c.logInfo("Migrated cache file", "from", fromVersion, "to", SchemaVersion)
+	}
+	c.logInfo("Cache loaded", "path", c.Path, "version", fromVersion, "patch", persist.Patch,
+		"champion_names", len(persist.ChampionMap), "champions", len(persist.Champions),
+		"summoner_spells", len(persist.SummonerSpells))
+	return nil
+}
+
+// decodeCache parses a cache document, applies migrations and returns it in
+// the current layout together with the version it was stored as.
+func decodeCache(raw []byte) (persistedCache, int, error) {
+	var persist persistedCache
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return persist, 0, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if doc == nil {
+		return persist, 0, errors.New("cache document is null")
+	}
+
+	version := 1
+	if v, ok := doc["version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return persist, 0, fmt.Errorf("invalid version field: %w", err)
+		}
+	}
+	if version < 1 {
+		return persist, version, fmt.Errorf("invalid version %d", version)
+	}
+	if version > SchemaVersion {
+		return persist, version, fmt.Errorf("%w %d (this build supports up to %d)", ErrUnsupportedVersion, version, SchemaVersion)
+	}
+
+	from := version
+	for ; version < SchemaVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return persist, from, fmt.Errorf("no migration from version %d", version)
+		}
+		if err := migrate(doc); err != nil {
+			return persist, from, fmt.Errorf("migrating from version %d: %w", version, err)
+		}
+	}
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return persist, from, fmt.Errorf("re-encoding migrated cache: %w", err)
+	}
+	if err := json.Unmarshal(upgraded, &persist); err != nil {
+		return persist, from, fmt.Errorf("decoding cache: %w", err)
+	}
+	return persist, from, nil
+}
+
+// quarantine renames the cache file out of the way and returns its new path.
+func (c *Cache) quarantine() (string, error) {
+	dest := fmt.Sprintf("%s.corrupt-%s", c.Path, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.Rename(c.Path, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// Save stores the patch-level data in the backend and writes the cache to
+// file atomically: the data is written to a temporary file in the same
+// directory, flushed to disk, and renamed over the old file, so a crash leaves
+// either the previous or the new cache, never a partial one. The file is
+// written even when the backend fails, and that error is returned after. An
+// empty Path skips the file.
+func (c *Cache) Save() error {
+	c.mu.RLock()
+	snapshot := Snapshot{
+		Patch:          c.Patch,
+		ChampionMap:    c.ChampionMap,
+		ChampionKeyMap: c.ChampionKeyMap,
+		SummonerSpells: c.SummonerSpells,
+	}
+	persist := persistedCache{
+		Version:        SchemaVersion,
+		SavedAt:        time.Now().UTC(),
+		Patch:          c.Patch,
+		Champions:      c.Champions,
+		ChampionMap:    c.ChampionMap,
+		ChampionKeyMap: c.ChampionKeyMap,
+		SummonerSpells: c.SummonerSpells,
+	}
+	data, err := json.Marshal(&persist)
+	c.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cache data: %w", err)
+	}
+
+	// A backend outage must not cost the local file as well.
+	var backendErr error
+	if c.Backend != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+		if err := c.Backend.SaveSnapshot(ctx, snapshot); err != nil {
+			backendErr = fmt.Errorf("failed to save cache to backend: %w", err)
+		}
+	}
+	if c.Path == "" {
+		return backendErr
+	}
+	if err := fileutil.WriteFileAtomic(c.Path, data, 0o644); err != nil {
+		return errors.Join(backendErr, fmt.Errorf("failed to write cache file: %w", err))
+	}
+	return backendErr
+}
+
+func (c *Cache) logInfo(msg string, keyvals ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Info(msg, keyvals...)
+	}
+}
+
+func (c *Cache) logWarn(msg string, keyvals ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Warn(msg, keyvals...)
+	}
+}
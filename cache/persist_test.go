@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSaveWritesVersionedFileAtomically checks the saved file carries the
+// schema version and no temporary files are left behind.
+func TestSaveWritesVersionedFileAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.json")
+	c := New(path, 3)
+	c.SetPatch("15.1.1")
+	c.SetChampionMap(map[string]string{"Ahri": "Ahri"})
+
+	// Save twice so the second write replaces an existing file.
+	for range 2 {
+		if err := c.Save(); err != nil {
+			t.Fatalf("Save() error: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "cache.json" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("expected only cache.json in dir, got %v", names)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	var doc struct {
+		Version int    `json:"version"`
+		Patch   string `json:"patch"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("saved file is not valid JSON: %v", err)
+	}
+	if doc.Version != SchemaVersion || doc.Patch != "15.1.1" {
+		t.Errorf("saved header: got %+v, want version %d patch 15.1.1", doc, SchemaVersion)
+	}
+}
+
+// TestLoadMigratesLegacyFile loads an unversioned (v1) file.
+// downBackend is a backend whose snapshot writes fail, like Redis during an
+// outage.
+type downBackend struct{ *MemoryBackend }
+
+func (downBackend) SaveSnapshot(context.Context, Snapshot) error {
+	return errors.New("connection refused")
+}
+
+func TestSaveWritesFileWhenBackendFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	c := New(path, 3)
+	c.Backend = downBackend{NewMemoryBackend()}
+	c.SetPatch("15.1.1")
+
+	err := c.Save()
+	if err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("Save() error = %v, want the backend failure", err)
+	}
+	loaded := New(path, 3)
+	if err := loaded.Load(); err != nil || loaded.GetPatch() != "15.1.1" {
+		t.Errorf("cache file not written: patch %q, %v", loaded.GetPatch(), err)
+	}
+}
+
+func TestLoadMigratesLegacyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	legacy := `{"patch":"14.9.1","champion_map":{"Ahri":"Ahri"},"champion_key_map":{"103":"Ahri"},"champions":null}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	c := New(path, 3)
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if c.GetPatch() != "14.9.1" || c.GetChampionMapLen() != 1 || c.GetChampionKeyMap()["103"] != "Ahri" {
+		t.Errorf("legacy data not loaded: patch %q, map %v", c.GetPatch(), c.GetChampionMap())
+	}
+	// A null champions map must not replace the initialized one.
+	if c.Champions == nil {
+		t.Error("Champions map should remain non-nil")
+	}
+}
+
+// TestLoadQuarantinesCorruptFile checks a corrupt file is moved aside rather than deleted.
+func TestLoadQuarantinesCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.json")
+	if err := os.WriteFile(path, []byte(`{"patch":"15.1`), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	c := New(path, 3)
+	if err := c.Load(); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("corrupt file should be moved away, stat err: %v", err)
+	}
+	matches, _ := filepath.Glob(path + ".corrupt-*")
+	if len(matches) != 1 {
+		t.Fatalf("expected one quarantined file, got %v", matches)
+	}
+	raw, _ := os.ReadFile(matches[0])
+	if !strings.HasPrefix(string(raw), `{"patch"`) {
+		t.Errorf("quarantined file should keep the original bytes, got %q", raw)
+	}
+}
+
+// TestLoadRejectsNewerVersion leaves files from a newer schema in place.
+func TestLoadRejectsNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	if err := os.WriteFile(path, []byte(`{"version":99,"patch":"99.1.1"}`), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	c := New(path, 3)
+	err := c.Load()
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("Load() error = %v, want ErrUnsupportedVersion", err)
+	}
+	if c.GetPatch() != "" {
+		t.Errorf("patch should not be loaded, got %q", c.GetPatch())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("newer file should be left in place: %v", err)
+	}
+}
+
+func TestDecodeCacheVersions(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		wantVersion int
+		wantErr     bool
+	}{
+		{"legacy", `{"patch":"1"}`, 1, false},
+		{"current", `{"version":2,"patch":"1"}`, 2, false},
+		{"zero version", `{"version":0}`, 0, true},
+		{"non-numeric version", `{"version":"two"}`, 0, true},
+		{"null document", `null`, 0, true},
+		{"array document", `[]`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			persist, from, err := decodeCache([]byte(tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeCache error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if from != tt.wantVersion {
+				t.Errorf("from version: got %d, want %d", from, tt.wantVersion)
+			}
+			if persist.Version != SchemaVersion {
+				t.Errorf("migrated version: got %d, want %d", persist.Version, SchemaVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/fileutil"
+)
+
+// DefaultMaxPlayers is the number of Riot IDs a Players index keeps unless
+// told otherwise.
+const DefaultMaxPlayers = 50000
+
+// SeenPlayer is a Riot ID met in an account lookup, a match or a live game.
+type SeenPlayer struct {
+	PUUID    string    `json:"puuid"`
+	GameName string    `json:"game_name"`
+	TagLine  string    `json:"tag_line"`
+	Region   string    `json:"region"` // platform the player was seen on, e.g. euw1
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// RiotID returns the player's Riot ID as "name#tag".
+func (p SeenPlayer) RiotID() string {
+	return p.GameName + "#" + p.TagLine
+}
+
+// playerRef is an entry of Players.sorted.
+type playerRef struct {
+	key   string // playerKey of the Riot ID
+	puuid string
+}
+
+// Players is a local index of every Riot ID the server has come across, for
+// suggesting players as they are typed. Each PUUID is kept once under its
+// latest Riot ID. When the index is full, the players seen longest ago are
+// dropped. The methods are safe for concurrent use and on a nil *Players,
+// which records nothing.
+type Players struct {
+	Path   string      // file for Load and Save; empty keeps the index in memory
+	Max    int         // capacity; zero or less means DefaultMaxPlayers
+	Logger *log.Logger // optional
+
+	mu     sync.RWMutex
+	byID   map[string]SeenPlayer // by PUUID
+	sorted []playerRef           // by key, then PUUID
+	dirty  bool                  // changed since the last Save
+	now    func() time.Time
+}
+
+// NewPlayers creates an empty index stored at path.
+func NewPlayers(path string, max int) *Players {
+	return &Players{Path: path, Max: max, byID: make(map[string]SeenPlayer), now: time.Now}
+}
+
+// playerKey normalizes a Riot ID for matching: both parts are lowercased and
+// stripped of anything but letters and digits.
+func playerKey(gameName, tagLine string) string {
+	return preprocessString(gameName) + "#" + preprocessString(tagLine)
+}
+
+// Len returns the number of players in the index.
+func (p *Players) Len() int {
+	if p == nil {
+		return 0
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.byID)
+}
+
+// Observe records players seen just now on region. Entries without a PUUID
+// or a complete Riot ID are ignored.
+func (p *Players) Observe(region string, players ...SeenPlayer) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now().UTC()
+	for _, sp := range players {
+		if sp.PUUID == "" || sp.GameName == "" || sp.TagLine == "" {
+			continue
+		}
+		sp.Region = region
+		sp.LastSeen = now
+		p.put(sp)
+	}
+	p.evict()
+}
+
+// put adds or updates sp, moving it in the sorted list if its Riot ID
+// changed. p.mu must be held for writing.
+func (p *Players) put(sp SeenPlayer) {
+	key := playerKey(sp.GameName, sp.TagLine)
+	if old, ok := p.byID[sp.PUUID]; ok {
+		oldKey := playerKey(old.GameName, old.TagLine)
+		if oldKey != key {
+			p.unlink(playerRef{key: oldKey, puuid: sp.PUUID})
+			p.link(playerRef{key: key, puuid: sp.PUUID})
+		}
+	} else {
+		p.link(playerRef{key: key, puuid: sp.PUUID})
+	}
+	p.byID[sp.PUUID] = sp
+	p.dirty = true
+}
+
+// search returns the position of ref in p.sorted, or where it would go.
+func (p *Players) search(ref playerRef) int {
+	return sort.Search(len(p.sorted), func(i int) bool {
+		s := p.sorted[i]
+		return s.key > ref.key || (s.key == ref.key && s.puuid >= ref.puuid)
+	})
+}
+
+func (p *Players) link(ref playerRef) {
+	i := p.search(ref)
+	p.sorted = append(p.sorted, playerRef{})
+	copy(p.sorted[i+1:], p.sorted[i:])
+	p.sorted[i] = ref
+}
+
+func (p *Players) unlink(ref playerRef) {
+	if i := p.search(ref); i < len(p.sorted) && p.sorted[i] == ref {
+		p.sorted = append(p.sorted[:i], p.sorted[i+1:]...)
+	}
+}
+
+// evict drops the players seen longest ago once the index is over capacity,
+// down to nine tenths of it so that eviction does not run on every call.
+// p.mu must be held for writing.
+func (p *Players) evict() {
+	max := p.Max
+	if max <= 0 {
+		max = DefaultMaxPlayers
+	}
+	if len(p.byID) <= max {
+		return
+	}
+	all := make([]SeenPlayer, 0, len(p.byID))
+	for _, sp := range p.byID {
+		all = append(all, sp)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if !all[i].LastSeen.Equal(all[j].LastSeen) {
+			return all[i].LastSeen.Before(all[j].LastSeen)
+		}
+		return all[i].PUUID < all[j].PUUID
+	})
+	for _, sp := range all[:len(all)-max*9/10] {
+		delete(p.byID, sp.PUUID)
+	}
+	p.sorted = p.sorted[:0]
+	for id, sp := range p.byID {
+		p.sorted = append(p.sorted, playerRef{key: playerKey(sp.GameName, sp.TagLine), puuid: id})
+	}
+	sort.Slice(p.sorted, func(i, j int) bool {
+		if p.sorted[i].key != p.sorted[j].key {
+			return p.sorted[i].key < p.sorted[j].key
+		}
+		return p.sorted[i].puuid < p.sorted[j].puuid
+	})
+}
+
+// Search returns up to limit players whose Riot ID starts with input, most
+// recently seen first. Input without "#" matches the name; with it, the tag
+// must also start with what follows. Case, spaces and punctuation are
+// ignored.
+func (p *Players) Search(input string, limit int) []SeenPlayer {
+	if p == nil {
+		return nil
+	}
+	name, tag, hasTag := strings.Cut(input, "#")
+	prefix := preprocessString(name)
+	if prefix == "" {
+		return nil
+	}
+	if hasTag {
+		prefix += "#" + preprocessString(tag)
+	}
+
+	p.mu.RLock()
+	var out []SeenPlayer
+	for i := p.search(playerRef{key: prefix}); i < len(p.sorted) && strings.HasPrefix(p.sorted[i].key, prefix); i++ {
+		out = append(out, p.byID[p.sorted[i].puuid])
+	}
+	p.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].LastSeen.Equal(out[j].LastSeen) {
+			return out[i].LastSeen.After(out[j].LastSeen)
+		}
+		return out[i].PUUID < out[j].PUUID
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// persistedPlayers is the on-disk form of a Players index.
+type persistedPlayers struct {
+	SavedAt time.Time    `json:"saved_at"`
+	Players []SeenPlayer `json:"players"`
+}
+
+// Load replaces the index with the players saved at Path. A missing file
+// leaves the index empty.
+func (p *Players) Load() error {
+	if p == nil || p.Path == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(p.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading player index: %w", err)
+	}
+	var persisted persistedPlayers
+	if err := json.Unmarshal(raw, &persisted); err != nil {
+		return fmt.Errorf("decoding player index %s: %w", p.Path, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.byID = make(map[string]SeenPlayer, len(persisted.Players))
+	p.sorted = nil
+	for _, sp := range persisted.Players {
+		if sp.PUUID != "" {
+			p.put(sp)
+		}
+	}
+	p.evict()
+	p.dirty = false
+	if p.Logger != nil {
+		p.Logger.Info("Player index loaded", "players", len(p.byID))
+	}
+	return nil
+}
+
+// Save writes the index to Path atomically if it changed since it was
+// loaded or last saved.
+func (p *Players) Save() error {
+	if p == nil || p.Path == "" {
+		return nil
+	}
+	p.mu.Lock()
+	if !p.dirty {
+		p.mu.Unlock()
+		return nil
+	}
+	persisted := persistedPlayers{SavedAt: p.now().UTC(), Players: make([]SeenPlayer, 0, len(p.sorted))}
+	for _, ref := range p.sorted {
+		persisted.Players = append(persisted.Players, p.byID[ref.puuid])
+	}
+	p.dirty = false
+	p.mu.Unlock()
+
+	data, err := json.Marshal(&persisted)
+	if err == nil {
+		err = fileutil.WriteFileAtomic(p.Path, data, 0o644)
+	}
+	if err != nil {
+		p.mu.Lock()
+		p.dirty = true
+		p.mu.Unlock()
+		return fmt.Errorf("saving player index: %w", err)
+	}
+	return nil
+}
+
+// Autosave saves the index every interval while it keeps changing, until ctx
+// is done.
+func (p *Players) Autosave(ctx context.Context, interval time.Duration) {
+	if p == nil || p.Path == "" {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := p.Save(); err != nil && p.Logger != nil {
+				p.Logger.Warn("Player index not saved", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestPlayers returns an index whose clock advances a minute per call.
+func newTestPlayers(path string, max int) *Players {
+	p := NewPlayers(path, max)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return p
+}
+
+func riotIDs(players []SeenPlayer) []string {
+	var out []string
+	for _, p := range players {
+		out = append(out, p.RiotID())
+	}
+	return out
+}
+
+func TestPlayers_Search(t *testing.T) {
+	p := newTestPlayers("", 0)
+	p.Observe("euw1", SeenPlayer{PUUID: "1", GameName: "Faker", TagLine: "KR1"})
+	p.Observe("euw1", SeenPlayer{PUUID: "2", GameName: "Fake Name", TagLine: "EUW"})
+	p.Observe("na1", SeenPlayer{PUUID: "3", GameName: "Doublelift", TagLine: "NA1"})
+	p.Observe("euw1", SeenPlayer{PUUID: "4", GameName: "", TagLine: "EUW"}) // incomplete, ignored
+
+	for _, tc := range []struct {
+		input string
+		want  []string
+	}{
+		{"fake", []string{"Fake Name#EUW", "Faker#KR1"}}, // most recent first
+		{"FAKER", []string{"Faker#KR1"}},
+		{"fake name#e", []string{"Fake Name#EUW"}},
+		{"faker#euw", nil},
+		{"#kr1", nil},
+		{"zed", nil},
+	} {
+		if got := riotIDs(p.Search(tc.input, 10)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Search(%q) = %v, want %v", tc.input, got, tc.want)
+		}
+	}
+	if got := p.Search("fake", 1); len(got) != 1 {
+		t.Errorf("Search with limit 1 returned %d players", len(got))
+	}
+}
+
+func TestPlayers_RenameAndEvict(t *testing.T) {
+	p := newTestPlayers("", 10)
+	p.Observe("euw1", SeenPlayer{PUUID: "1", GameName: "Old Name", TagLine: "EUW"})
+	p.Observe("euw1", SeenPlayer{PUUID: "1", GameName: "New Name", TagLine: "EUW"})
+	if got := p.Search("old", 10); len(got) != 0 {
+		t.Errorf("old Riot ID still found: %v", riotIDs(got))
+	}
+	if got := riotIDs(p.Search("new", 10)); !reflect.DeepEqual(got, []string{"New Name#EUW"}) {
+		t.Errorf("Search(new) = %v", got)
+	}
+
+	for i := 0; i < 10; i++ {
+		p.Observe("euw1", SeenPlayer{PUUID: string(rune('a' + i)), GameName: "Player" + string(rune('a'+i)), TagLine: "EUW"})
+	}
+	// Eleven players exceed the capacity of ten; the two seen longest ago go.
+	if p.Len() != 9 {
+		t.Fatalf("Len() = %d after eviction, want 9", p.Len())
+	}
+	if got := p.Search("new", 10); len(got) != 0 {
+		t.Error("least recently seen player was not evicted")
+	}
+	if got := p.Search("playera", 10); len(got) != 0 {
+		t.Error("second least recently seen player was not evicted")
+	}
+	if got := p.Search("player", 20); len(got) != 9 {
+		t.Errorf("Search(player) found %d players, want 9", len(got))
+	}
+}
+
+func TestPlayers_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "players.json")
+	p := newTestPlayers(path, 0)
+	p.Observe("kr", SeenPlayer{PUUID: "1", GameName: "Faker", TagLine: "KR1"})
+	if err := p.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewPlayers(path, 0)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := loaded.Search("faker", 10), p.Search("faker", 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded %+v, want %+v", got, want)
+	}
+
+	// A missing file is an empty index, and a nil index records nothing.
+	if err := NewPlayers(filepath.Join(t.TempDir(), "none.json"), 0).Load(); err != nil {
+		t.Errorf("Load() of a missing file: %v", err)
+	}
+	var none *Players
+	none.Observe("euw1", SeenPlayer{PUUID: "1", GameName: "A", TagLine: "B"})
+	if none.Search("a", 10) != nil || none.Len() != 0 || none.Save() != nil {
+		t.Error("nil *Players should be inert")
+	}
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisBackend stores cache data in Redis (or any server speaking the Redis
+// protocol) so several instances share one copy. Keys are namespaced by prefix:
+//
+//	<prefix>:snapshot   JSON-encoded Snapshot
+//	<prefix>:champions  hash of champion key -> JSON-encoded models.Champion
+//	<prefix>:kv:<key>   raw KV values with their TTL
+type RedisBackend struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisBackend wraps an existing client. The backend owns the client and
+// closes it in Close.
+func NewRedisBackend(client *redis.Client, prefix string) *RedisBackend {
+	if prefix == "" {
+		prefix = "lolmatchup"
+	}
+	return &RedisBackend{client: client, prefix: prefix}
+}
+
+func (r *RedisBackend) key(parts ...string) string {
+	k := r.prefix
+	for _, p := range parts {
+		k += ":" + p
+	}
+	return k
+}
+
+func (r *RedisBackend) LoadSnapshot(ctx context.Context) (Snapshot, error) {
+	var s Snapshot
+	raw, err := r.client.Get(ctx, r.key("snapshot")).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("redis: loading snapshot: %w", err)
+	}
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return s, fmt.Errorf("redis: decoding snapshot: %w", err)
+	}
+	return s, nil
+}
+
+func (r *RedisBackend) SaveSnapshot(ctx context.Context, s Snapshot) error {
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("redis: encoding snapshot: %w", err)
+	}
+	if err := r.client.Set(ctx, r.key("snapshot"), raw, 0).Err(); err != nil {
+		return fmt.Errorf("redis: saving snapshot: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisBackend) GetChampion(ctx context.Context, key string) (models.Champion, bool, error) {
+	var champ models.Champion
+	raw, err := r.client.HGet(ctx, r.key("champions"), key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return champ, false, nil
+	}
+	if err != nil {
+		return champ, false, fmt.Errorf("redis: loading champion %s: %w", key, err)
+	}
+	if err := json.Unmarshal(raw, &champ); err != nil {
+		return champ, false, fmt.Errorf("redis: decoding champion %s: %w", key, err)
+	}
+	return champ, true, nil
+}
+
+func (r *RedisBackend) SetChampion(ctx context.Context, champion models.Champion) error {
+	raw, err := json.Marshal(champion)
+	if err != nil {
+		return fmt.Errorf("redis: encoding champion %s: %w", champion.Key, err)
+	}
+	if err := r.client.HSet(ctx, r.key("champions"), champion.Key, raw).Err(); err != nil {
+		return fmt.Errorf("redis: saving champion %s: %w", champion.Key, err)
+	}
+	return nil
+}
+
+func (r *RedisBackend) ClearChampions(ctx context.Context) error {
+	if err := r.client.Del(ctx, r.key("champions")).Err(); err != nil {
+		return fmt.Errorf("redis: clearing champions: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	raw, err := r.client.Get(ctx, r.key("kv", key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("redis: get %s: %w", key, err)
+	}
+	return raw, true, nil
+}
+
+func (r *RedisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	if err := r.client.Set(ctx, r.key("kv", key), value, ttl).Err(); err != nil {
+		return fmt.Errorf("redis: set %s: %w", key, err)
+	}
+	return nil
+}
+
+func (r *RedisBackend) Delete(ctx context.Context, key string) error {
+	if err := r.client.Del(ctx, r.key("kv", key)).Err(); err != nil {
+		return fmt.Errorf("redis: delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (r *RedisBackend) Close() error {
+	return r.client.Close()
+}
@@ -18,40 +18,35 @@ func preprocessString(s string) string {
 	return builder.String()
 }
 
-// levenshteinDistance implements the standard Levenshtein distance algorithm.
+// levenshteinDistance implements the standard Levenshtein distance algorithm,
+// keeping only two rows of the matrix.
 func levenshteinDistance(a, b string) int {
-	la := len(a)
-	lb := len(b)
-	if la == 0 {
-		return lb
+	if len(a) < len(b) {
+		a, b = b, a
 	}
-	if lb == 0 {
-		return la
+	if len(b) == 0 {
+		return len(a)
 	}
 
-	dp := make([][]int, la+1)
-	for i := range dp {
-		dp[i] = make([]int, lb+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
 	}
-	for i := 0; i <= la; i++ {
-		dp[i][0] = i
-	}
-	for j := 0; j <= lb; j++ {
-		dp[0][j] = j
-	}
-
-	for i := 1; i <= la; i++ {
-		for j := 1; j <= lb; j++ {
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
 			cost := 0
 			if a[i-1] != b[j-1] {
 				cost = 1
 			}
-			dp[i][j] = min(
-				dp[i-1][j]+1,      // deletion
-				dp[i][j-1]+1,      // insertion
-				dp[i-1][j-1]+cost, // substitution
+			curr[j] = min(
+				prev[j]+1,      // deletion
+				curr[j-1]+1,    // insertion
+				prev[j-1]+cost, // substitution
 			)
 		}
+		prev, curr = curr, prev
 	}
-	return dp[la][lb]
+	return prev[len(b)]
 }
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// cacheReport describes the state of the local champion cache.
+type cacheReport struct {
+	Path           string    `json:"path"`
+	Exists         bool      `json:"exists"`
+	SizeBytes      int64     `json:"sizeBytes,omitempty"`
+	ModifiedAt     time.Time `json:"modifiedAt,omitzero"`
+	Patch          string    `json:"patch"`
+	ChampionNames  int       `json:"championNames"`
+	ChampionKeys   int       `json:"championKeys"`
+	ChampionsData  int       `json:"championsWithDetails"`
+	SummonerSpells int       `json:"summonerSpells"`
+}
+
+// runCache dispatches the cache maintenance subcommands.
+func runCache(ctx context.Context, e *env, args []string) error {
+	var cf commonFlags
+	fs := newFlagSet(e, "cache", &cf)
+	rest, err := parseArgs(fs, &cf, args, 1)
+	if err != nil {
+		return err
+	}
+	switch rest[0] {
+	case "inspect", "refresh", "clear":
+	default:
+		fs.Usage()
+		return errUsage
+	}
+
+	a, err := loadApp(&cf, false)
+	if err != nil {
+		return err
+	}
+	cfg := a.cfg
+
+	switch rest[0] {
+	case "refresh":
+		// Drop the cached data (including any shared backend copy) so
+		// Initialize treats it as stale and refetches the champion list and
+		// summoner spells.
+		cfg.Cache.Invalidate()
+		cfg.Cache.SetPatch("")
+		if err := a.loader.Initialize(ctx); err != nil {
+			return fmt.Errorf("refreshing cache: %w", err)
+		}
+		if patch := cfg.SnapshotPatch(); patch != "" {
+			return fmt.Errorf("refreshing cache: upstream unreachable (only the bundled %s snapshot is available)", patch)
+		}
+		if err := a.loader.SaveCache(); err != nil {
+			return fmt.Errorf("saving cache: %w", err)
+		}
+	case "clear":
+		if err := os.Remove(cfg.Cache.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing cache file: %w", err)
+		}
+		cfg.Cache.Invalidate()
+		cfg.Cache.SetPatch("")
+	}
+
+	report := inspectCache(a)
+	if cf.output == "json" {
+		return writeJSON(e.stdout, report)
+	}
+	return printCache(e, rest[0], report)
+}
+
+// inspectCache collects the cache report from memory and the file on disk.
+func inspectCache(a *app) cacheReport {
+	c := a.cfg.Cache
+	r := cacheReport{
+		Path:           c.Path,
+		Patch:          c.GetPatch(),
+		ChampionNames:  c.GetChampionMapLen(),
+		ChampionKeys:   len(c.GetChampionKeyMap()),
+		ChampionsData:  c.GetChampionsLen(),
+		SummonerSpells: c.GetSummonerSpellsLen(),
+	}
+	if info, err := os.Stat(c.Path); err == nil {
+		r.Exists = true
+		r.SizeBytes = info.Size()
+		r.ModifiedAt = info.ModTime()
+	}
+	return r
+}
+
+// printCache writes the cache report as a human-readable table.
+func printCache(e *env, action string, r cacheReport) error {
+	switch action {
+	case "refresh":
+		fmt.Fprintf(e.stdout, "Cache refreshed to patch %s\n\n", r.Patch)
+	case "clear":
+		fmt.Fprintf(e.stdout, "Cache cleared\n\n")
+	}
+
+	t := newTable(e.stdout)
+	t.row("Path", r.Path)
+	if r.Exists {
+		t.row("File", fmt.Sprintf("%d bytes, modified %s", r.SizeBytes, r.ModifiedAt.Format(time.RFC3339)))
+	} else {
+		t.row("File", "not present")
+	}
+	t.row("Patch", orDash(r.Patch))
+	t.row("Champion names", r.ChampionNames)
+	t.row("Champion keys", r.ChampionKeys)
+	t.row("Champions with details", r.ChampionsData)
+	t.row("Summoner spells", r.SummonerSpells)
+	return t.flush()
+}
@@ -0,0 +1,285 @@
+// Package cli implements the lolmatchup command-line interface: the web server
+// plus lookup and maintenance subcommands that share the client, cache and data
+// packages with it.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/client"
+	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/data"
+)
+
+const defaultConfigPath = "config.toml"
+
+// configPathEnv names the environment variable that selects the config file
+// when -config is not given.
+const configPathEnv = config.EnvPrefix + "CONFIG"
+
+// errUsage signals that a command was invoked with bad arguments; the usage
+// text has already been printed.
+var errUsage = errors.New("invalid usage")
+
+// command is a single CLI subcommand.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, env *env, args []string) error
+}
+
+// env carries the output streams shared by every command.
+type env struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// commands is populated in init to break the initialization cycle between the
+// command table and the per-command usage text built from it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "serve [flags]", "Run the web server (default when no command is given)", runServe},
+		{"player", "player [flags] <nickname#tag>", "Look up a player's account, rank and recent matches", runPlayer},
+		{"livegame", "livegame [flags] <nickname#tag>", "Show the live game a player is currently in", runLiveGame},
+		{"champion", "champion [flags] <name>", "Look up champion details", runChampion},
+		{"cache", "cache [flags] refresh|inspect|clear", "Maintain the local champion cache", runCache},
+		{"user", "user [flags] list|add|remove|token|revoke [name] [token-id]", "Manage accounts and API tokens for auth_mode accounts", runUser},
+	}
+}
+
+// Run executes the command named by args[0] and returns the process exit code.
+// With no arguments (or only flags) it starts the web server.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	e := &env{stdout: stdout, stderr: stderr}
+
+	name := "serve"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(ctx, e, args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			if !errors.Is(err, errUsage) {
+				fmt.Fprintf(stderr, "Error: %v\n", err)
+			}
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "Unknown command %q\n\n", name)
+	printUsage(stderr)
+	return 2
+}
+
+// printUsage writes the top-level help text.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: lolmatchup <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'lolmatchup <command> -h' for command flags.")
+}
+
+// commonFlags are accepted by every subcommand.
+type commonFlags struct {
+	configPath string
+	output     string
+	verbose    bool
+	overrides  *config.Overrides // per-setting flags, applied over file and environment
+}
+
+// newFlagSet creates a FlagSet for cmd with the common flags registered.
+func newFlagSet(e *env, cmd string, cf *commonFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.StringVar(&cf.configPath, "config", defaultConfigPath, "path to the TOML config file (env "+configPathEnv+")")
+	fs.StringVar(&cf.output, "o", "table", "output format: table or json")
+	fs.BoolVar(&cf.verbose, "v", false, "log debug output to stderr")
+	cf.overrides = config.BindFlags(fs)
+	for _, c := range commands {
+		if c.name == cmd {
+			fs.Usage = func() {
+				fmt.Fprintf(e.stderr, "Usage: lolmatchup %s\n\n%s\n\nFlags:\n", c.usage, c.summary)
+				printCommandFlags(fs)
+				fmt.Fprintf(e.stderr, "\nAny config.toml key can also be given as a flag (e.g. -riot_region=euw1)\n"+
+					"or as %s<KEY> in the environment (e.g. %s). Flags override the\n"+
+					"environment, which overrides the config file.\n", config.EnvPrefix, config.EnvName("riot_region"))
+			}
+		}
+	}
+	return fs
+}
+
+// printCommandFlags prints fs's defaults like PrintDefaults, leaving out the
+// per-setting config flags, which are described by a note instead.
+func printCommandFlags(fs *flag.FlagSet) {
+	full := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	full.SetOutput(fs.Output())
+	fs.VisitAll(func(f *flag.Flag) {
+		if !config.IsConfigFlag(f.Name) {
+			full.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	full.PrintDefaults()
+}
+
+// parseArgs parses flags and checks that exactly want positional arguments remain.
+func parseArgs(fs *flag.FlagSet, cf *commonFlags, args []string, want int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, errUsage
+	}
+	if cf.output != "table" && cf.output != "json" {
+		fmt.Fprintf(fs.Output(), "invalid output format %q: use table or json\n", cf.output)
+		return nil, errUsage
+	}
+	if fs.NArg() != want {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+// app bundles the objects every command needs once configuration is loaded.
+type app struct {
+	cfg    *config.AppConfig
+	client *client.Client
+	loader *data.DataLoader
+}
+
+// loadApp builds the configuration from defaults, the config file (if
+// present), LOLMATCHUP_* environment variables and per-setting flags, in that
+// order, then initializes logger, cache and HTTP client, and loads the
+// persisted cache from disk. Unless serving, logging is kept to warnings so
+// command output stays readable.
+func loadApp(cf *commonFlags, serving bool) (*app, error) {
+	cfg, err := buildConfig(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Initialize(); err != nil {
+		return nil, fmt.Errorf("error initializing AppConfig: %w", err)
+	}
+	if !serving {
+		if cf.verbose {
+			cfg.Logger.SetLevel(log.DebugLevel)
+		} else {
+			cfg.Logger.SetLevel(log.WarnLevel)
+		}
+	}
+
+	if err := cfg.Cache.Load(); err != nil {
+		cfg.Logger.Warnf("Cache not loaded (possibly first run): %v", err)
+	}
+	if err := cfg.Players.Load(); err != nil {
+		cfg.Logger.Warnf("Player index not loaded: %v", err)
+	}
+	if err := cfg.Matches.Load(); err != nil {
+		cfg.Logger.Warnf("Match store not loaded: %v", err)
+	}
+
+	// Reloading (on SIGHUP or a key file change) rebuilds the configuration
+	// from the same layers, so the key can come from any of them.
+	keys := client.NewKeyProvider(cfg.RiotAPIKey, func() (string, error) {
+		fresh, err := buildConfig(cf)
+		if err != nil {
+			return "", err
+		}
+		return fresh.RiotAPIKey, nil
+	})
+	keys.Logger = cfg.Logger
+
+	apiClient := &client.Client{
+		HTTPClient:        cfg.HTTPClient,
+		Logger:            cfg.Logger,
+		ChampionDataURL:   cfg.MerakiURL,
+		DDragonVersionURL: cfg.DDragonVersionURL,
+		RiotAPIBaseURL:    cfg.RiotAPIBaseURL,
+		Responses: &client.ResponseCache{
+			KV: cfg.Cache.KV(),
+			TTLs: client.ResponseTTLs{
+				Account:              seconds(cfg.AccountCacheSeconds),
+				Summoner:             seconds(cfg.SummonerCacheSeconds),
+				League:               seconds(cfg.LeagueCacheSeconds),
+				MatchIDs:             seconds(cfg.MatchIDsCacheSeconds),
+				StaleWhileRevalidate: seconds(cfg.StaleCacheSeconds),
+			},
+		},
+		Retry: client.RetryPolicy{
+			MaxAttempts: cfg.RetryAttempts,
+			BaseDelay:   time.Duration(cfg.RetryBaseMillis) * time.Millisecond,
+			MaxDelay:    time.Duration(cfg.RetryMaxMillis) * time.Millisecond,
+		},
+		Breakers: client.NewBreakers(cfg.BreakerThreshold, seconds(cfg.BreakerOpenSeconds)),
+		Keys:     keys,
+		Players:  cfg.Players,
+		Matches:  cfg.Matches,
+	}
+
+	return &app{
+		cfg:    cfg,
+		client: apiClient,
+		loader: data.NewDataLoader(cfg, apiClient, cfg.Cache),
+	}, nil
+}
+
+// buildConfig layers defaults, the config file, the environment and flags,
+// and resolves secret files. It does not initialize the result.
+func buildConfig(cf *commonFlags) (*config.AppConfig, error) {
+	cfg := config.New()
+
+	configPath := cf.configPath
+	if env, ok := os.LookupEnv(configPathEnv); ok && configPath == defaultConfigPath {
+		configPath = env
+	}
+	if _, err := os.Stat(configPath); err == nil {
+		if err := cfg.Load(configPath); err != nil {
+			return nil, err
+		}
+	} else if configPath != defaultConfigPath {
+		return nil, fmt.Errorf("config file %s: %w", configPath, err)
+	}
+	if err := cfg.LoadEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := cf.overrides.Apply(cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.ResolveSecrets(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// seconds converts a config value in seconds to a time.Duration.
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
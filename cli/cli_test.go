@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klnstprx/lolMatchup/auth"
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+// newFakeAPI returns a test server answering the Riot and Meraki endpoints the
+// CLI uses, keyed by URL path substring.
+func newFakeAPI(t *testing.T, routes map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for pattern, body := range routes {
+			if strings.Contains(r.URL.Path, pattern) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, body)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// writeTestConfig writes a config file pointing all upstreams at srv.
+func writeTestConfig(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	content := fmt.Sprintf(`debug = false
+riot_api_key = "test-key"
+riot_region = "na1"
+riot_api_base_url = %q
+meraki_url = %q
+ddragon_version_url = %q
+cache_path = %q
+`, srv.URL, srv.URL+"/meraki/", srv.URL+"/versions.json", filepath.Join(dir, "cache.json"))
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	return path
+}
+
+func run(t *testing.T, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	code = Run(context.Background(), args, &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+const (
+	acctJSON     = `{"puuid":"p1","gameName":"Tester","tagLine":"NA1"}`
+	summonerJSON = `{"puuid":"p1","profileIconId":1,"summonerLevel":42}`
+	leagueJSON   = `[{"queueType":"RANKED_SOLO_5x5","tier":"GOLD","rank":"II","leaguePoints":50,"wins":10,"losses":8}]`
+	matchIDsJSON = `["NA1_1"]`
+	matchJSON    = `{"metadata":{"matchId":"NA1_1"},"info":{"gameDuration":1800,"participants":[{"puuid":"p1","championName":"Ahri","win":true,"kills":5,"deaths":2,"assists":7,"totalMinionsKilled":180,"individualPosition":"MIDDLE"}]}}`
+)
+
+func TestRun_UnknownCommand(t *testing.T) {
+	code, _, stderr := run(t, "bogus")
+	if code != 2 {
+		t.Errorf("exit code: got %d, want 2", code)
+	}
+	if !strings.Contains(stderr, "Unknown command") {
+		t.Errorf("stderr should mention unknown command, got %q", stderr)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"missing riot ID", []string{"player"}},
+		{"bad output format", []string{"player", "-o", "xml", "A#B"}},
+		{"unknown cache action", []string{"cache", "nuke"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, _ := run(t, tt.args...)
+			if code != 1 {
+				t.Errorf("exit code: got %d, want 1", code)
+			}
+		})
+	}
+}
+
+func TestPlayer_InvalidRiotID(t *testing.T) {
+	code, _, stderr := run(t, "player", "NoTag")
+	if code != 1 {
+		t.Errorf("exit code: got %d, want 1", code)
+	}
+	if !strings.Contains(stderr, "nickname#tag") {
+		t.Errorf("stderr should explain the format, got %q", stderr)
+	}
+}
+
+func TestPlayer_JSON(t *testing.T) {
+	srv := newFakeAPI(t, map[string]string{
+		"/accounts/by-riot-id/": acctJSON,
+		"/summoners/by-puuid/":  summonerJSON,
+		"/entries/by-puuid/":    leagueJSON,
+		"/ids":                  matchIDsJSON,
+		"/matches/NA1_1":        matchJSON,
+	})
+	cfgPath := writeTestConfig(t, srv)
+
+	code, stdout, stderr := run(t, "player", "-config", cfgPath, "-o", "json", "Tester#NA1")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+
+	var report playerReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if report.RiotID != "Tester#NA1" || report.SummonerLevel != 42 {
+		t.Errorf("unexpected report header: %+v", report)
+	}
+	if len(report.Ranked) != 1 || report.Ranked[0].Tier != "GOLD" {
+		t.Errorf("unexpected ranked rows: %+v", report.Ranked)
+	}
+	if len(report.Matches) != 1 || report.Matches[0].Champion != "Ahri" || !report.Matches[0].Win {
+		t.Errorf("unexpected match rows: %+v", report.Matches)
+	}
+}
+
+func TestPlayer_Table(t *testing.T) {
+	srv := newFakeAPI(t, map[string]string{
+		"/accounts/by-riot-id/": acctJSON,
+		"/summoners/by-puuid/":  summonerJSON,
+		"/entries/by-puuid/":    `[]`,
+		"/ids":                  matchIDsJSON,
+		"/matches/NA1_1":        matchJSON,
+	})
+	cfgPath := writeTestConfig(t, srv)
+
+	code, stdout, stderr := run(t, "player", "-config", cfgPath, "Tester#NA1")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	for _, want := range []string{"Tester#NA1", "Unranked", "Ahri", "5/2/7", "30:00"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %q:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestLiveGame_NotInGame(t *testing.T) {
+	srv := newFakeAPI(t, map[string]string{
+		"/accounts/by-riot-id/": acctJSON,
+	})
+	cfgPath := writeTestConfig(t, srv)
+
+	code, _, stderr := run(t, "livegame", "-config", cfgPath, "Tester#NA1")
+	if code != 1 {
+		t.Errorf("exit code: got %d, want 1", code)
+	}
+	if !strings.Contains(stderr, "not currently in a game") {
+		t.Errorf("stderr: got %q", stderr)
+	}
+}
+
+func TestBuildLiveGameReport(t *testing.T) {
+	game := models.CurrentGameInfo{
+		GameID: 7,
+		Participants: []models.CurrentGameParticipant{
+			{ChampionID: 266, TeamID: 200, RiotID: "B#2", Spell1ID: 4, Spell2ID: 99},
+			{ChampionID: 103, TeamID: 100, RiotID: "A#1", Spell1ID: 4, Spell2ID: 14},
+		},
+		BannedChampions: []models.BannedChampion{
+			{ChampionID: -1, TeamID: 100},
+			{ChampionID: 266, TeamID: 100},
+		},
+	}
+	keyMap := map[string]string{"266": "Aatrox", "103": "Ahri"}
+	nameMap := map[string]string{"Aatrox": "Aatrox", "Ahri": "Ahri"}
+	spells := map[string]models.SummonerSpell{"4": {Name: "Flash"}, "14": {Name: "Ignite"}}
+
+	r := buildLiveGameReport(game, keyMap, nameMap, spells)
+
+	if len(r.Teams) != 2 || r.Teams[0].TeamID != 100 {
+		t.Fatalf("teams should be sorted by ID, got %+v", r.Teams)
+	}
+	if p := r.Teams[0].Participants[0]; p.Champion != "Ahri" || p.Spell1 != "Flash" || p.Spell2 != "Ignite" {
+		t.Errorf("unexpected participant: %+v", p)
+	}
+	if p := r.Teams[1].Participants[0]; p.Spell2 != "99" {
+		t.Errorf("unknown spell should fall back to its ID, got %q", p.Spell2)
+	}
+	if len(r.Bans) != 1 || r.Bans[0].Champion != "Aatrox" {
+		t.Errorf("unexpected bans: %+v", r.Bans)
+	}
+}
+
+func TestChampion_JSON(t *testing.T) {
+	srv := newFakeAPI(t, map[string]string{
+		"/versions.json":          `["15.1.1"]`,
+		"/meraki/champions.json":  `{"Ahri":{"id":103,"key":"Ahri","name":"Ahri","title":"the Nine-Tailed Fox"}}`,
+		"/meraki/champions/Ahri":  `{"id":103,"key":"Ahri","name":"Ahri","title":"the Nine-Tailed Fox"}`,
+		"/data/en_US/summoner.js": `{"data":{}}`,
+	})
+	cfgPath := writeTestConfig(t, srv)
+
+	code, stdout, stderr := run(t, "champion", "-config", cfgPath, "-o", "json", "ahr")
+	if code != 0 {
+		t.Fatalf("exit code %d, stderr: %s", code, stderr)
+	}
+	var champ models.Champion
+	if err := json.Unmarshal([]byte(stdout), &champ); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if champ.Title != "the Nine-Tailed Fox" {
+		t.Errorf("Title: got %q", champ.Title)
+	}
+}
+
+func TestCache_InspectAndClear(t *testing.T) {
+	srv := newFakeAPI(t, nil)
+	cfgPath := writeTestConfig(t, srv)
+	cachePath := filepath.Join(filepath.Dir(cfgPath), "cache.json")
+	if err := os.WriteFile(cachePath, []byte(`{"patch":"15.1.1","champion_map":{"Ahri":"Ahri"}}`), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	code, stdout, stderr := run(t, "cache", "-config", cfgPath, "-o", "json", "inspect")
+	if code != 0 {
+		t.Fatalf("inspect exit code %d, stderr: %s", code, stderr)
+	}
+	var report cacheReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if !report.Exists || report.Patch != "15.1.1" || report.ChampionNames != 1 {
+		t.Errorf("unexpected inspect report: %+v", report)
+	}
+
+	code, stdout, stderr = run(t, "cache", "-config", cfgPath, "clear")
+	if code != 0 {
+		t.Fatalf("clear exit code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Cache cleared") || !strings.Contains(stdout, "not present") {
+		t.Errorf("unexpected clear output:\n%s", stdout)
+	}
+	if _, err := os.Stat(cachePath); !os.IsNotExist(err) {
+		t.Errorf("cache file should be removed, stat err: %v", err)
+	}
+}
+
+func TestUser_Commands(t *testing.T) {
+	usersFile := filepath.Join(t.TempDir(), "users.json")
+	stdin = strings.NewReader("correct horse\n")
+	t.Cleanup(func() { stdin = os.Stdin })
+
+	code, stdout, stderr := run(t, "user", "-users_file", usersFile, "-role", "admin", "add", "alice")
+	if code != 0 || !strings.Contains(stdout, "Added alice (admin)") {
+		t.Fatalf("add: exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+	store, err := auth.OpenStore(usersFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := store.Authenticate("alice", "correct horse"); !ok || !p.IsAdmin() {
+		t.Errorf("added user does not authenticate: %+v", p)
+	}
+
+	code, stdout, stderr = run(t, "user", "-users_file", usersFile, "-label", "ci", "token", "alice")
+	if code != 0 || !strings.Contains(stderr, "will not be shown again") {
+		t.Fatalf("token: exit %d, stderr %q", code, stderr)
+	}
+	if store, err = auth.OpenStore(usersFile); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := store.AuthenticateToken(strings.TrimSpace(stdout)); !ok || p.Name != "alice" {
+		t.Errorf("printed token does not authenticate: %q", stdout)
+	}
+
+	code, stdout, _ = run(t, "user", "-users_file", usersFile, "-o", "json", "list")
+	var users []auth.User
+	if err := json.Unmarshal([]byte(stdout), &users); code != 0 || err != nil {
+		t.Fatalf("list: exit %d, %v", code, err)
+	}
+	if len(users) != 1 || len(users[0].Tokens) != 1 || users[0].Tokens[0].Label != "ci" {
+		t.Errorf("unexpected users: %+v", users)
+	}
+
+	if code, _, _ := run(t, "user", "-users_file", usersFile, "revoke", "alice"); code != 1 {
+		t.Errorf("revoke without a token ID: exit %d, want 1", code)
+	}
+	if code, _, stderr := run(t, "user", "-users_file", usersFile, "remove", "bob"); code != 1 || !strings.Contains(stderr, "user not found") {
+		t.Errorf("removing an unknown user: exit %d, stderr %q", code, stderr)
+	}
+}
+
+func TestLoadApp_Layering(t *testing.T) {
+	srv := newFakeAPI(t, nil)
+	cfgPath := writeTestConfig(t, srv)
+	keyPath := filepath.Join(t.TempDir(), "riot_api_key")
+	if err := os.WriteFile(keyPath, []byte("RGAPI-file\n"), 0600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	t.Setenv("LOLMATCHUP_CONFIG", cfgPath)
+	t.Setenv("LOLMATCHUP_RIOT_REGION", "euw1")
+	t.Setenv("LOLMATCHUP_PORT", "8080")
+	t.Setenv("LOLMATCHUP_RIOT_API_KEY_FILE", keyPath)
+
+	var cf commonFlags
+	fs := newFlagSet(&env{stdout: io.Discard, stderr: io.Discard}, "serve", &cf)
+	if err := fs.Parse([]string{"-port", "9000"}); err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	a, err := loadApp(&cf, false)
+	if err != nil {
+		t.Fatalf("loadApp() error: %v", err)
+	}
+	cfg := a.cfg
+	if cfg.RiotAPIBaseURL != srv.URL {
+		t.Errorf("config file from LOLMATCHUP_CONFIG not loaded: base URL %q", cfg.RiotAPIBaseURL)
+	}
+	if cfg.RiotRegion != "euw1" {
+		t.Errorf("environment should override file: region %q", cfg.RiotRegion)
+	}
+	if cfg.Port != 9000 {
+		t.Errorf("flag should override environment: port %d", cfg.Port)
+	}
+	if cfg.RiotAPIKey != "RGAPI-file" {
+		t.Errorf("key file should replace file key: %q", cfg.RiotAPIKey)
+	}
+
+	if err := os.WriteFile(keyPath, []byte("RGAPI-rotated\n"), 0600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	if changed, err := a.client.Keys.Reload("test"); !changed || err != nil {
+		t.Fatalf("Reload() = %v, %v", changed, err)
+	}
+	if got := a.client.Keys.Key(); got != "RGAPI-rotated" {
+		t.Errorf("reloaded key: got %q, want RGAPI-rotated", got)
+	}
+}
+
+func TestLoadApp_InvalidEnv(t *testing.T) {
+	t.Setenv("LOLMATCHUP_CONFIG", writeTestConfig(t, newFakeAPI(t, nil)))
+	t.Setenv("LOLMATCHUP_PORT", "eighty")
+	code, _, stderr := run(t, "cache", "inspect")
+	if code != 1 || !strings.Contains(stderr, "LOLMATCHUP_PORT") {
+		t.Errorf("exit code %d, stderr: %s", code, stderr)
+	}
+}
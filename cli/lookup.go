@@ -0,0 +1,480 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klnstprx/lolMatchup/client"
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+// splitRiotID splits "nickname#tag" into its two halves.
+func splitRiotID(riotID string) (gameName, tagLine string, err error) {
+	parts := strings.SplitN(riotID, "#", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid Riot ID %q: use nickname#tag", riotID)
+	}
+	return parts[0], parts[1], nil
+}
+
+// playerReport is the output of the player command.
+type playerReport struct {
+	RiotID        string      `json:"riotId"`
+	PUUID         string      `json:"puuid"`
+	Region        string      `json:"region"`
+	SummonerLevel int64       `json:"summonerLevel"`
+	Ranked        []rankedRow `json:"ranked"`
+	Matches       []matchRow  `json:"matches"`
+	MatchErrors   int         `json:"matchErrors,omitempty"`
+}
+
+type rankedRow struct {
+	Queue  string `json:"queue"`
+	Tier   string `json:"tier"`
+	Rank   string `json:"rank"`
+	LP     int    `json:"leaguePoints"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+}
+
+type matchRow struct {
+	MatchID   string `json:"matchId"`
+	Champion  string `json:"champion"`
+	Position  string `json:"position"`
+	Win       bool   `json:"win"`
+	Kills     int    `json:"kills"`
+	Deaths    int    `json:"deaths"`
+	Assists   int    `json:"assists"`
+	CS        int    `json:"cs"`
+	Duration  int64  `json:"durationSeconds"`
+	StartTime int64  `json:"startTime"`
+}
+
+// runPlayer prints account, ranked and recent-match information for a Riot ID.
+func runPlayer(ctx context.Context, e *env, args []string) error {
+	var cf commonFlags
+	fs := newFlagSet(e, "player", &cf)
+	count := fs.Int("n", 10, "number of recent matches to fetch")
+	rest, err := parseArgs(fs, &cf, args, 1)
+	if err != nil {
+		return err
+	}
+	gameName, tagLine, err := splitRiotID(rest[0])
+	if err != nil {
+		return err
+	}
+
+	a, err := loadApp(&cf, false)
+	if err != nil {
+		return err
+	}
+	cfg := a.cfg
+
+	acct, err := a.client.FetchAccountByRiotID(ctx, gameName, tagLine, cfg.RiotRegion)
+	if err != nil {
+		return fmt.Errorf("fetching account: %w", err)
+	}
+	summoner, err := a.client.FetchSummonerByPUUID(ctx, acct.PUUID, cfg.RiotRegion)
+	if err != nil {
+		return fmt.Errorf("fetching summoner: %w", err)
+	}
+
+	report := playerReport{
+		RiotID:        acct.GameName + "#" + acct.TagLine,
+		PUUID:         acct.PUUID,
+		Region:        cfg.RiotRegion,
+		SummonerLevel: summoner.SummonerLevel,
+	}
+
+	entries, err := a.client.FetchLeagueEntries(ctx, acct.PUUID, cfg.RiotRegion)
+	if err != nil {
+		cfg.Logger.Warn("could not fetch league entries", "error", err)
+	}
+	for _, entry := range entries {
+		report.Ranked = append(report.Ranked, rankedRow{
+			Queue: entry.QueueType, Tier: entry.Tier, Rank: entry.Rank,
+			LP: entry.LeaguePoints, Wins: entry.Wins, Losses: entry.Losses,
+		})
+	}
+
+	if *count > 0 {
+		report.Matches, report.MatchErrors, err = fetchMatchRows(ctx, a, acct.PUUID, *count)
+		if err != nil {
+			cfg.Logger.Warn("could not fetch match history", "error", err)
+		}
+	}
+
+	if cf.output == "json" {
+		return writeJSON(e.stdout, report)
+	}
+	return printPlayer(e, report)
+}
+
+// fetchMatchRows fetches the player's most recent matches concurrently and
+// returns one row per match, in match-ID order, plus the number of failures.
+func fetchMatchRows(ctx context.Context, a *app, puuid string, count int) ([]matchRow, int, error) {
+	cfg := a.cfg
+	ids, err := a.client.FetchMatchIDs(ctx, puuid, cfg.RiotRegion, count, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows := make([]*matchRow, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(idx int, matchID string) {
+			defer wg.Done()
+			match, err := a.client.FetchMatch(ctx, matchID, cfg.RiotRegion)
+			if err != nil {
+				cfg.Logger.Debug("failed to fetch match", "matchId", matchID, "error", err)
+				return
+			}
+			for _, p := range match.Info.Participants {
+				if p.PUUID != puuid {
+					continue
+				}
+				rows[idx] = &matchRow{
+					MatchID:   matchID,
+					Champion:  p.ChampionName,
+					Position:  p.IndividualPosition,
+					Win:       p.Win,
+					Kills:     p.Kills,
+					Deaths:    p.Deaths,
+					Assists:   p.Assists,
+					CS:        p.TotalMinionsKilled + p.NeutralMinionsKilled,
+					Duration:  match.Info.GameDuration,
+					StartTime: match.Info.GameStartTimestamp,
+				}
+				break
+			}
+		}(i, id)
+	}
+	wg.Wait()
+
+	var out []matchRow
+	failed := 0
+	for _, r := range rows {
+		if r == nil {
+			failed++
+			continue
+		}
+		out = append(out, *r)
+	}
+	return out, failed, nil
+}
+
+// printPlayer writes the player report as human-readable tables.
+func printPlayer(e *env, r playerReport) error {
+	t := newTable(e.stdout)
+	t.row("Riot ID", r.RiotID)
+	t.row("Region", r.Region)
+	t.row("Level", r.SummonerLevel)
+	t.row("PUUID", r.PUUID)
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	section(e.stdout, "Ranked")
+	if len(r.Ranked) == 0 {
+		fmt.Fprintln(e.stdout, "Unranked")
+	} else {
+		t = newTable(e.stdout)
+		t.row("QUEUE", "TIER", "LP", "W", "L")
+		for _, rk := range r.Ranked {
+			t.row(rk.Queue, strings.TrimSpace(rk.Tier+" "+rk.Rank), rk.LP, rk.Wins, rk.Losses)
+		}
+		if err := t.flush(); err != nil {
+			return err
+		}
+	}
+
+	section(e.stdout, "Recent matches")
+	if len(r.Matches) == 0 {
+		fmt.Fprintln(e.stdout, "No matches found")
+	} else {
+		t = newTable(e.stdout)
+		t.row("MATCH", "CHAMPION", "ROLE", "RESULT", "KDA", "CS", "DURATION", "PLAYED")
+		for _, m := range r.Matches {
+			result := "Loss"
+			if m.Win {
+				result = "Win"
+			}
+			t.row(m.MatchID, m.Champion, orDash(m.Position), result,
+				fmt.Sprintf("%d/%d/%d", m.Kills, m.Deaths, m.Assists), m.CS,
+				formatDuration(m.Duration), formatEpochMs(m.StartTime))
+		}
+		if err := t.flush(); err != nil {
+			return err
+		}
+	}
+	if r.MatchErrors > 0 {
+		fmt.Fprintf(e.stdout, "(%d matches could not be retrieved)\n", r.MatchErrors)
+	}
+	return nil
+}
+
+// liveGameReport is the output of the livegame command.
+type liveGameReport struct {
+	GameID     int64         `json:"gameId"`
+	GameMode   string        `json:"gameMode"`
+	QueueID    int64         `json:"queueId"`
+	StartTime  int64         `json:"startTime"`
+	GameLength int64         `json:"gameLengthSeconds"`
+	Teams      []liveTeamRow `json:"teams"`
+	Bans       []liveBanRow  `json:"bans"`
+}
+
+type liveTeamRow struct {
+	TeamID       int64             `json:"teamId"`
+	Participants []liveParticipant `json:"participants"`
+}
+
+type liveParticipant struct {
+	RiotID   string `json:"riotId"`
+	Champion string `json:"champion"`
+	Spell1   string `json:"spell1"`
+	Spell2   string `json:"spell2"`
+	Bot      bool   `json:"bot,omitempty"`
+}
+
+type liveBanRow struct {
+	TeamID   int64  `json:"teamId"`
+	Champion string `json:"champion"`
+}
+
+// runLiveGame prints the teams, champions and bans of a player's live game.
+func runLiveGame(ctx context.Context, e *env, args []string) error {
+	var cf commonFlags
+	fs := newFlagSet(e, "livegame", &cf)
+	rest, err := parseArgs(fs, &cf, args, 1)
+	if err != nil {
+		return err
+	}
+	gameName, tagLine, err := splitRiotID(rest[0])
+	if err != nil {
+		return err
+	}
+
+	a, err := loadApp(&cf, false)
+	if err != nil {
+		return err
+	}
+	cfg := a.cfg
+
+	acct, err := a.client.FetchAccountByRiotID(ctx, gameName, tagLine, cfg.RiotRegion)
+	if err != nil {
+		return fmt.Errorf("fetching account: %w", err)
+	}
+	game, err := a.client.FetchCurrentGameByPUUID(ctx, acct.PUUID, cfg.RiotRegion)
+	if err != nil {
+		if errors.Is(err, client.ErrGameNotFound) {
+			return fmt.Errorf("%s#%s is not currently in a game", gameName, tagLine)
+		}
+		return fmt.Errorf("fetching live game: %w", err)
+	}
+
+	// Champion and spell names come from the static data cache.
+	if cfg.Cache.GetChampionMapLen() == 0 || cfg.Cache.GetSummonerSpellsLen() == 0 {
+		if err := a.loader.Initialize(ctx); err != nil {
+			cfg.Logger.Warn("could not load champion data; showing numeric IDs", "error", err)
+		}
+	}
+	report := buildLiveGameReport(game, cfg.Cache.GetChampionKeyMap(), cfg.Cache.GetChampionMap(), cfg.Cache.GetSummonerSpells())
+
+	if cf.output == "json" {
+		return writeJSON(e.stdout, report)
+	}
+	return printLiveGame(e, report)
+}
+
+// buildLiveGameReport resolves champion and spell IDs and groups participants by team.
+func buildLiveGameReport(game models.CurrentGameInfo, keyMap, nameMap map[string]string, spells map[string]models.SummonerSpell) liveGameReport {
+	idToName := make(map[string]string, len(nameMap))
+	for name, id := range nameMap {
+		idToName[id] = name
+	}
+	champion := func(id int64) string {
+		key := strconv.FormatInt(id, 10)
+		textID, ok := keyMap[key]
+		if !ok {
+			return key
+		}
+		if name, ok := idToName[textID]; ok {
+			return name
+		}
+		return textID
+	}
+	spell := func(id int64) string {
+		key := strconv.FormatInt(id, 10)
+		if s, ok := spells[key]; ok {
+			return s.Name
+		}
+		return key
+	}
+
+	report := liveGameReport{
+		GameID:     game.GameID,
+		GameMode:   game.GameMode,
+		QueueID:    game.GameQueueConfigID,
+		StartTime:  game.GameStartTime,
+		GameLength: game.GameLength,
+	}
+	teams := make(map[int64]*liveTeamRow)
+	for _, p := range game.Participants {
+		t, ok := teams[p.TeamID]
+		if !ok {
+			t = &liveTeamRow{TeamID: p.TeamID}
+			teams[p.TeamID] = t
+		}
+		t.Participants = append(t.Participants, liveParticipant{
+			RiotID:   p.RiotID,
+			Champion: champion(p.ChampionID),
+			Spell1:   spell(p.Spell1ID),
+			Spell2:   spell(p.Spell2ID),
+			Bot:      p.Bot,
+		})
+	}
+	for _, t := range teams {
+		report.Teams = append(report.Teams, *t)
+	}
+	sort.Slice(report.Teams, func(i, j int) bool { return report.Teams[i].TeamID < report.Teams[j].TeamID })
+
+	for _, ban := range game.BannedChampions {
+		if ban.ChampionID == -1 {
+			continue
+		}
+		report.Bans = append(report.Bans, liveBanRow{TeamID: ban.TeamID, Champion: champion(ban.ChampionID)})
+	}
+	return report
+}
+
+// printLiveGame writes the live game report as human-readable tables.
+func printLiveGame(e *env, r liveGameReport) error {
+	t := newTable(e.stdout)
+	t.row("Game", r.GameID)
+	t.row("Mode", orDash(r.GameMode))
+	t.row("Queue", r.QueueID)
+	t.row("Started", formatEpochMs(r.StartTime))
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	for _, team := range r.Teams {
+		section(e.stdout, fmt.Sprintf("Team %d", team.TeamID))
+		t = newTable(e.stdout)
+		t.row("PLAYER", "CHAMPION", "SPELLS")
+		for _, p := range team.Participants {
+			t.row(orDash(p.RiotID), p.Champion, p.Spell1+" / "+p.Spell2)
+		}
+		if err := t.flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Bans) > 0 {
+		section(e.stdout, "Bans")
+		t = newTable(e.stdout)
+		t.row("TEAM", "CHAMPION")
+		for _, b := range r.Bans {
+			t.row(b.TeamID, b.Champion)
+		}
+		return t.flush()
+	}
+	return nil
+}
+
+// runChampion prints champion details, resolving fuzzy names via the cache.
+func runChampion(ctx context.Context, e *env, args []string) error {
+	var cf commonFlags
+	fs := newFlagSet(e, "champion", &cf)
+	rest, err := parseArgs(fs, &cf, args, 1)
+	if err != nil {
+		return err
+	}
+
+	a, err := loadApp(&cf, false)
+	if err != nil {
+		return err
+	}
+	cfg := a.cfg
+
+	if cfg.Cache.GetChampionMapLen() == 0 {
+		if err := a.loader.Initialize(ctx); err != nil {
+			cfg.Logger.Warn("could not load champion list; using input as champion ID", "error", err)
+		}
+	}
+
+	championID, err := cfg.Cache.SearchChampionName(rest[0])
+	if err != nil {
+		championID = rest[0]
+	}
+
+	champion, ok := cfg.Cache.GetChampionByID(championID)
+	if !ok {
+		champion, err = a.client.FetchChampionData(ctx, championID)
+		if err != nil {
+			if errors.Is(err, client.ErrChampionNotFound) {
+				return fmt.Errorf("champion %q not found", rest[0])
+			}
+			return fmt.Errorf("fetching champion: %w", err)
+		}
+		cfg.Cache.SetChampion(champion)
+		if err := a.loader.SaveCache(); err != nil {
+			cfg.Logger.Warn("could not save cache", "error", err)
+		}
+	}
+
+	if cf.output == "json" {
+		return writeJSON(e.stdout, champion)
+	}
+	return printChampion(e, champion)
+}
+
+// printChampion writes champion details as human-readable tables.
+func printChampion(e *env, c models.Champion) error {
+	t := newTable(e.stdout)
+	t.row("Name", c.Name)
+	t.row("Title", c.Title)
+	t.row("Key", fmt.Sprintf("%s (%d)", c.Key, c.ID))
+	t.row("Positions", orDash(strings.Join(c.Positions, ", ")))
+	t.row("Roles", orDash(strings.Join(c.Roles, ", ")))
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	s := c.Stats
+	section(e.stdout, "Base stats")
+	t = newTable(e.stdout)
+	t.row("STAT", "BASE", "PER LEVEL")
+	t.row("Health", s.Health.Flat, s.Health.PerLevel)
+	t.row("Health regen", s.HealthRegen.Flat, s.HealthRegen.PerLevel)
+	t.row("Mana", s.Mana.Flat, s.Mana.PerLevel)
+	t.row("Mana regen", s.ManaRegen.Flat, s.ManaRegen.PerLevel)
+	t.row("Armor", s.Armor.Flat, s.Armor.PerLevel)
+	t.row("Magic resist", s.MagicResistance.Flat, s.MagicResistance.PerLevel)
+	t.row("Attack damage", s.AttackDamage.Flat, s.AttackDamage.PerLevel)
+	t.row("Attack speed", s.AttackSpeed.Flat, s.AttackSpeed.PerLevel)
+	t.row("Attack range", s.AttackRange.Flat, "-")
+	t.row("Move speed", s.Movespeed.Flat, "-")
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	if len(c.Abilities) > 0 {
+		section(e.stdout, "Abilities")
+		t = newTable(e.stdout)
+		for _, slot := range []string{"P", "Q", "W", "E", "R"} {
+			for _, ab := range c.Abilities[slot] {
+				t.row(slot, ab.Name)
+			}
+		}
+		return t.flush()
+	}
+	return nil
+}
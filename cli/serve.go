@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/auth"
+	"github.com/klnstprx/lolMatchup/client"
+	"github.com/klnstprx/lolMatchup/notes"
+	"github.com/klnstprx/lolMatchup/router"
+	"github.com/klnstprx/lolMatchup/tracing"
+)
+
+// keyFileCheckInterval is how often riot_api_key_file is checked for changes.
+const keyFileCheckInterval = 10 * time.Second
+
+// storeSaveInterval is how often the player index and match store are saved
+// while serving.
+const storeSaveInterval = 5 * time.Minute
+
+// runServe starts the HTTP server and blocks until SIGINT/SIGTERM, then shuts
+// down gracefully and persists the cache.
+func runServe(ctx context.Context, e *env, args []string) error {
+	var cf commonFlags
+	fs := newFlagSet(e, "serve", &cf)
+	if _, err := parseArgs(fs, &cf, args, 0); err != nil {
+		return err
+	}
+
+	a, err := loadApp(&cf, true)
+	if err != nil {
+		return err
+	}
+	cfg := a.cfg
+
+	cfg.Validate(cfg.Logger)
+	if cfg.Debug {
+		cfg.LogConfig()
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Options{
+		Exporter: cfg.TracingExporter,
+		Endpoint: cfg.TracingEndpoint,
+		File:     cfg.TracingFile,
+	})
+	if err != nil {
+		return fmt.Errorf("setting up tracing: %w", err)
+	}
+
+	initCtx, initCancel := context.WithTimeout(ctx, 30*time.Second)
+	defer initCancel()
+
+	if err := a.loader.Initialize(initCtx); err != nil {
+		cfg.Logger.Fatalf("Error during data initialization: %v", err)
+	}
+
+	// Set up router
+	authn, err := auth.New(cfg)
+	if err != nil {
+		return fmt.Errorf("setting up authentication: %w", err)
+	}
+	switch {
+	case authn.Accounts() && authn.Users.Len() == 0:
+		cfg.Logger.Warnf("auth_mode is accounts but %s has no users; create one with 'lolmatchup user add -role admin <name>'", authn.Users.Path())
+	case authn.Enabled() && cfg.SessionSecret == "":
+		cfg.Logger.Warn("session_secret is not set; sign-ins will not survive a restart")
+	}
+	notesStore, err := notes.Open(cfg.NotesPath)
+	if err != nil {
+		return fmt.Errorf("opening matchup notes: %w", err)
+	}
+	r := router.SetupRouter(cfg, a.client, a.loader, authn, notesStore)
+
+	// Handle graceful shutdown signals
+	shutdownCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:    cfg.ListenAddr + ":" + strconv.Itoa(cfg.Port),
+		Handler: r,
+	}
+
+	// Fetch details for champions not cached yet, then keep champion data
+	// current on long-running instances
+	a.loader.WarmInBackground(shutdownCtx)
+	if cfg.PatchCheckMinutes > 0 {
+		go a.loader.Watch(shutdownCtx, time.Duration(cfg.PatchCheckMinutes)*time.Minute)
+	}
+
+	// Keep the index of seen players for autocomplete and the matches fetched
+	// for matchup statistics across restarts
+	go cfg.Players.Autosave(shutdownCtx, storeSaveInterval)
+	go cfg.Matches.Autosave(shutdownCtx, storeSaveInterval)
+
+	// Pick up a rotated Riot API key without a restart
+	go reloadKeyOnHangup(shutdownCtx, a.client.Keys)
+	if cfg.RiotAPIKeyFile != "" {
+		go a.client.Keys.WatchFile(shutdownCtx, cfg.RiotAPIKeyFile, keyFileCheckInterval)
+	}
+
+	// Start serving in a goroutine
+	go func() {
+		cfg.Logger.Infof("HTTP server starting on port %d", cfg.Port)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cfg.Logger.Fatalf("Failed to start HTTP server: %v", err)
+		}
+	}()
+
+	// Wait for shutdown signal
+	<-shutdownCtx.Done()
+	cfg.Logger.Info("Shutdown signal received; stopping HTTP server")
+
+	// Graceful shutdown
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := httpServer.Shutdown(timeoutCtx); err != nil {
+		cfg.Logger.Errorf("HTTP server shutdown error: %v", err)
+	}
+
+	// Save state (cache) before exiting
+	if err := a.loader.SaveCache(); err != nil {
+		cfg.Logger.Errorf("Error saving cache during shutdown: %v", err)
+	} else {
+		cfg.Logger.Info("Cache saved successfully on shutdown.")
+	}
+	if err := cfg.Players.Save(); err != nil {
+		cfg.Logger.Errorf("Error saving player index during shutdown: %v", err)
+	}
+	if err := cfg.Matches.Save(); err != nil {
+		cfg.Logger.Errorf("Error saving match store during shutdown: %v", err)
+	}
+	if err := cfg.Cache.Close(); err != nil {
+		cfg.Logger.Errorf("Error closing cache backend: %v", err)
+	}
+
+	// Flush any buffered spans
+	if err := shutdownTracing(timeoutCtx); err != nil {
+		cfg.Logger.Errorf("Tracing shutdown error: %v", err)
+	}
+
+	cfg.Logger.Info("Server shut down gracefully")
+	return nil
+}
+
+// reloadKeyOnHangup reloads the Riot API key from the configuration each time
+// the process receives SIGHUP, until ctx is done.
+func reloadKeyOnHangup(ctx context.Context, keys *client.KeyProvider) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+		changed, err := keys.Reload("SIGHUP")
+		switch {
+		case err != nil:
+			keys.Logger.Error("Reloading Riot API key failed", "error", err)
+		case !changed:
+			keys.Logger.Info("SIGHUP received; Riot API key unchanged")
+		}
+	}
+}
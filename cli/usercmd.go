@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/klnstprx/lolMatchup/auth"
+)
+
+// stdin supplies passwords to the user command; tests replace it.
+var stdin io.Reader = os.Stdin
+
+// userArgs is the number of positional arguments each user action takes,
+// including the action itself.
+var userArgs = map[string]int{
+	"list":   1,
+	"add":    2,
+	"remove": 2,
+	"token":  2,
+	"revoke": 3,
+}
+
+// runUser manages the local accounts used by auth_mode accounts. It edits
+// users_file directly, and a running server picks the changes up.
+func runUser(ctx context.Context, e *env, args []string) error {
+	var cf commonFlags
+	fs := newFlagSet(e, "user", &cf)
+	role := fs.String("role", string(auth.RoleUser), "role for add: user or admin")
+	label := fs.String("label", "", "label for a new API token")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	rest := fs.Args()
+	if len(rest) == 0 || userArgs[rest[0]] != len(rest) || (cf.output != "table" && cf.output != "json") {
+		fs.Usage()
+		return errUsage
+	}
+
+	cfg, err := buildConfig(&cf)
+	if err != nil {
+		return err
+	}
+	store, err := auth.OpenStore(cfg.UsersFile)
+	if err != nil {
+		return err
+	}
+
+	switch rest[0] {
+	case "list":
+		users := store.Users()
+		if cf.output == "json" {
+			return writeJSON(e.stdout, users)
+		}
+		if len(users) == 0 {
+			fmt.Fprintf(e.stdout, "No users in %s\n", store.Path())
+			return nil
+		}
+		t := newTable(e.stdout)
+		t.row("NAME", "ROLE", "TOKENS", "CREATED")
+		for _, u := range users {
+			t.row(u.Name, u.Role, len(u.Tokens), u.CreatedAt.Format(time.DateOnly))
+		}
+		return t.flush()
+
+	case "add":
+		password, err := readPassword(e)
+		if err != nil {
+			return err
+		}
+		created, err := store.SetUser(rest[1], password, auth.Role(*role))
+		if err != nil {
+			return err
+		}
+		verb := "Updated"
+		if created {
+			verb = "Added"
+		}
+		fmt.Fprintf(e.stdout, "%s %s (%s) in %s\n", verb, rest[1], *role, store.Path())
+
+	case "remove":
+		if err := store.RemoveUser(rest[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "Removed %s\n", rest[1])
+
+	case "token":
+		raw, token, err := store.CreateToken(rest[1], *label)
+		if err != nil {
+			return err
+		}
+		if cf.output == "json" {
+			return writeJSON(e.stdout, map[string]string{"token": raw, "id": token.ID, "label": token.Label})
+		}
+		fmt.Fprintf(e.stderr, "Created token %s for %s. It will not be shown again:\n", token.ID, rest[1])
+		fmt.Fprintln(e.stdout, raw)
+
+	case "revoke":
+		if err := store.RevokeToken(rest[1], rest[2]); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "Revoked token %s of %s\n", rest[2], rest[1])
+	}
+	return nil
+}
+
+// readPassword reads a new password from the terminal without echoing it,
+// asking twice, or from the first line of standard input when it is not a
+// terminal (e.g. `echo "$PASSWORD" | lolmatchup user add alice`).
+func readPassword(e *env) (string, error) {
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(f.Fd()) {
+		fmt.Fprint(e.stderr, "Password: ")
+		first, err := term.ReadPassword(f.Fd())
+		fmt.Fprintln(e.stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading password: %w", err)
+		}
+		fmt.Fprint(e.stderr, "Repeat password: ")
+		second, err := term.ReadPassword(f.Fd())
+		fmt.Fprintln(e.stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading password: %w", err)
+		}
+		if string(first) != string(second) {
+			return "", fmt.Errorf("passwords do not match")
+		}
+		return string(first), nil
+	}
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
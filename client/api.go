@@ -8,10 +8,18 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/cache"
+	"github.com/klnstprx/lolMatchup/metrics"
 	"github.com/klnstprx/lolMatchup/models"
+	"github.com/klnstprx/lolMatchup/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/sync/singleflight"
 )
 
 // Client is a unified client for all API interactions.
@@ -20,7 +28,16 @@ type Client struct {
 	Logger            *log.Logger
 	ChampionDataURL   string
 	DDragonVersionURL string
-	RiotAPIBaseURL    string // when non-empty, overrides Riot API hostname for mock/dev use
+	RiotAPIBaseURL    string         // when non-empty, overrides Riot API hostname for mock/dev use
+	Responses         *ResponseCache // optional short-lived cache for player data
+	Retry             RetryPolicy    // retries for transient upstream failures; zero means none
+	Breakers          *Breakers      // optional per-host circuit breakers
+	Keys              *KeyProvider   // Riot API key, read on every Riot request
+	Players           *cache.Players // optional; remembers the Riot IDs in responses for autocomplete
+	Matches           *cache.Matches // optional; keeps fetched matches for matchup statistics
+
+	recent   recentCalls        // upstream outcomes for RecentErrorRates
+	inflight singleflight.Group // coalesces identical concurrent requests
 }
 
 // riotURL builds the base URL for Riot API calls. When RiotAPIBaseURL is set,
@@ -43,33 +60,162 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Body)
 }
 
+// endpoint identifies an upstream API call. op names the client method and
+// labels metrics; template is the URL path with parameters left as
+// placeholders, recorded on trace spans.
+type endpoint struct {
+	op       string
+	template string
+}
+
+var (
+	epSummonerByPUUID = endpoint{"FetchSummonerByPUUID", "/lol/summoner/v4/summoners/by-puuid/{puuid}"}
+	epAccountByRiotID = endpoint{"FetchAccountByRiotID", "/riot/account/v1/accounts/by-riot-id/{gameName}/{tagLine}"}
+	epCurrentGame     = endpoint{"FetchCurrentGameByPUUID", "/lol/spectator/v5/active-games/by-summoner/{puuid}"}
+	epMatchIDs        = endpoint{"FetchMatchIDs", "/lol/match/v5/matches/by-puuid/{puuid}/ids"}
+	epMatch           = endpoint{"FetchMatch", "/lol/match/v5/matches/{matchId}"}
+	epLeagueEntries   = endpoint{"FetchLeagueEntries", "/lol/league/v4/entries/by-puuid/{puuid}"}
+	epSummonerSpells  = endpoint{"FetchSummonerSpells", "/cdn/{patch}/data/en_US/summoner.json"}
+	epChampionData    = endpoint{"FetchChampionData", "champions/{championId}.json"}
+	epChampionList    = endpoint{"FetchChampionList", "champions.json"}
+	epLatestPatch     = endpoint{"FetchLatestPatch", "/api/versions.json"}
+	epPlatformStatus  = endpoint{"ProbeAPIKey", "/lol/status/v4/platform-data"}
+)
+
 // doJSON performs a GET request, reads the response, and unmarshals JSON into target.
 // For non-200 responses it returns an *APIError. If riotAPIKey is non-empty, the
-// X-Riot-Token header is set.
-func (c *Client) doJSON(ctx context.Context, url, riotAPIKey string, target interface{}) error {
+// X-Riot-Token header is set and Riot's answer is recorded on c.Keys: a 401 or
+// 403 marks the key as expired (every endpoint used here is open to all key
+// types, so a refusal means the key itself was rejected) and a success clears
+// that. Each call is recorded as a client span labelled with ep.
+//
+// Concurrent calls for the same endpoint, URL and key are coalesced: one
+// upstream request is made and every caller decodes the shared response. The
+// shared request is detached from the callers' cancellation, so a caller that
+// gives up returns its own context error without failing the others; the HTTP
+// client timeout still bounds the request.
+func (c *Client) doJSON(ctx context.Context, ep endpoint, url, riotAPIKey string, target interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "client."+ep.op,
+		attribute.String("url.template", ep.template),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	key := ep.op + "\x00" + url + "\x00" + riotAPIKey
+	deadline, _ := ctx.Deadline()
+	ch := c.inflight.DoChan(key, func() (interface{}, error) {
+		return c.fetch(context.WithoutCancel(ctx), ep, url, riotAPIKey, deadline)
+	})
+
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to execute request: %w", ctx.Err())
+	}
+	fr := res.Val.(fetchResult)
+	span.SetAttributes(attribute.Bool("coalesced", res.Shared), attribute.Int("attempt", fr.attempts))
+
+	var apiErr *APIError
+	switch {
+	case errors.As(res.Err, &apiErr):
+		span.SetAttributes(attribute.Int("http.response.status_code", apiErr.StatusCode))
+		if apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden {
+			c.Keys.observe(riotAPIKey, true)
+		}
+		return res.Err
+	case res.Err != nil:
+		return res.Err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", http.StatusOK))
+	c.Keys.observe(riotAPIKey, false)
+
+	if err := json.Unmarshal(fr.body, target); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return nil
+}
+
+// fetchResult is the outcome of fetch shared between coalesced callers.
+type fetchResult struct {
+	body     []byte
+	attempts int
+}
+
+// fetch performs an upstream GET, retrying transient failures according to
+// c.Retry, and returns the body of a 200 response or an *APIError for other
+// statuses. Requests are refused with ErrCircuitOpen while the host's circuit
+// breaker is open. A retry is skipped when its backoff would run past
+// deadline (zero means none).
+func (c *Client) fetch(ctx context.Context, ep endpoint, url, riotAPIKey string, deadline time.Time) (fetchResult, error) {
+	var res fetchResult
+	for {
+		res.attempts++
+		body, status, retryAfter, host, err := c.attempt(ctx, ep, url, riotAPIKey)
+		if err == nil && status == http.StatusOK {
+			res.body = body
+			return res, nil
+		}
+		if errors.Is(err, ErrCircuitOpen) {
+			return res, err
+		}
+		retry := retryable(status, err)
+		if err == nil {
+			err = &APIError{StatusCode: status, Body: string(body)}
+		}
+		if !retry {
+			return res, err
+		}
+		wait, ok := c.Retry.backoff(res.attempts, retryAfter)
+		if !ok || (!deadline.IsZero() && time.Until(deadline) < wait) {
+			return res, err
+		}
+		metrics.UpstreamRetries.WithLabelValues(host, ep.op).Inc()
+		time.Sleep(wait)
+	}
+}
+
+// attempt performs one upstream GET. It returns the response body and status,
+// or an error if no response was received. The call is checked against and
+// recorded in the host's circuit breaker, the upstream request metrics and the
+// recent error-rate window.
+func (c *Client) attempt(ctx context.Context, ep endpoint, url, riotAPIKey string) (body []byte, status int, retryAfter time.Duration, host string, err error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, 0, 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	host = req.URL.Host
+	if err := c.Breakers.allow(host); err != nil {
+		return nil, 0, 0, host, err
 	}
 	if riotAPIKey != "" {
 		req.Header.Set("X-Riot-Token", riotAPIKey)
 	}
+
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(req)
+	metrics.UpstreamDuration.WithLabelValues(host, ep.op).Observe(time.Since(start).Seconds())
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		metrics.UpstreamRequests.WithLabelValues(host, ep.op, "error").Inc()
+		c.recent.add(time.Now(), host, true)
+		c.Breakers.record(host, true)
+		return nil, 0, 0, host, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	metrics.UpstreamRequests.WithLabelValues(host, ep.op, strconv.Itoa(resp.StatusCode)).Inc()
+	c.recent.add(time.Now(), host, isUpstreamFailure(resp.StatusCode))
+	c.Breakers.record(host, resp.StatusCode >= http.StatusInternalServerError)
+
+	body, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, 0, 0, host, fmt.Errorf("failed to read response body: %w", err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
-	}
-	if err := json.Unmarshal(body, target); err != nil {
-		return fmt.Errorf("failed to parse JSON: %w", err)
-	}
-	return nil
+	return body, resp.StatusCode, parseRetryAfter(resp.Header), host, nil
 }
 
 // mapAPIError maps an *APIError to domain-specific sentinel errors.
@@ -120,10 +266,10 @@ type SummonerDTO struct {
 }
 
 // FetchSummonerByPUUID retrieves summoner information by encrypted PUUID.
-func (c *Client) FetchSummonerByPUUID(ctx context.Context, puuid, riotRegion, riotAPIKey string) (SummonerDTO, error) {
+func (c *Client) FetchSummonerByPUUID(ctx context.Context, puuid, riotRegion string) (SummonerDTO, error) {
 	var summoner SummonerDTO
 	reqURL := fmt.Sprintf("%s/lol/summoner/v4/summoners/by-puuid/%s", c.riotURL(riotRegion), url.PathEscape(puuid))
-	if err := c.doJSON(ctx, reqURL, riotAPIKey, &summoner); err != nil {
+	if err := c.cachedJSON(ctx, epSummonerByPUUID, reqURL, c.Keys.Key(), &summoner); err != nil {
 		return summoner, mapAPIError(err, ErrSummonerNotFound)
 	}
 	return summoner, nil
@@ -137,7 +283,7 @@ type AccountDTO struct {
 }
 
 // FetchAccountByRiotID retrieves account information (incl. puuid) via gameName/tagLine.
-func (c *Client) FetchAccountByRiotID(ctx context.Context, gameName, tagLine, riotRegion, riotAPIKey string) (AccountDTO, error) {
+func (c *Client) FetchAccountByRiotID(ctx context.Context, gameName, tagLine, riotRegion string) (AccountDTO, error) {
 	var acct AccountDTO
 	// Determine the regional cluster for account-v1.
 	cluster, ok := RegionToCluster[riotRegion]
@@ -148,58 +294,71 @@ func (c *Client) FetchAccountByRiotID(ctx context.Context, gameName, tagLine, ri
 		"%s/riot/account/v1/accounts/by-riot-id/%s/%s",
 		c.riotURL(cluster), url.PathEscape(gameName), url.PathEscape(tagLine),
 	)
-	if err := c.doJSON(ctx, reqURL, riotAPIKey, &acct); err != nil {
+	if err := c.cachedJSON(ctx, epAccountByRiotID, reqURL, c.Keys.Key(), &acct); err != nil {
 		return acct, mapAPIError(err, ErrAccountNotFound)
 	}
+	c.Players.Observe(riotRegion, cache.SeenPlayer{PUUID: acct.PUUID, GameName: acct.GameName, TagLine: acct.TagLine})
 	return acct, nil
 }
 
 // FetchCurrentGameByPUUID retrieves current game info using encrypted PUUID (spectator v5).
-func (c *Client) FetchCurrentGameByPUUID(ctx context.Context, puuid, riotRegion, riotAPIKey string) (models.CurrentGameInfo, error) {
+func (c *Client) FetchCurrentGameByPUUID(ctx context.Context, puuid, riotRegion string) (models.CurrentGameInfo, error) {
 	var game models.CurrentGameInfo
 	reqURL := fmt.Sprintf("%s/lol/spectator/v5/active-games/by-summoner/%s", c.riotURL(riotRegion), url.PathEscape(puuid))
-	if err := c.doJSON(ctx, reqURL, riotAPIKey, &game); err != nil {
+	if err := c.doJSON(ctx, epCurrentGame, reqURL, c.Keys.Key(), &game); err != nil {
 		return game, mapAPIError(err, ErrGameNotFound)
 	}
+	seen := make([]cache.SeenPlayer, 0, len(game.Participants))
+	for _, p := range game.Participants {
+		name, tag, _ := strings.Cut(p.RiotID, "#")
+		seen = append(seen, cache.SeenPlayer{PUUID: p.PUUID, GameName: name, TagLine: tag})
+	}
+	c.Players.Observe(riotRegion, seen...)
 	return game, nil
 }
 
 // FetchMatchIDs retrieves recent match IDs for a player by PUUID (match-v5, cluster routing).
 // The start parameter controls the offset for pagination.
-func (c *Client) FetchMatchIDs(ctx context.Context, puuid, riotRegion, riotAPIKey string, count, start int) ([]string, error) {
+func (c *Client) FetchMatchIDs(ctx context.Context, puuid, riotRegion string, count, start int) ([]string, error) {
 	cluster, ok := RegionToCluster[riotRegion]
 	if !ok {
 		cluster = riotRegion
 	}
 	var ids []string
 	reqURL := fmt.Sprintf("%s/lol/match/v5/matches/by-puuid/%s/ids?count=%d&start=%d", c.riotURL(cluster), url.PathEscape(puuid), count, start)
-	if err := c.doJSON(ctx, reqURL, riotAPIKey, &ids); err != nil {
+	if err := c.cachedJSON(ctx, epMatchIDs, reqURL, c.Keys.Key(), &ids); err != nil {
 		return nil, err
 	}
 	return ids, nil
 }
 
 // FetchMatch retrieves full match data by match ID (match-v5, cluster routing).
-func (c *Client) FetchMatch(ctx context.Context, matchID, riotRegion, riotAPIKey string) (models.MatchDTO, error) {
+func (c *Client) FetchMatch(ctx context.Context, matchID, riotRegion string) (models.MatchDTO, error) {
 	cluster, ok := RegionToCluster[riotRegion]
 	if !ok {
 		cluster = riotRegion
 	}
 	var match models.MatchDTO
 	reqURL := fmt.Sprintf("%s/lol/match/v5/matches/%s", c.riotURL(cluster), url.PathEscape(matchID))
-	if err := c.doJSON(ctx, reqURL, riotAPIKey, &match); err != nil {
+	if err := c.doJSON(ctx, epMatch, reqURL, c.Keys.Key(), &match); err != nil {
 		return match, mapAPIError(err, ErrMatchNotFound)
 	}
+	seen := make([]cache.SeenPlayer, 0, len(match.Info.Participants))
+	for _, p := range match.Info.Participants {
+		seen = append(seen, cache.SeenPlayer{PUUID: p.PUUID, GameName: p.RiotIDGameName, TagLine: p.RiotIDTagline})
+	}
+	c.Players.Observe(riotRegion, seen...)
+	c.Matches.Observe(match)
 	return match, nil
 }
 
 // FetchLeagueEntries retrieves ranked league entries for a player by PUUID.
 // Uses regional routing (same as Summoner v4). Returns an empty slice for unranked players.
-func (c *Client) FetchLeagueEntries(ctx context.Context, puuid, riotRegion, riotAPIKey string) ([]models.LeagueEntryDTO, error) {
+func (c *Client) FetchLeagueEntries(ctx context.Context, puuid, riotRegion string) ([]models.LeagueEntryDTO, error) {
 	var entries []models.LeagueEntryDTO
 	reqURL := fmt.Sprintf("%s/lol/league/v4/entries/by-puuid/%s",
 		c.riotURL(riotRegion), url.PathEscape(puuid))
-	if err := c.doJSON(ctx, reqURL, riotAPIKey, &entries); err != nil {
+	if err := c.cachedJSON(ctx, epLeagueEntries, reqURL, c.Keys.Key(), &entries); err != nil {
 		return nil, mapAPIError(err, ErrLeagueNotFound)
 	}
 	return entries, nil
@@ -211,7 +370,7 @@ func (c *Client) FetchSummonerSpells(ctx context.Context, patchNumber string) (m
 	reqURL := fmt.Sprintf("https://ddragon.leagueoflegends.com/cdn/%s/data/en_US/summoner.json", patchNumber)
 	c.Logger.Debug("Fetching summoner spells", "url", reqURL)
 	var data models.DDragonSpellData
-	if err := c.doJSON(ctx, reqURL, "", &data); err != nil {
+	if err := c.doJSON(ctx, epSummonerSpells, reqURL, "", &data); err != nil {
 		return nil, fmt.Errorf("failed to fetch summoner spells: %w", err)
 	}
 	return models.ParseSummonerSpells(data), nil
@@ -222,7 +381,7 @@ func (c *Client) FetchChampionData(ctx context.Context, championID string) (mode
 	var champion models.Champion
 	reqURL := fmt.Sprintf("%schampions/%s.json", c.ChampionDataURL, url.PathEscape(championID))
 	c.Logger.Debug("Fetching champion data", "url", reqURL, "champID", championID)
-	if err := c.doJSON(ctx, reqURL, "", &champion); err != nil {
+	if err := c.doJSON(ctx, epChampionData, reqURL, "", &champion); err != nil {
 		return champion, mapAPIError(err, ErrChampionNotFound)
 	}
 	return champion, nil
@@ -233,7 +392,7 @@ func (c *Client) FetchChampionList(ctx context.Context) (map[string]models.Champ
 	var champions map[string]models.Champion
 	reqURL := fmt.Sprintf("%schampions.json", c.ChampionDataURL)
 	c.Logger.Debug("Fetching champion list", "url", reqURL)
-	if err := c.doJSON(ctx, reqURL, "", &champions); err != nil {
+	if err := c.doJSON(ctx, epChampionList, reqURL, "", &champions); err != nil {
 		return champions, err
 	}
 	return champions, nil
@@ -243,7 +402,7 @@ func (c *Client) FetchChampionList(ctx context.Context) (map[string]models.Champ
 func (c *Client) FetchLatestPatch(ctx context.Context) (string, error) {
 	c.Logger.Debug("Fetching latest patch", "url", c.DDragonVersionURL)
 	var versions []string
-	if err := c.doJSON(ctx, c.DDragonVersionURL, "", &versions); err != nil {
+	if err := c.doJSON(ctx, epLatestPatch, c.DDragonVersionURL, "", &versions); err != nil {
 		return "", err
 	}
 	if len(versions) == 0 {
@@ -251,3 +410,35 @@ func (c *Client) FetchLatestPatch(ctx context.Context) (string, error) {
 	}
 	return versions[0], nil
 }
+
+// KeyStatus is the outcome of probing the Riot API key.
+type KeyStatus string
+
+const (
+	KeyValid   KeyStatus = "valid"   // the probe succeeded
+	KeyInvalid KeyStatus = "invalid" // Riot rejected the key (401/403)
+	KeyMissing KeyStatus = "missing" // no key is configured
+	KeyUnknown KeyStatus = "unknown" // the probe failed for another reason
+)
+
+// ProbeAPIKey checks whether the current key is accepted by calling the
+// platform status endpoint, which is cheap and available to every key type.
+// The error is non-nil only for KeyUnknown and describes why the probe was
+// inconclusive.
+func (c *Client) ProbeAPIKey(ctx context.Context, riotRegion string) (KeyStatus, error) {
+	riotAPIKey := c.Keys.Key()
+	if riotAPIKey == "" {
+		return KeyMissing, nil
+	}
+	reqURL := fmt.Sprintf("%s/lol/status/v4/platform-data", c.riotURL(riotRegion))
+	var status json.RawMessage
+	err := c.doJSON(ctx, epPlatformStatus, reqURL, riotAPIKey, &status)
+	if err == nil {
+		return KeyValid, nil
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+		return KeyInvalid, nil
+	}
+	return KeyUnknown, err
+}
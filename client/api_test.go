@@ -3,13 +3,27 @@ package client
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/cache"
+	"github.com/klnstprx/lolMatchup/metrics"
+	"github.com/klnstprx/lolMatchup/models"
+	"github.com/klnstprx/lolMatchup/tracing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // fakeTransport implements http.RoundTripper for testing.
@@ -28,6 +42,7 @@ func newTestClient(transport fakeTransport) *Client {
 		Logger:            log.New(os.Stderr),
 		ChampionDataURL:   "http://fake.test/",
 		DDragonVersionURL: "http://fake.test/versions.json",
+		Keys:              NewKeyProvider("k", nil),
 	}
 }
 
@@ -53,7 +68,7 @@ func TestFetchSummonerByPUUID(t *testing.T) {
 					Body:       io.NopCloser(strings.NewReader(tt.body)),
 				},
 			})
-			summ, err := c.FetchSummonerByPUUID(context.Background(), "test-puuid", "euw1", "k")
+			summ, err := c.FetchSummonerByPUUID(context.Background(), "test-puuid", "euw1")
 			if tt.wantErr != nil {
 				if !errors.Is(err, tt.wantErr) {
 					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
@@ -94,7 +109,7 @@ func TestFetchAccountByRiotID(t *testing.T) {
 					Body:       io.NopCloser(strings.NewReader(tt.body)),
 				},
 			})
-			acct, err := c.FetchAccountByRiotID(context.Background(), "p", "t", tt.region, "k")
+			acct, err := c.FetchAccountByRiotID(context.Background(), "p", "t", tt.region)
 			if tt.wantErr != nil {
 				if !errors.Is(err, tt.wantErr) {
 					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
@@ -133,7 +148,7 @@ func TestFetchCurrentGameByPUUID(t *testing.T) {
 					Body:       io.NopCloser(strings.NewReader(tt.body)),
 				},
 			})
-			game, err := c.FetchCurrentGameByPUUID(context.Background(), "puuid", "na1", "k")
+			game, err := c.FetchCurrentGameByPUUID(context.Background(), "puuid", "na1")
 			if tt.wantErr != nil {
 				if !errors.Is(err, tt.wantErr) {
 					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
@@ -291,14 +306,14 @@ func TestURLEncoding(t *testing.T) {
 		{
 			name: "summoner puuid with slash",
 			call: func(c *Client) {
-				c.FetchSummonerByPUUID(context.Background(), "abc/def", "na1", "k")
+				c.FetchSummonerByPUUID(context.Background(), "abc/def", "na1")
 			},
 			wantInURL: "abc%2Fdef",
 		},
 		{
 			name: "riot id gameName with space",
 			call: func(c *Client) {
-				c.FetchAccountByRiotID(context.Background(), "Some Player", "NA1", "na1", "k")
+				c.FetchAccountByRiotID(context.Background(), "Some Player", "NA1", "na1")
 			},
 			wantInURL: "Some%20Player",
 		},
@@ -361,7 +376,7 @@ func TestFetchMatchIDs(t *testing.T) {
 					Body:       io.NopCloser(strings.NewReader(tt.body)),
 				},
 			})
-			ids, err := c.FetchMatchIDs(context.Background(), "puuid", "euw1", "k", 3, 0)
+			ids, err := c.FetchMatchIDs(context.Background(), "puuid", "euw1", 3, 0)
 			if tt.wantErr {
 				if err == nil {
 					t.Fatal("expected error, got nil")
@@ -399,7 +414,7 @@ func TestFetchMatch(t *testing.T) {
 					Body:       io.NopCloser(strings.NewReader(tt.body)),
 				},
 			})
-			match, err := c.FetchMatch(context.Background(), "EUW1_123", "euw1", "k")
+			match, err := c.FetchMatch(context.Background(), "EUW1_123", "euw1")
 			if tt.wantErr != nil {
 				if !errors.Is(err, tt.wantErr) {
 					t.Fatalf("expected %v, got %v", tt.wantErr, err)
@@ -452,3 +467,247 @@ func TestAPIError(t *testing.T) {
 		t.Fatal("expected errors.As to match *APIError")
 	}
 }
+
+func TestDoJSONRecordsMetrics(t *testing.T) {
+	counter := metrics.UpstreamRequests.WithLabelValues("fake.test", "FetchLatestPatch", "200")
+	before := testutil.ToFloat64(counter)
+
+	c := newTestClient(fakeTransport{
+		resp: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`["15.1.1"]`)),
+		},
+	})
+	if _, err := c.FetchLatestPatch(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := testutil.ToFloat64(counter) - before; got != 1 {
+		t.Errorf("expected upstream request counter to grow by 1, got %v", got)
+	}
+}
+
+func TestDoJSONRecordsSpan(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	c := newTestClient(fakeTransport{
+		resp: &http.Response{
+			StatusCode: http.StatusNotFound,
+			Body:       io.NopCloser(strings.NewReader(`{}`)),
+		},
+	})
+	ctx := tracing.WithRequestID(context.Background(), "req-42")
+	if _, err := c.FetchMatch(ctx, "NA1_1", "na1"); err == nil {
+		t.Fatal("expected error for 404 response")
+	}
+
+	ended := rec.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(ended))
+	}
+	span := ended[0]
+	if span.Name() != "client.FetchMatch" {
+		t.Errorf("span name: got %q", span.Name())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("failed call should mark span as error, got %v", span.Status().Code)
+	}
+	attrs := attribute.NewSet(span.Attributes()...)
+	want := map[attribute.Key]string{
+		"url.template":              epMatch.template,
+		"http.response.status_code": "404",
+		"attempt":                   "1",
+		tracing.RequestIDKey:        "req-42",
+	}
+	for k, v := range want {
+		got, ok := attrs.Value(k)
+		if !ok || got.Emit() != v {
+			t.Errorf("attribute %s: got %q, want %q", k, got.Emit(), v)
+		}
+	}
+}
+
+func TestProbeAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		status  int
+		want    KeyStatus
+		wantErr bool
+	}{
+		{"no key", "", 0, KeyMissing, false},
+		{"valid", "k", http.StatusOK, KeyValid, false},
+		{"forbidden", "k", http.StatusForbidden, KeyInvalid, false},
+		{"unauthorized", "k", http.StatusUnauthorized, KeyInvalid, false},
+		{"server error", "k", http.StatusServiceUnavailable, KeyUnknown, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(fakeTransport{
+				resp: &http.Response{
+					StatusCode: tt.status,
+					Body:       io.NopCloser(strings.NewReader(`{}`)),
+				},
+			})
+			c.Keys = NewKeyProvider(tt.key, nil)
+			got, err := c.ProbeAPIKey(context.Background(), "na1")
+			if got != tt.want {
+				t.Errorf("status: got %q, want %q", got, tt.want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// gatedTransport counts requests and holds each response until release is closed.
+type gatedTransport struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+	body    string
+}
+
+func (g *gatedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if g.calls.Add(1) == 1 {
+		close(g.started)
+	}
+	<-g.release
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(g.body)),
+	}, nil
+}
+
+func TestDoJSONCoalescesConcurrentCalls(t *testing.T) {
+	gt := &gatedTransport{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		body:    `{"metadata":{"matchId":"EUW1_123"},"info":{"gameDuration":1800}}`,
+	}
+	c := &Client{HTTPClient: &http.Client{Transport: gt}, Logger: log.New(io.Discard)}
+
+	const callers = 5
+	errs := make(chan error, callers)
+	var wg sync.WaitGroup
+	fetch := func(ctx context.Context) {
+		defer wg.Done()
+		match, err := c.FetchMatch(ctx, "EUW1_123", "euw1")
+		if err == nil && match.Metadata.MatchID != "EUW1_123" {
+			err = fmt.Errorf("bad match parsed: %+v", match.Metadata)
+		}
+		errs <- err
+	}
+
+	// The first caller starts the upstream request and then gives up.
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	wg.Add(1)
+	go fetch(cancelCtx)
+	<-gt.started
+
+	wg.Add(callers - 1)
+	for range callers - 1 {
+		go fetch(context.Background())
+	}
+	// Let the others join the in-flight call before the first caller cancels.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(gt.release)
+	wg.Wait()
+	close(errs)
+
+	var canceled, ok int
+	for err := range errs {
+		switch {
+		case errors.Is(err, context.Canceled):
+			canceled++
+		case err == nil:
+			ok++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if canceled != 1 || ok != callers-1 {
+		t.Errorf("got %d canceled and %d successful callers, want 1 and %d", canceled, ok, callers-1)
+	}
+	if n := gt.calls.Load(); n != 1 {
+		t.Errorf("upstream calls: got %d, want 1", n)
+	}
+}
+
+func TestDoJSONDoesNotCoalesceDifferentKeys(t *testing.T) {
+	gt := &gatedTransport{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		body:    `{"metadata":{"matchId":"EUW1_123"}}`,
+	}
+	close(gt.release)
+	c := &Client{HTTPClient: &http.Client{Transport: gt}, Logger: log.New(io.Discard)}
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"k1", "k2"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var match models.MatchDTO
+			if err := c.doJSON(context.Background(), epMatch, "http://fake.test/lol/match/v5/matches/EUW1_123", key, &match); err != nil {
+				t.Errorf("doJSON(%s) error: %v", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := gt.calls.Load(); n != 2 {
+		t.Errorf("upstream calls: got %d, want 2", n)
+	}
+}
+
+func TestFetchesRecordSeenPlayers(t *testing.T) {
+	const matchJSON = `{"metadata":{"matchId":"EUW1_1"},"info":{"participants":[
+		{"puuid":"p1","riotIdGameName":"Faker","riotIdTagline":"KR1"},
+		{"puuid":"p2","riotIdGameName":"","riotIdTagline":""}]}}`
+	const gameJSON = `{"gameId":1,"participants":[{"riotId":"Caps#EUW","puuid":"p3"},{"riotId":"","puuid":"","bot":true}]}`
+
+	players := cache.NewPlayers("", 0)
+	for _, body := range []string{matchJSON, gameJSON} {
+		c := newTestClient(fakeTransport{resp: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}})
+		c.Players = players
+		if body == matchJSON {
+			if _, err := c.FetchMatch(context.Background(), "EUW1_1", "euw1"); err != nil {
+				t.Fatal(err)
+			}
+		} else if _, err := c.FetchCurrentGameByPUUID(context.Background(), "p3", "euw1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if players.Len() != 2 {
+		t.Fatalf("recorded %d players, want 2", players.Len())
+	}
+	got := players.Search("caps#", 10)
+	if len(got) != 1 || got[0].RiotID() != "Caps#EUW" || got[0].PUUID != "p3" || got[0].Region != "euw1" {
+		t.Errorf("Search(caps#) = %+v", got)
+	}
+}
+
+func TestFetchMatchStoresMatch(t *testing.T) {
+	const matchJSON = `{"metadata":{"matchId":"EUW1_1"},"info":{"gameDuration":1800,"gameVersion":"14.10.585.1","participants":[
+		{"puuid":"p1","teamId":100,"championName":"Ahri","individualPosition":"MIDDLE","win":true,"goldEarned":12000},
+		{"puuid":"p2","teamId":200,"championName":"Zed","individualPosition":"MIDDLE","goldEarned":11000}]}}`
+
+	matches := cache.NewMatches("", 0)
+	c := newTestClient(fakeTransport{resp: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(matchJSON))}})
+	c.Matches = matches
+	if _, err := c.FetchMatch(context.Background(), "EUW1_1", "euw1"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := matches.Total(cache.MatchupQuery{Champion: "Ahri", Opponent: "Zed", Patch: "14.10"})
+	if got.Games != 1 || got.Wins != 1 || got.GoldDiff != 1000 {
+		t.Errorf("stored Ahri vs Zed = %+v, want one win 1000 gold ahead", got)
+	}
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/metrics"
+)
+
+// ErrCircuitOpen is returned without contacting the upstream while the
+// circuit breaker for its host is open.
+var ErrCircuitOpen = errors.New("upstream unavailable: circuit open")
+
+// BreakerState is the state of one host's circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets requests through and counts consecutive failures.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails requests fast until the open period has passed.
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe request through; its outcome closes
+	// or reopens the circuit.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breakers holds a circuit breaker per upstream host. A host's circuit opens
+// after Threshold consecutive failures (network errors or 5xx responses) and
+// stays open for OpenFor, after which one probe request decides whether it
+// closes again. A nil *Breakers never trips.
+type Breakers struct {
+	Threshold int
+	OpenFor   time.Duration
+
+	now   func() time.Time
+	mu    sync.Mutex
+	hosts map[string]*breaker
+}
+
+type breaker struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreakers returns per-host circuit breakers with the given failure
+// threshold and open period. A threshold below 1 returns nil, disabling them.
+func NewBreakers(threshold int, openFor time.Duration) *Breakers {
+	if threshold < 1 {
+		return nil
+	}
+	return &Breakers{Threshold: threshold, OpenFor: openFor, now: time.Now, hosts: make(map[string]*breaker)}
+}
+
+// allow reports whether a request to host may proceed, moving an open circuit
+// to half-open once its open period has passed.
+func (b *Breakers) allow(host string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br := b.hosts[host]
+	if br == nil {
+		return nil
+	}
+	switch br.state {
+	case BreakerOpen:
+		if b.now().Sub(br.openedAt) < b.OpenFor {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+		}
+		b.setState(host, br, BreakerHalfOpen)
+		br.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if br.probing {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+		}
+		br.probing = true
+	}
+	return nil
+}
+
+// record updates host's breaker with the outcome of a request that allow let
+// through.
+func (b *Breakers) record(host string, failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br := b.hosts[host]
+	if br == nil {
+		if !failed {
+			return
+		}
+		br = &breaker{}
+		b.hosts[host] = br
+	}
+	br.probing = false
+	if !failed {
+		br.failures = 0
+		b.setState(host, br, BreakerClosed)
+		return
+	}
+	br.failures++
+	if br.state == BreakerHalfOpen || br.failures >= b.Threshold {
+		br.openedAt = b.now()
+		b.setState(host, br, BreakerOpen)
+	}
+}
+
+// setState changes br's state and updates the circuit state gauge.
+func (b *Breakers) setState(host string, br *breaker, s BreakerState) {
+	if br.state == s {
+		return
+	}
+	br.state = s
+	metrics.UpstreamCircuitState.WithLabelValues(host).Set(float64(s))
+}
+
+// HostBreaker is the state of one host's circuit breaker.
+type HostBreaker struct {
+	Host     string `json:"host"`
+	State    string `json:"state"`
+	Failures int    `json:"consecutive_failures"`
+}
+
+// States returns the circuit state of every host that has failed since
+// startup, sorted by host.
+func (b *Breakers) States() []HostBreaker {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]HostBreaker, 0, len(b.hosts))
+	for host, br := range b.hosts {
+		out = append(out, HostBreaker{Host: host, State: br.state.String(), Failures: br.failures})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Host < out[j].Host })
+	return out
+}
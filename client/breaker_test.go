@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	now := time.Now()
+	b := NewBreakers(2, 30*time.Second)
+	b.now = func() time.Time { return now }
+	const host = "euw1.api.riotgames.com"
+
+	b.record(host, true)
+	if err := b.allow(host); err != nil {
+		t.Fatalf("one failure should not open the circuit: %v", err)
+	}
+	b.record(host, true)
+	if err := b.allow(host); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("after threshold: got %v, want ErrCircuitOpen", err)
+	}
+	if err := b.allow("other.test"); err != nil {
+		t.Errorf("other hosts should be unaffected: %v", err)
+	}
+
+	now = now.Add(31 * time.Second)
+	if err := b.allow(host); err != nil {
+		t.Fatalf("after open period the probe should pass: %v", err)
+	}
+	if err := b.allow(host); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("only one probe at a time: got %v", err)
+	}
+	b.record(host, true)
+	if got := b.States()[0].State; got != "open" {
+		t.Fatalf("failed probe should reopen, got %s", got)
+	}
+
+	now = now.Add(31 * time.Second)
+	if err := b.allow(host); err != nil {
+		t.Fatal(err)
+	}
+	b.record(host, false)
+	if err := b.allow(host); err != nil {
+		t.Errorf("successful probe should close the circuit: %v", err)
+	}
+	if s := b.States()[0]; s.State != "closed" || s.Failures != 0 {
+		t.Errorf("state after recovery: %+v", s)
+	}
+}
+
+func TestBreakerFailsFastThroughClient(t *testing.T) {
+	tr := &scriptedTransport{statuses: []int{503}}
+	c := newRetryClient(tr, RetryPolicy{})
+	c.Breakers = NewBreakers(3, time.Minute)
+
+	for range 3 {
+		if _, err := c.FetchLatestPatch(context.Background()); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("circuit opened early: %v", err)
+		}
+	}
+	_, err := c.FetchLatestPatch(context.Background())
+	if !errors.Is(err, ErrCircuitOpen) || !IsDegraded(err) {
+		t.Fatalf("got %v, want ErrCircuitOpen", err)
+	}
+	if tr.Calls() != 3 {
+		t.Errorf("open circuit should not reach the upstream: %d calls", tr.Calls())
+	}
+}
+
+func TestNilBreakers(t *testing.T) {
+	var b *Breakers
+	b.record("h", true)
+	if err := b.allow("h"); err != nil || b.States() != nil {
+		t.Errorf("nil Breakers should be inert, got %v", err)
+	}
+	if NewBreakers(0, time.Second) != nil {
+		t.Error("threshold 0 should disable breakers")
+	}
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// KeyProvider holds the Riot API key used for every Riot request. Development
+// keys expire after 24 hours, so the key can be replaced while the server runs:
+// by Set (the admin form), or by Reload, which asks the configured source for
+// the current key (on SIGHUP or when the key file changes). It is safe for
+// concurrent use; a nil KeyProvider has no key.
+type KeyProvider struct {
+	Logger *log.Logger
+
+	load func() (string, error) // re-reads the key for Reload; nil disables reloading
+
+	mu        sync.RWMutex
+	key       string
+	source    string
+	updatedAt time.Time
+	rejected  bool // Riot answered 401/403 to the current key
+}
+
+// KeyInfo describes the key currently in use without revealing it.
+type KeyInfo struct {
+	Set       bool      `json:"set"`
+	Hint      string    `json:"hint,omitempty"` // last four characters
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Expired   bool      `json:"expired"`
+}
+
+// NewKeyProvider returns a provider holding key, loaded at startup. load, if
+// non-nil, is called by Reload to fetch the current key again, typically by
+// re-reading the configuration and riot_api_key_file.
+func NewKeyProvider(key string, load func() (string, error)) *KeyProvider {
+	return &KeyProvider{load: load, key: key, source: "config", updatedAt: time.Now()}
+}
+
+// Key returns the key to send with the next Riot request.
+func (p *KeyProvider) Key() string {
+	if p == nil {
+		return ""
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.key
+}
+
+// Set replaces the key, recording source (e.g. "admin" or "SIGHUP") for the
+// status page, and reports whether it changed. A new key clears the expired
+// state; setting the same key again leaves it as is.
+func (p *KeyProvider) Set(key, source string) bool {
+	key = strings.TrimSpace(key)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key == p.key {
+		return false
+	}
+	p.key, p.source, p.updatedAt, p.rejected = key, source, time.Now(), false
+	if p.Logger != nil {
+		p.Logger.Info("Riot API key updated", "source", source, "hint", keyHint(key))
+	}
+	return true
+}
+
+// Reload fetches the key from the provider's source and installs it if it
+// changed. It does nothing when the provider was created without a source.
+func (p *KeyProvider) Reload(source string) (bool, error) {
+	if p == nil || p.load == nil {
+		return false, nil
+	}
+	key, err := p.load()
+	if err != nil {
+		return false, err
+	}
+	return p.Set(key, source), nil
+}
+
+// Expired reports whether Riot has rejected the current key, which for a
+// development key almost always means it has expired. It clears once the key
+// is replaced or a request with it succeeds again.
+func (p *KeyProvider) Expired() bool {
+	if p == nil {
+		return false
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rejected
+}
+
+// Info returns a redacted description of the current key.
+func (p *KeyProvider) Info() KeyInfo {
+	if p == nil {
+		return KeyInfo{}
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return KeyInfo{
+		Set:       p.key != "",
+		Hint:      keyHint(p.key),
+		Source:    p.source,
+		UpdatedAt: p.updatedAt,
+		Expired:   p.rejected,
+	}
+}
+
+// observe records Riot's verdict on key. Verdicts on a key that has since been
+// replaced are ignored, so a slow request cannot flag a fresh key as expired.
+func (p *KeyProvider) observe(key string, rejected bool) {
+	if p == nil || key == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key != p.key || rejected == p.rejected {
+		return
+	}
+	p.rejected = rejected
+	if p.Logger == nil {
+		return
+	}
+	if rejected {
+		p.Logger.Error("Riot rejected the API key; it has probably expired", "hint", keyHint(key))
+	} else {
+		p.Logger.Info("Riot accepted the API key again", "hint", keyHint(key))
+	}
+}
+
+// WatchFile calls Reload on its first check and then whenever path's size or
+// modification time changes, checking every interval until ctx is done.
+// Mounted Kubernetes secrets are replaced by swapping a symlink, which os.Stat
+// follows.
+func (p *KeyProvider) WatchFile(ctx context.Context, path string, interval time.Duration) {
+	var last os.FileInfo
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		fi, err := os.Stat(path)
+		if err != nil || (last != nil && fi.Size() == last.Size() && fi.ModTime().Equal(last.ModTime())) {
+			continue
+		}
+		last = fi
+		if _, err := p.Reload("key file"); err != nil && p.Logger != nil {
+			p.Logger.Error("Reloading Riot API key failed", "path", path, "error", err)
+		}
+	}
+}
+
+// keyHint returns the last four characters of key, enough to tell keys apart
+// on the status page and in logs.
+func keyHint(key string) string {
+	if len(key) <= 4 {
+		return ""
+	}
+	return "…" + key[len(key)-4:]
+}
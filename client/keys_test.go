@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestKeyProvider_SetAndInfo(t *testing.T) {
+	p := NewKeyProvider("RGAPI-old-aaaa", nil)
+	if p.Key() != "RGAPI-old-aaaa" || p.Info().Source != "config" {
+		t.Fatalf("initial state: %q %+v", p.Key(), p.Info())
+	}
+	if p.Set("RGAPI-old-aaaa", "admin") {
+		t.Error("setting the same key should report no change")
+	}
+	if !p.Set(" RGAPI-new-bbbb\n", "admin") {
+		t.Error("setting a new key should report a change")
+	}
+	info := p.Info()
+	if p.Key() != "RGAPI-new-bbbb" || info.Hint != "…bbbb" || info.Source != "admin" || !info.Set {
+		t.Errorf("after Set: key %q, info %+v", p.Key(), info)
+	}
+
+	var nilProvider *KeyProvider
+	if nilProvider.Key() != "" || nilProvider.Expired() || nilProvider.Info().Set {
+		t.Error("a nil provider should have no key")
+	}
+	if changed, err := nilProvider.Reload("test"); changed || err != nil {
+		t.Errorf("nil Reload() = %v, %v", changed, err)
+	}
+}
+
+func TestKeyProvider_Reload(t *testing.T) {
+	next, loadErr := "RGAPI-rotated", error(nil)
+	p := NewKeyProvider("RGAPI-old", func() (string, error) { return next, loadErr })
+
+	if changed, err := p.Reload("SIGHUP"); !changed || err != nil || p.Key() != "RGAPI-rotated" {
+		t.Errorf("Reload() = %v, %v; key %q", changed, err, p.Key())
+	}
+	if changed, _ := p.Reload("SIGHUP"); changed {
+		t.Error("reloading an unchanged key should report no change")
+	}
+	loadErr = errors.New("config unreadable")
+	if _, err := p.Reload("SIGHUP"); err == nil || p.Key() != "RGAPI-rotated" {
+		t.Errorf("failed Reload() = %v; key %q should be kept", err, p.Key())
+	}
+}
+
+func TestKeyProvider_Expiry(t *testing.T) {
+	p := NewKeyProvider("RGAPI-old", nil)
+	p.observe("RGAPI-old", true)
+	if !p.Expired() {
+		t.Fatal("a rejection of the current key should mark it expired")
+	}
+	p.observe("RGAPI-old", false)
+	if p.Expired() {
+		t.Error("a success with the current key should clear expiry")
+	}
+
+	p.observe("RGAPI-old", true)
+	p.Set("RGAPI-new", "admin")
+	if p.Expired() {
+		t.Error("a new key should clear expiry")
+	}
+	p.observe("RGAPI-old", true)
+	if p.Expired() {
+		t.Error("a late rejection of the replaced key should be ignored")
+	}
+}
+
+func TestDoJSON_TracksKeyExpiry(t *testing.T) {
+	status := http.StatusForbidden
+	c := &Client{
+		HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if got := req.Header.Get("X-Riot-Token"); got != "RGAPI-current" {
+				t.Errorf("X-Riot-Token: got %q", got)
+			}
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+		})},
+		Logger: log.New(io.Discard),
+		Keys:   NewKeyProvider("RGAPI-current", nil),
+	}
+
+	if _, err := c.FetchSummonerByPUUID(context.Background(), "p1", "na1"); !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("got %v, want ErrPermissionDenied", err)
+	}
+	if !c.Keys.Expired() {
+		t.Fatal("403 should mark the key expired")
+	}
+
+	status = http.StatusOK
+	if _, err := c.FetchSummonerByPUUID(context.Background(), "p2", "na1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Keys.Expired() {
+		t.Error("a successful call should clear expiry")
+	}
+}
+
+func TestKeyProvider_WatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "riot_api_key")
+	if err := os.WriteFile(path, []byte("RGAPI-old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p := NewKeyProvider("RGAPI-old", func() (string, error) {
+		b, err := os.ReadFile(path)
+		return strings.TrimSpace(string(b)), err
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.WatchFile(ctx, path, 5*time.Millisecond)
+		close(done)
+	}()
+
+	if err := os.WriteFile(path, []byte("RGAPI-rotated-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for p.Key() != "RGAPI-rotated-key" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if p.Key() != "RGAPI-rotated-key" {
+		t.Errorf("key not reloaded from file: %q", p.Key())
+	}
+	if info := p.Info(); info.Source != "key file" {
+		t.Errorf("source: got %q, want key file", info.Source)
+	}
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/cache"
+	"github.com/klnstprx/lolMatchup/metrics"
+	"github.com/klnstprx/lolMatchup/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// responseKeyPrefix namespaces cached responses within the KV store.
+const responseKeyPrefix = "resp:"
+
+// ResponseTTLs sets how long each kind of player data is served from the
+// response cache before it is fetched again. A zero TTL disables caching for
+// that kind.
+type ResponseTTLs struct {
+	Account  time.Duration // account by Riot ID
+	Summoner time.Duration // summoner by PUUID
+	League   time.Duration // ranked league entries
+	MatchIDs time.Duration // match ID lists, including "Load More" pages
+
+	// StaleWhileRevalidate is how long past its TTL an entry may still be
+	// served while a background request refreshes it.
+	StaleWhileRevalidate time.Duration
+}
+
+// ResponseCache keeps recent upstream responses in a cache.KV so repeated
+// player lookups don't hit the Riot API every time.
+type ResponseCache struct {
+	KV   cache.KV
+	TTLs ResponseTTLs
+
+	refreshing sync.Map // cache key -> struct{}, background refreshes in flight
+}
+
+// cachedResponse is the stored form of a response, with its freshness metadata.
+type cachedResponse struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// ttl returns the freshness lifetime for ep, or zero if ep is not cached.
+func (rc *ResponseCache) ttl(ep endpoint) time.Duration {
+	if rc == nil || rc.KV == nil {
+		return 0
+	}
+	switch ep {
+	case epAccountByRiotID:
+		return rc.TTLs.Account
+	case epSummonerByPUUID:
+		return rc.TTLs.Summoner
+	case epLeagueEntries:
+		return rc.TTLs.League
+	case epMatchIDs:
+		return rc.TTLs.MatchIDs
+	}
+	return 0
+}
+
+type forceRefreshKey struct{}
+
+// WithForceRefresh returns a context under which cached responses are ignored
+// and replaced by fresh upstream data.
+func WithForceRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceRefreshKey{}, true)
+}
+
+func forceRefresh(ctx context.Context) bool {
+	force, _ := ctx.Value(forceRefreshKey{}).(bool)
+	return force
+}
+
+// DataAge collects the fetch time of the responses served under a context
+// returned by TrackDataAge.
+type DataAge struct {
+	mu     sync.Mutex
+	oldest time.Time
+	stale  bool
+}
+
+type dataAgeKey struct{}
+
+// TrackDataAge returns a context that records, in the returned DataAge, when
+// the data served by client calls made with it was fetched upstream.
+func TrackDataAge(ctx context.Context) (context.Context, *DataAge) {
+	age := &DataAge{}
+	return context.WithValue(ctx, dataAgeKey{}, age), age
+}
+
+// FetchedAt returns the fetch time of the oldest response served, or the zero
+// time if nothing was recorded.
+func (a *DataAge) FetchedAt() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.oldest
+}
+
+// Stale reports whether any response was served past its TTL.
+func (a *DataAge) Stale() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.stale
+}
+
+func noteDataAge(ctx context.Context, fetchedAt time.Time, stale bool) {
+	a, ok := ctx.Value(dataAgeKey{}).(*DataAge)
+	if !ok {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.oldest.IsZero() || fetchedAt.Before(a.oldest) {
+		a.oldest = fetchedAt
+	}
+	a.stale = a.stale || stale
+}
+
+// cachedJSON is doJSON behind the response cache. Fresh entries are served
+// directly; entries past their TTL but within the stale window are served
+// while a background request refreshes them. Misses, expired entries and
+// forced refreshes go upstream and store the result. Endpoints without a TTL
+// bypass the cache. Errors are never cached.
+func (c *Client) cachedJSON(ctx context.Context, ep endpoint, url, riotAPIKey string, target interface{}) error {
+	rc := c.Responses
+	ttl := rc.ttl(ep)
+	if ttl <= 0 {
+		if err := c.doJSON(ctx, ep, url, riotAPIKey, target); err != nil {
+			return err
+		}
+		noteDataAge(ctx, time.Now(), false)
+		return nil
+	}
+
+	key := responseKeyPrefix + ep.op + ":" + url
+	if !forceRefresh(ctx) {
+		if entry, ok := c.loadResponse(ctx, key); ok {
+			age := time.Since(entry.FetchedAt)
+			if err := json.Unmarshal(entry.Body, target); err == nil {
+				stale := age >= ttl
+				result := "hit"
+				if stale {
+					result = "stale"
+					c.revalidate(ctx, ep, key, url, riotAPIKey)
+				}
+				metrics.CacheLookups.WithLabelValues("response", result).Inc()
+				noteDataAge(ctx, entry.FetchedAt, stale)
+				return nil
+			}
+		}
+	}
+	metrics.CacheLookups.WithLabelValues("response", "miss").Inc()
+
+	body, err := c.fetchAndStore(ctx, ep, key, url, riotAPIKey)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	noteDataAge(ctx, time.Now(), false)
+	return nil
+}
+
+// loadResponse reads a cached entry; backend errors count as a miss.
+func (c *Client) loadResponse(ctx context.Context, key string) (cachedResponse, bool) {
+	var entry cachedResponse
+	ctx, span := tracing.Start(ctx, "cache.response", attribute.String("cache.key", key))
+	defer span.End()
+	raw, ok, err := c.Responses.KV.Get(ctx, key)
+	if err != nil {
+		c.Logger.Warn("response cache read failed", "key", key, "error", err)
+		return entry, false
+	}
+	if !ok || json.Unmarshal(raw, &entry) != nil {
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+		return entry, false
+	}
+	span.SetAttributes(attribute.Bool("cache.hit", true))
+	return entry, true
+}
+
+// fetchAndStore fetches url upstream and stores the raw response under key.
+// The entry is kept for its TTL plus the stale window.
+func (c *Client) fetchAndStore(ctx context.Context, ep endpoint, key, url, riotAPIKey string) (json.RawMessage, error) {
+	var body json.RawMessage
+	if err := c.doJSON(ctx, ep, url, riotAPIKey, &body); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(cachedResponse{FetchedAt: time.Now().UTC(), Body: body})
+	if err == nil {
+		keep := c.Responses.ttl(ep) + c.Responses.TTLs.StaleWhileRevalidate
+		err = c.Responses.KV.Set(ctx, key, raw, keep)
+	}
+	if err != nil {
+		c.Logger.Warn("response cache write failed", "key", key, "error", err)
+	}
+	return body, nil
+}
+
+// revalidate refreshes a stale entry in the background, at most once at a
+// time per key. The refresh outlives the request that triggered it.
+func (c *Client) revalidate(ctx context.Context, ep endpoint, key, url, riotAPIKey string) {
+	if _, busy := c.Responses.refreshing.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer c.Responses.refreshing.Delete(key)
+		if _, err := c.fetchAndStore(ctx, ep, key, url, riotAPIKey); err != nil {
+			c.Logger.Debug("background refresh failed", "op", ep.op, "error", err)
+		}
+	}()
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/cache"
+)
+
+// countingTransport answers every request with body and counts the calls.
+type countingTransport struct {
+	calls atomic.Int32
+	body  atomic.Value // string
+}
+
+func (ct *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ct.calls.Add(1)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(ct.body.Load().(string))),
+	}, nil
+}
+
+func newCachingClient(body string, ttls ResponseTTLs) (*Client, *countingTransport, cache.KV) {
+	ct := &countingTransport{}
+	ct.body.Store(body)
+	kv := cache.NewMemoryBackend()
+	return &Client{
+		HTTPClient: &http.Client{Transport: ct},
+		Logger:     log.New(io.Discard),
+		Responses:  &ResponseCache{KV: kv, TTLs: ttls},
+	}, ct, kv
+}
+
+// seedResponse stores body under the cache key for the summoner endpoint as if
+// it had been fetched at fetchedAt.
+func seedResponse(t *testing.T, kv cache.KV, url, body string, fetchedAt time.Time) {
+	t.Helper()
+	raw, err := json.Marshal(cachedResponse{FetchedAt: fetchedAt, Body: json.RawMessage(body)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := responseKeyPrefix + epSummonerByPUUID.op + ":" + url
+	if err := kv.Set(context.Background(), key, raw, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const summonerURL = "https://na1.api.riotgames.com/lol/summoner/v4/summoners/by-puuid/p1"
+
+func TestResponseCache_FreshHit(t *testing.T) {
+	c, ct, _ := newCachingClient(`{"puuid":"p1","summonerLevel":30}`, ResponseTTLs{Summoner: time.Minute})
+
+	for range 3 {
+		summ, err := c.FetchSummonerByPUUID(context.Background(), "p1", "na1")
+		if err != nil {
+			t.Fatalf("FetchSummonerByPUUID() error: %v", err)
+		}
+		if summ.SummonerLevel != 30 {
+			t.Errorf("SummonerLevel: got %d, want 30", summ.SummonerLevel)
+		}
+	}
+	if n := ct.calls.Load(); n != 1 {
+		t.Errorf("upstream calls: got %d, want 1", n)
+	}
+}
+
+func TestResponseCache_StaleWhileRevalidate(t *testing.T) {
+	c, ct, _ := newCachingClient(`{"puuid":"p1","summonerLevel":31}`,
+		ResponseTTLs{Summoner: time.Minute, StaleWhileRevalidate: time.Hour})
+	fetchedAt := time.Now().Add(-5 * time.Minute).UTC()
+	seedResponse(t, c.Responses.KV, summonerURL, `{"puuid":"p1","summonerLevel":30}`, fetchedAt)
+
+	ctx, age := TrackDataAge(context.Background())
+	summ, err := c.FetchSummonerByPUUID(ctx, "p1", "na1")
+	if err != nil {
+		t.Fatalf("FetchSummonerByPUUID() error: %v", err)
+	}
+	if summ.SummonerLevel != 30 {
+		t.Errorf("stale entry should be served first, got level %d", summ.SummonerLevel)
+	}
+	if !age.Stale() || !age.FetchedAt().Equal(fetchedAt) {
+		t.Errorf("DataAge = %v (stale %v), want %v (stale)", age.FetchedAt(), age.Stale(), fetchedAt)
+	}
+
+	// The background refresh replaces the entry with the new response.
+	deadline := time.Now().Add(time.Second)
+	for {
+		summ, err = c.FetchSummonerByPUUID(context.Background(), "p1", "na1")
+		if err == nil && summ.SummonerLevel == 31 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("entry was not refreshed in the background (level %d, err %v)", summ.SummonerLevel, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := ct.calls.Load(); n != 1 {
+		t.Errorf("upstream calls: got %d, want 1", n)
+	}
+}
+
+func TestResponseCache_ForceRefresh(t *testing.T) {
+	c, ct, _ := newCachingClient(`{"puuid":"p1","summonerLevel":31}`, ResponseTTLs{Summoner: time.Hour})
+	seedResponse(t, c.Responses.KV, summonerURL, `{"puuid":"p1","summonerLevel":30}`, time.Now())
+
+	ctx, age := TrackDataAge(WithForceRefresh(context.Background()))
+	before := time.Now()
+	summ, err := c.FetchSummonerByPUUID(ctx, "p1", "na1")
+	if err != nil {
+		t.Fatalf("FetchSummonerByPUUID() error: %v", err)
+	}
+	if summ.SummonerLevel != 31 || ct.calls.Load() != 1 {
+		t.Errorf("forced refresh should go upstream: level %d, calls %d", summ.SummonerLevel, ct.calls.Load())
+	}
+	if age.FetchedAt().Before(before) || age.Stale() {
+		t.Errorf("DataAge should report a fresh fetch, got %v (stale %v)", age.FetchedAt(), age.Stale())
+	}
+
+	// The refreshed entry is now served from the cache.
+	summ, _ = c.FetchSummonerByPUUID(context.Background(), "p1", "na1")
+	if summ.SummonerLevel != 31 || ct.calls.Load() != 1 {
+		t.Errorf("refreshed entry not cached: level %d, calls %d", summ.SummonerLevel, ct.calls.Load())
+	}
+}
+
+func TestResponseCache_ZeroTTLBypasses(t *testing.T) {
+	c, ct, _ := newCachingClient(`{"puuid":"p1"}`, ResponseTTLs{Account: time.Minute})
+
+	for range 2 {
+		if _, err := c.FetchSummonerByPUUID(context.Background(), "p1", "na1"); err != nil {
+			t.Fatalf("FetchSummonerByPUUID() error: %v", err)
+		}
+	}
+	if n := ct.calls.Load(); n != 2 {
+		t.Errorf("upstream calls: got %d, want 2", n)
+	}
+}
+
+func TestResponseCache_ErrorsNotCached(t *testing.T) {
+	c := newTestClient(fakeTransport{resp: &http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       io.NopCloser(strings.NewReader("")),
+	}})
+	kv := cache.NewMemoryBackend()
+	c.Responses = &ResponseCache{KV: kv, TTLs: ResponseTTLs{Summoner: time.Minute}}
+
+	if _, err := c.FetchSummonerByPUUID(context.Background(), "p1", "na1"); err == nil {
+		t.Fatal("expected an error")
+	}
+	key := responseKeyPrefix + epSummonerByPUUID.op + ":" + summonerURL
+	if _, ok, _ := kv.Get(context.Background(), key); ok {
+		t.Error("error response should not be cached")
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed upstream GETs are retried. The zero value
+// makes a single attempt.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per request, including the
+	// first; values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; each retry doubles it.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A Retry-After longer than MaxDelay is not
+	// waited out: the 429 is returned to the caller instead.
+	MaxDelay time.Duration
+}
+
+// retryable reports whether a failed attempt is worth repeating: the request
+// never got a response, or the upstream answered 429 or a transient 5xx.
+func retryable(status int, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	switch status {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns how long to wait before the attempt following attempt
+// (1-based), or false when no further attempt should be made. The delay is
+// drawn uniformly from [0, min(MaxDelay, BaseDelay*2^(attempt-1))] ("full
+// jitter"); a Retry-After from the upstream is a lower bound.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+	if p.MaxDelay > 0 && retryAfter > p.MaxDelay {
+		return 0, false
+	}
+	ceiling := p.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || (p.MaxDelay > 0 && ceiling > p.MaxDelay) {
+		ceiling = p.MaxDelay
+	}
+	var wait time.Duration
+	if ceiling > 0 {
+		wait = rand.N(ceiling + 1)
+	}
+	return max(wait, retryAfter), true
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds. Riot never
+// sends the HTTP-date form, so it is not supported.
+func parseRetryAfter(h http.Header) time.Duration {
+	secs, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// IsDegraded reports whether err means an upstream API is unavailable or
+// overloaded rather than that the requested data does not exist: the circuit
+// for its host is open, it answered 429 or 5xx after retries, or it could not
+// be reached. Handlers use it to show a degraded state instead of partial data.
+func IsDegraded(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
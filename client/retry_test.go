@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedTransport answers requests with statuses in order, repeating the
+// last one; a status of 0 fails the request with a network error.
+type scriptedTransport struct {
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	calls    int
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	status := s.statuses[min(s.calls, len(s.statuses)-1)]
+	s.calls++
+	s.mu.Unlock()
+	if status == 0 {
+		return nil, errors.New("connection reset")
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     s.header.Clone(),
+		Body:       io.NopCloser(strings.NewReader(`["15.1.1"]`)),
+	}, nil
+}
+
+func (s *scriptedTransport) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func newRetryClient(tr *scriptedTransport, p RetryPolicy) *Client {
+	c := newTestClient(fakeTransport{})
+	c.HTTPClient = &http.Client{Transport: tr}
+	c.Retry = p
+	return c
+}
+
+func TestFetchRetries(t *testing.T) {
+	fast := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	tests := []struct {
+		name      string
+		statuses  []int
+		policy    RetryPolicy
+		wantErr   bool
+		wantCalls int
+	}{
+		{"success needs no retry", []int{200}, fast, false, 1},
+		{"503 then success", []int{503, 200}, fast, false, 2},
+		{"network error then success", []int{0, 200}, fast, false, 2},
+		{"429 then success", []int{429, 429, 200}, fast, false, 3},
+		{"gives up after max attempts", []int{500}, fast, true, 3},
+		{"404 is not retried", []int{404}, fast, true, 1},
+		{"403 is not retried", []int{403}, fast, true, 1},
+		{"zero policy makes one attempt", []int{503, 200}, RetryPolicy{}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &scriptedTransport{statuses: tt.statuses}
+			c := newRetryClient(tr, tt.policy)
+			_, err := c.FetchLatestPatch(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := tr.Calls(); got != tt.wantCalls {
+				t.Errorf("upstream calls: got %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFetchRetryHonorsRetryAfterAndDeadline(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Second}
+
+	// A Retry-After longer than the caller's deadline is not waited out.
+	tr := &scriptedTransport{statuses: []int{429, 200}, header: http.Header{"Retry-After": {"5"}}}
+	c := newRetryClient(tr, p)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := c.FetchLatestPatch(ctx)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("got %v, want the 429", err)
+	}
+	if tr.Calls() != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("should fail fast without retrying: %d calls in %v", tr.Calls(), time.Since(start))
+	}
+
+	// A Retry-After longer than MaxDelay is not waited out either.
+	p.MaxDelay = 100 * time.Millisecond
+	tr = &scriptedTransport{statuses: []int{429, 200}, header: http.Header{"Retry-After": {"5"}}}
+	if _, err := newRetryClient(tr, p).FetchLatestPatch(context.Background()); err == nil || tr.Calls() != 1 {
+		t.Errorf("got %v after %d calls, want the 429 after 1", err, tr.Calls())
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 4, BaseDelay: 100 * time.Millisecond, MaxDelay: 250 * time.Millisecond}
+	for attempt, ceiling := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 250 * time.Millisecond} {
+		for range 50 {
+			wait, ok := p.backoff(attempt, 0)
+			if !ok || wait < 0 || wait > ceiling {
+				t.Fatalf("backoff(%d) = %v, %v; want within [0, %v]", attempt, wait, ok, ceiling)
+			}
+		}
+	}
+	if _, ok := p.backoff(4, 0); ok {
+		t.Error("backoff after the last attempt should stop")
+	}
+	if wait, _ := p.backoff(1, 200*time.Millisecond); wait < 200*time.Millisecond {
+		t.Errorf("Retry-After should be a lower bound, got %v", wait)
+	}
+}
+
+func TestIsDegraded(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{ErrAccountNotFound, false},
+		{ErrPermissionDenied, false},
+		{context.Canceled, false},
+		{&APIError{StatusCode: 404}, false},
+		{&APIError{StatusCode: 429}, true},
+		{&APIError{StatusCode: 503}, true},
+		{ErrCircuitOpen, true},
+	}
+	for _, tt := range tests {
+		if got := IsDegraded(tt.err); got != tt.want {
+			t.Errorf("IsDegraded(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+
+	tr := &scriptedTransport{statuses: []int{0}}
+	_, err := newRetryClient(tr, RetryPolicy{}).FetchLatestPatch(context.Background())
+	if !IsDegraded(err) {
+		t.Errorf("network error %v should be degraded", err)
+	}
+}
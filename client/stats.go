@@ -0,0 +1,94 @@
+package client
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// recentWindow is how far back RecentErrorRates looks.
+const recentWindow = 5 * time.Minute
+
+// callRecord is a single upstream call outcome kept for error-rate reporting.
+type callRecord struct {
+	at     time.Time
+	host   string
+	failed bool
+}
+
+// recentCalls keeps upstream call outcomes from the last recentWindow. The
+// zero value is ready to use.
+type recentCalls struct {
+	mu      sync.Mutex
+	records []callRecord
+}
+
+// add records one call outcome and drops records older than the window.
+func (r *recentCalls) add(now time.Time, host string, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pruneLocked(now)
+	r.records = append(r.records, callRecord{at: now, host: host, failed: failed})
+}
+
+// pruneLocked discards records that have fallen out of the window. Records are
+// appended in time order, so the expired ones form a prefix.
+func (r *recentCalls) pruneLocked(now time.Time) {
+	cutoff := now.Add(-recentWindow)
+	i := 0
+	for i < len(r.records) && r.records[i].at.Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		r.records = append(r.records[:0], r.records[i:]...)
+	}
+}
+
+// HostErrorRate summarizes recent upstream calls to one host.
+type HostErrorRate struct {
+	Host   string  `json:"host"`
+	Total  int     `json:"total"`
+	Errors int     `json:"errors"`
+	Rate   float64 `json:"rate"`
+}
+
+// RecentErrorRates returns per-host call and error counts over the last five
+// minutes, sorted by host. A call counts as an error when the request failed
+// outright or the upstream answered with 401, 403, 429 or a 5xx status; 404s
+// are normal (e.g. player not in a game) and are not counted.
+func (c *Client) RecentErrorRates() []HostErrorRate {
+	c.recent.mu.Lock()
+	c.recent.pruneLocked(time.Now())
+	byHost := make(map[string]*HostErrorRate)
+	for _, rec := range c.recent.records {
+		h, ok := byHost[rec.host]
+		if !ok {
+			h = &HostErrorRate{Host: rec.host}
+			byHost[rec.host] = h
+		}
+		h.Total++
+		if rec.failed {
+			h.Errors++
+		}
+	}
+	c.recent.mu.Unlock()
+
+	rates := make([]HostErrorRate, 0, len(byHost))
+	for _, h := range byHost {
+		h.Rate = float64(h.Errors) / float64(h.Total)
+		rates = append(rates, *h)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Host < rates[j].Host })
+	return rates
+}
+
+// isUpstreamFailure reports whether a response status indicates a problem with
+// the upstream or our credentials rather than a normal "not found" answer.
+func isUpstreamFailure(status int) bool {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return true
+	}
+	return status >= http.StatusInternalServerError
+}
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRecentCalls(t *testing.T) {
+	var c Client
+	now := time.Now()
+
+	c.recent.add(now.Add(-10*time.Minute), "old.test", true)
+	c.recent.add(now.Add(-time.Minute), "a.test", false)
+	c.recent.add(now.Add(-time.Minute), "a.test", true)
+	c.recent.add(now, "b.test", false)
+
+	got := c.RecentErrorRates()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 hosts (expired record dropped), got %+v", got)
+	}
+	if got[0].Host != "a.test" || got[0].Total != 2 || got[0].Errors != 1 || got[0].Rate != 0.5 {
+		t.Errorf("a.test: got %+v", got[0])
+	}
+	if got[1].Host != "b.test" || got[1].Errors != 0 || got[1].Rate != 0 {
+		t.Errorf("b.test: got %+v", got[1])
+	}
+}
+
+func TestIsUpstreamFailure(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusNotFound, false},
+		{http.StatusUnauthorized, true},
+		{http.StatusForbidden, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusBadGateway, true},
+	}
+	for _, tt := range tests {
+		if got := isUpstreamFailure(tt.status); got != tt.want {
+			t.Errorf("isUpstreamFailure(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
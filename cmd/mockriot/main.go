@@ -0,0 +1,87 @@
+// Command mockriot runs the fixture-backed Riot API mock (package mockriot) as
+// a standalone server for local development.
+//
+// Usage:
+//
+//	go run ./cmd/mockriot [-addr 127.0.0.1:9090] [-scenario ok] [-latency 0s] [-api-key KEY]
+//
+// Then set in config.toml:
+//
+//	riot_api_base_url = "http://localhost:9090"
+//	meraki_url = "http://localhost:9090/meraki/"
+//	ddragon_version_url = "http://localhost:9090/api/versions.json"
+//
+// The scenario can be switched at runtime with PUT /_mock/scenario/{name}, and
+// spectator games started or ended with PUT or DELETE /_mock/games/{puuid}.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/mockriot"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:9090", "listen address")
+	scenario := flag.String("scenario", "ok", "fault scenario: "+strings.Join(mockriot.ScenarioNames(), ", "))
+	latency := flag.Duration("latency", 0, "extra latency added to every response (overrides the scenario's)")
+	apiKey := flag.String("api-key", "", "require this X-Riot-Token on Riot requests")
+	flag.Parse()
+
+	logger := log.New(os.Stderr)
+	logger.SetReportTimestamp(true)
+
+	sc, ok := mockriot.Scenarios[*scenario]
+	if !ok {
+		logger.Fatalf("unknown scenario %q (available: %s)", *scenario, strings.Join(mockriot.ScenarioNames(), ", "))
+	}
+	if *latency > 0 {
+		sc.Latency = *latency
+	}
+
+	srv := mockriot.New()
+	srv.APIKey = *apiKey
+	srv.SetScenario(sc)
+
+	base := "http://" + *addr
+	logger.Info("Mock Riot API listening", "addr", base, "scenario", sc.Name, "description", sc.Description)
+	fmt.Fprintf(os.Stderr, "\nSet in config.toml:\n  riot_api_base_url = %q\n  meraki_url = %q\n  ddragon_version_url = %q\n\n",
+		base, base+mockriot.MerakiPath, base+mockriot.DDragonVersionsPath)
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           logRequests(logger, srv),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Fatal("server error", "error", err)
+	}
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// logRequests logs each request with its status and duration.
+func logRequests(logger *log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		logger.Info(r.Method+" "+r.URL.RequestURI(), "status", rec.status, "duration", time.Since(start).Round(time.Millisecond))
+	})
+}
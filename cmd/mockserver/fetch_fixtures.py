@@ -21,7 +21,8 @@ import urllib.error
 import urllib.request
 from pathlib import Path
 
-FIXTURES = Path(__file__).parent / "fixtures"
+# Shared with the Go mock server (package mockriot), which embeds them.
+FIXTURES = Path(__file__).resolve().parents[2] / "mockriot" / "fixtures"
 
 REGION_TO_CLUSTER = {
     "na1": "americas", "br1": "americas", "la1": "americas", "la2": "americas",
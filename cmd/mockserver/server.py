@@ -29,7 +29,8 @@ from flask import Flask, jsonify, request
 
 app = Flask(__name__)
 
-FIXTURES_DIR = Path(__file__).parent / "fixtures"
+# Shared with the Go mock server (package mockriot), which embeds them.
+FIXTURES_DIR = Path(__file__).resolve().parents[2] / "mockriot" / "fixtures"
 
 
 def load_fixture(name: str) -> dict:
@@ -129,6 +130,18 @@ def match_by_id(match_id: str):
     return jsonify(match)
 
 
+@app.route("/lol/status/v4/platform-data")
+def platform_data():
+    # Cheap endpoint used by /debug/status to probe API key validity.
+    return jsonify({
+        "id": "NA1",
+        "name": "North America",
+        "locales": ["en_US"],
+        "maintenances": [],
+        "incidents": [],
+    })
+
+
 if __name__ == "__main__":
     parser = argparse.ArgumentParser(description="Riot API Mock Server")
     parser.add_argument("--port", type=int, default=9090, help="Port to listen on")
@@ -0,0 +1,92 @@
+// Command snapshot refreshes the offline champion snapshot bundled into the
+// binary (data/snapshot.json.gz), which the app falls back to when it starts
+// without network access and without a cache file.
+//
+// Usage:
+//
+//	go run ./cmd/snapshot [-out data/snapshot.json.gz] [-base-url URL]
+//
+// or `make snapshot`. By default data comes from Data Dragon and Meraki;
+// -base-url sends every request to another server instead, such as a running
+// cmd/mockriot, and then needs an -out other than the bundled snapshot.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/client"
+	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/data"
+	"github.com/klnstprx/lolMatchup/mockriot"
+)
+
+func main() {
+	out := flag.String("out", data.SnapshotPath, "output file")
+	baseURL := flag.String("base-url", "", "send every request to this server instead of Data Dragon and Meraki")
+	timeout := flag.Duration("timeout", time.Minute, "overall timeout")
+	flag.Parse()
+
+	logger := log.New(os.Stderr)
+	defaults := config.New()
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	c := &client.Client{
+		HTTPClient:        httpClient,
+		Logger:            logger,
+		ChampionDataURL:   defaults.MerakiURL,
+		DDragonVersionURL: defaults.DDragonVersionURL,
+	}
+	if *baseURL != "" {
+		// The bundled snapshot must hold real display names and the full
+		// roster, which a mock server does not have.
+		if *out == data.SnapshotPath {
+			logger.Fatal("refusing to overwrite the bundled snapshot with data from -base-url; pass -out")
+		}
+		base, err := url.Parse(*baseURL)
+		if err != nil || base.Host == "" {
+			logger.Fatalf("invalid -base-url %q", *baseURL)
+		}
+		// Summoner spells always come from the Data Dragon CDN host, so
+		// rewrite hosts rather than only overriding the configured URLs.
+		httpClient.Transport = rewriteHost{base: base, next: http.DefaultTransport}
+		c = mockriot.ClientFor(*baseURL, httpClient)
+		c.Logger = logger
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	snap, err := data.BuildOfflineSnapshot(ctx, c)
+	if err != nil {
+		logger.Fatal("building snapshot failed", "error", err)
+	}
+
+	var buf bytes.Buffer
+	if err := snap.Write(&buf); err != nil {
+		logger.Fatal("encoding snapshot failed", "error", err)
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+		logger.Fatal("writing snapshot failed", "error", err)
+	}
+	logger.Info("Snapshot written", "path", *out, "patch", snap.Patch,
+		"champions", len(snap.ChampionMap), "spells", len(snap.SummonerSpells), "bytes", buf.Len())
+}
+
+// rewriteHost sends every request to base's scheme and host.
+type rewriteHost struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (t rewriteHost) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.URL.Scheme = t.base.Scheme
+	out.URL.Host = t.base.Host
+	out.Host = ""
+	return t.next.RoundTrip(out)
+}
@@ -0,0 +1,17 @@
+package components
+
+import "context"
+
+type keyExpiredKey struct{}
+
+// WithKeyExpired returns a context marking that Riot is rejecting the API key.
+// Full pages rendered with it show a banner saying so.
+func WithKeyExpired(ctx context.Context) context.Context {
+	return context.WithValue(ctx, keyExpiredKey{}, true)
+}
+
+// keyExpired reports whether WithKeyExpired marked ctx.
+func keyExpired(ctx context.Context) bool {
+	expired, _ := ctx.Value(keyExpiredKey{}).(bool)
+	return expired
+}
@@ -0,0 +1,18 @@
+package components
+
+import "context"
+
+type snapshotPatchKey struct{}
+
+// WithSnapshotPatch returns a context marking that champion data is being
+// served from the bundled offline snapshot for patch. Full pages rendered with
+// it show a banner saying so.
+func WithSnapshotPatch(ctx context.Context, patch string) context.Context {
+	return context.WithValue(ctx, snapshotPatchKey{}, patch)
+}
+
+// snapshotPatch returns the snapshot patch set by WithSnapshotPatch, or "".
+func snapshotPatch(ctx context.Context) string {
+	p, _ := ctx.Value(snapshotPatchKey{}).(string)
+	return p
+}
@@ -0,0 +1,24 @@
+package components
+
+import "context"
+
+// User describes the signed-in visitor for the page header.
+type User struct {
+	Name     string
+	Admin    bool
+	Accounts bool // signed in with a local account, which has an account page
+}
+
+type userKey struct{}
+
+// WithUser returns a context carrying the signed-in visitor. Full pages
+// rendered with it show who is signed in and a sign-out button.
+func WithUser(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+// currentUser returns the visitor set by WithUser.
+func currentUser(ctx context.Context) (User, bool) {
+	u, ok := ctx.Value(userKey{}).(User)
+	return u, ok
+}
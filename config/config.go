@@ -1,37 +1,143 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/charmbracelet/log"
 	"github.com/gin-gonic/gin"
 	"github.com/klnstprx/lolMatchup/cache"
+	"github.com/redis/go-redis/v9"
 )
 
 // AppConfig holds configuration options loaded from TOML or defaulted at runtime.
 type AppConfig struct {
 	ListenAddr           string `toml:"listen_addr"`
 	Port                 int    `toml:"port"`
-	PatchNumber          string `toml:"-"` // Set dynamically upon initialization
 	LanguageCode         string `toml:"language_code"`
 	LevenshteinThreshold int    `toml:"levenshtein_threshold"`
+	ChampionAliases      string `toml:"champion_aliases"` // extra search aliases as "alias=Champion, ..."
 	MerakiURL            string `toml:"meraki_url"`
 	DDragonVersionURL    string `toml:"ddragon_version_url"`
 	Debug                bool   `toml:"debug"`
 	HTTPClientTimeout    int    `toml:"http_client_timeout"`
 	CachePath            string `toml:"cache_path"`
+	PlayersPath          string `toml:"players_path"`        // Riot IDs seen, for player autocomplete; empty keeps them in memory
+	PlayersMax           int    `toml:"players_max"`         // Riot IDs kept before the least recently seen are dropped
+	MatchesPath          string `toml:"matches_path"`        // matches fetched, for matchup statistics; empty keeps them in memory
+	MatchesMax           int    `toml:"matches_max"`         // matches kept before the oldest are dropped
+	MatchupMinGames      int    `toml:"matchup_min_games"`   // lane games a matchup needs before its statistics are shown as reliable
+	TeamRiotIDs          string `toml:"team_riot_ids"`       // the team's players as "name#tag, ..."; empty counts every stored player
+	NotesPath            string `toml:"notes_path"`          // team matchup notes; empty keeps them in memory
+	ProfileMatches       int    `toml:"profile_matches"`     // recent matches the per-role player profile covers (at most 100)
+	PatchCheckMinutes    int    `toml:"patch_check_minutes"` // 0 disables the background patch watcher
+	WarmupConcurrency    int    `toml:"warmup_concurrency"`  // champions fetched at a time by the warm-up; 0 disables it
+	DebugToken           string `toml:"debug_token"`         // Guards /debug/status; empty disables it
+	MetricsPublic        bool   `toml:"metrics_public"`      // serve /metrics to anyone even when sign-in or debug_token guards the site
 
-	Logger     *log.Logger  `toml:"-"` // Exclude from TOML
-	Cache      *cache.Cache `toml:"-"`
-	HTTPClient *http.Client `toml:"-"`
+	// Access control
+	AuthMode      string `toml:"auth_mode"`      // none, accounts or invite
+	UsersFile     string `toml:"users_file"`     // local accounts for auth_mode accounts
+	InviteToken   string `toml:"invite_token"`   // shared sign-in secret for auth_mode invite
+	SessionSecret string `toml:"session_secret"` // signs session cookies; random per process if empty
+	SessionHours  int    `toml:"session_hours"`  // how long a sign-in lasts
+
+	// Cache backend configuration
+	CacheBackend  string `toml:"cache_backend"`  // memory or redis
+	RedisAddr     string `toml:"redis_addr"`     // host:port of the Redis server
+	RedisPassword string `toml:"redis_password"` // optional AUTH password
+	RedisDB       int    `toml:"redis_db"`       // database number
+	RedisPrefix   string `toml:"redis_prefix"`   // key namespace shared by all instances
+
+	// Player data response cache (seconds; 0 disables caching for that kind)
+	AccountCacheSeconds  int `toml:"account_cache_seconds"`   // Riot ID -> account lookups
+	SummonerCacheSeconds int `toml:"summoner_cache_seconds"`  // summoner profiles
+	LeagueCacheSeconds   int `toml:"league_cache_seconds"`    // ranked league entries
+	MatchIDsCacheSeconds int `toml:"match_ids_cache_seconds"` // match history ID lists
+	StaleCacheSeconds    int `toml:"stale_cache_seconds"`     // serve expired entries this long while refreshing
+
+	// Upstream resilience
+	RetryAttempts      int `toml:"retry_attempts"`       // total attempts per upstream GET; 1 disables retries
+	RetryBaseMillis    int `toml:"retry_base_ms"`        // backoff before the first retry, doubled per retry
+	RetryMaxMillis     int `toml:"retry_max_ms"`         // backoff cap; longer Retry-After waits are not retried
+	BreakerThreshold   int `toml:"breaker_threshold"`    // consecutive failures that open a host's circuit; 0 disables
+	BreakerOpenSeconds int `toml:"breaker_open_seconds"` // how long an open circuit fails fast before probing
+
+	// Rate limiting, in cost units (about one upstream Riot call each)
+	RateLimitPerMinute       int `toml:"rate_limit_per_minute"`        // shared budget for all clients; 0 disables
+	RateLimitBurst           int `toml:"rate_limit_burst"`             // shared budget bucket size
+	ClientRateLimitPerMinute int `toml:"client_rate_limit_per_minute"` // quota per IP or API token; 0 disables
+	ClientRateLimitBurst     int `toml:"client_rate_limit_burst"`      // per-client bucket size
+	RateLimitQueueMillis     int `toml:"rate_limit_queue_ms"`          // max wait for the shared budget; 0 rejects at once
+
+	// Tracing configuration
+	TracingExporter string `toml:"tracing_exporter"` // none, otlp, stdout or file
+	TracingEndpoint string `toml:"tracing_endpoint"` // OTLP/HTTP collector (host:port or URL)
+	TracingFile     string `toml:"tracing_file"`     // Output path for the file exporter
+
+	Logger     *log.Logger    `toml:"-"` // Exclude from TOML
+	Cache      *cache.Cache   `toml:"-"`
+	Players    *cache.Players `toml:"-"`
+	Matches    *cache.Matches `toml:"-"`
+	HTTPClient *http.Client   `toml:"-"`
 	// Riot API configuration
 	RiotAPIKey     string `toml:"riot_api_key"`
+	RiotAPIKeyFile string `toml:"riot_api_key_file"` // read the key from this file instead (e.g. a mounted secret)
 	RiotRegion     string `toml:"riot_region"`
 	RiotAPIBaseURL string `toml:"riot_api_base_url"`
+
+	patch         atomic.Value // current patch version (string), see Patch/SetPatch
+	snapshotPatch atomic.Value // patch of the bundled snapshot in use (string), see SnapshotPatch
+}
+
+// Patch returns the patch version currently being served. It is set at
+// startup and updated by the background patch watcher, so it is safe to call
+// concurrently.
+func (cfg *AppConfig) Patch() string {
+	p, _ := cfg.patch.Load().(string)
+	return p
+}
+
+// SetPatch updates the patch version being served.
+func (cfg *AppConfig) SetPatch(patch string) {
+	cfg.patch.Store(patch)
+}
+
+// SnapshotPatch returns the patch of the bundled offline snapshot while
+// champion data is being served from it, or "" once live data has replaced it.
+func (cfg *AppConfig) SnapshotPatch() string {
+	p, _ := cfg.snapshotPatch.Load().(string)
+	return p
+}
+
+// SetSnapshotPatch records that champion data comes from the bundled snapshot
+// for patch; "" records that it does not.
+func (cfg *AppConfig) SetSnapshotPatch(patch string) {
+	cfg.snapshotPatch.Store(patch)
+}
+
+// Team returns the Riot IDs listed in team_riot_ids.
+func (cfg *AppConfig) Team() []string {
+	var team []string
+	for _, id := range strings.Split(cfg.TeamRiotIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			team = append(team, id)
+		}
+	}
+	return team
+}
+
+// ProfileMatchCount returns profile_matches within 1 to 100, the most match
+// IDs Riot returns at once.
+func (cfg *AppConfig) ProfileMatchCount() int {
+	return min(max(cfg.ProfileMatches, 1), 100)
 }
 
 // New returns an AppConfig with default values.
@@ -46,7 +152,39 @@ func New() *AppConfig {
 		DDragonVersionURL:    "https://ddragon.leagueoflegends.com/api/versions.json",
 		LevenshteinThreshold: 3,
 		CachePath:            "cache.json",
+		PlayersPath:          "players.json",
+		PlayersMax:           cache.DefaultMaxPlayers,
+		MatchesPath:          "matches.json",
+		MatchesMax:           cache.DefaultMaxMatches,
+		MatchupMinGames:      5,
+		NotesPath:            "notes.json",
+		ProfileMatches:       20,
 		HTTPClientTimeout:    10,
+		PatchCheckMinutes:    30,
+		WarmupConcurrency:    4,
+		TracingExporter:      "none",
+		AuthMode:             "none",
+		UsersFile:            "users.json",
+		SessionHours:         168,
+		CacheBackend:         "memory",
+		RedisAddr:            "localhost:6379",
+		RedisPrefix:          "lolmatchup",
+		AccountCacheSeconds:  3600,
+		SummonerCacheSeconds: 300,
+		LeagueCacheSeconds:   120,
+		MatchIDsCacheSeconds: 60,
+		StaleCacheSeconds:    600,
+		RetryAttempts:        3,
+		RetryBaseMillis:      200,
+		RetryMaxMillis:       2000,
+		BreakerThreshold:     5,
+		BreakerOpenSeconds:   30,
+
+		RateLimitPerMinute:       1200,
+		RateLimitBurst:           200,
+		ClientRateLimitPerMinute: 300,
+		ClientRateLimitBurst:     120,
+		RateLimitQueueMillis:     5000,
 	}
 }
 
@@ -67,13 +205,27 @@ func (cfg *AppConfig) Validate(logger *log.Logger) {
 	if cfg.RiotAPIBaseURL != "" {
 		logger.Warnf("Using mock Riot API at %s", cfg.RiotAPIBaseURL)
 	}
+	if (cfg.AuthMode == "" || cfg.AuthMode == "none") && !loopback(cfg.ListenAddr) {
+		logger.Warnf("Listening on %s without authentication: anyone who can reach the server can spend the Riot API quota (set auth_mode)", cfg.ListenAddr)
+	}
+}
+
+// loopback reports whether addr only accepts local connections.
+func loopback(addr string) bool {
+	if addr == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.IsLoopback()
 }
 
 // Initialize sets up logger, gin mode, cache, and HTTP client.
 func (cfg *AppConfig) Initialize() error {
 	cfg.setLogger()
 	cfg.setGinMode()
-	cfg.setCache()
+	if err := cfg.setCache(); err != nil {
+		return err
+	}
 	cfg.setHTTPClient()
 	return nil
 }
@@ -95,6 +247,7 @@ func (cfg *AppConfig) Load(path string) error {
 }
 
 // LogConfig logs configuration keys and values as TOML after marshalling.
+// Secrets such as the Riot API key are logged as [redacted].
 func (cfg *AppConfig) LogConfig() {
 	tomlBytes, err := toml.Marshal(*cfg)
 	if err != nil {
@@ -123,6 +276,9 @@ func (cfg *AppConfig) logMap(prefix string, data map[string]interface{}) {
 		case map[string]interface{}:
 			cfg.logMap(fullKey, v)
 		default:
+			if secretKeys[fullKey] && v != "" {
+				v = Redacted
+			}
 			cfg.Logger.Infof("%s: %v", fullKey, v)
 		}
 	}
@@ -147,7 +303,39 @@ func (cfg *AppConfig) setLogger() {
 	cfg.Logger = logger
 }
 
-// setCache initializes the cache with config path and threshold.
-func (cfg *AppConfig) setCache() {
+// setCache initializes the cache with config path and threshold, backed by the
+// configured cache backend, the index of seen players and the match store.
+func (cfg *AppConfig) setCache() error {
 	cfg.Cache = cache.New(cfg.CachePath, cfg.LevenshteinThreshold)
+	cfg.Cache.Logger = cfg.Logger
+	aliases, err := cache.ParseAliases(cfg.ChampionAliases)
+	if err != nil {
+		return fmt.Errorf("champion_aliases: %w", err)
+	}
+	cfg.Cache.SetAliases(aliases)
+	cfg.Players = cache.NewPlayers(cfg.PlayersPath, cfg.PlayersMax)
+	cfg.Players.Logger = cfg.Logger
+	cfg.Matches = cache.NewMatches(cfg.MatchesPath, cfg.MatchesMax)
+	cfg.Matches.Logger = cfg.Logger
+
+	switch cfg.CacheBackend {
+	case "", "memory":
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisAddr,
+			Password: cfg.RedisPassword,
+			DB:       cfg.RedisDB,
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			client.Close()
+			return fmt.Errorf("connecting to redis at %s: %w", cfg.RedisAddr, err)
+		}
+		cfg.Cache.Backend = cache.NewRedisBackend(client, cfg.RedisPrefix)
+		cfg.Logger.Info("Using Redis cache backend", "addr", cfg.RedisAddr, "prefix", cfg.RedisPrefix)
+	default:
+		return fmt.Errorf("unknown cache_backend %q (use memory or redis)", cfg.CacheBackend)
+	}
+	return nil
 }
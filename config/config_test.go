@@ -1,9 +1,14 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/klnstprx/lolMatchup/cache"
 )
 
 func TestNew_Defaults(t *testing.T) {
@@ -116,6 +121,43 @@ func TestInitialize(t *testing.T) {
 	}
 }
 
+func TestInitialize_CacheBackend(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	tests := []struct {
+		name        string
+		backend     string
+		addr        string
+		wantErr     bool
+		wantBackend interface{}
+	}{
+		{"memory", "memory", "", false, &cache.MemoryBackend{}},
+		{"redis", "redis", mr.Addr(), false, &cache.RedisBackend{}},
+		{"redis unreachable", "redis", "127.0.0.1:1", true, nil},
+		{"unknown", "memcached", "", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.CachePath = filepath.Join(t.TempDir(), "cache.json")
+			cfg.CacheBackend = tt.backend
+			cfg.RedisAddr = tt.addr
+
+			err := cfg.Initialize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Initialize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer cfg.Cache.Close()
+			if got, want := fmt.Sprintf("%T", cfg.Cache.Backend), fmt.Sprintf("%T", tt.wantBackend); got != want {
+				t.Errorf("backend type: got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
 func TestValidate_MissingKey(t *testing.T) {
 	cfg := New()
 	cfg.Initialize()
@@ -138,3 +180,24 @@ func TestValidate_InvalidRegion(t *testing.T) {
 	// Should not panic
 	cfg.Validate(cfg.Logger)
 }
+
+func TestTeam(t *testing.T) {
+	cfg := New()
+	if got := cfg.Team(); len(got) != 0 {
+		t.Errorf("default Team() = %v, want none", got)
+	}
+	cfg.TeamRiotIDs = " Faker#KR1, ,Caps#EUW "
+	if got := cfg.Team(); !reflect.DeepEqual(got, []string{"Faker#KR1", "Caps#EUW"}) {
+		t.Errorf("Team() = %v", got)
+	}
+}
+
+func TestProfileMatchCount(t *testing.T) {
+	cfg := New()
+	for in, want := range map[int]int{0: 1, 20: 20, 250: 100} {
+		cfg.ProfileMatches = in
+		if got := cfg.ProfileMatchCount(); got != want {
+			t.Errorf("ProfileMatchCount() with %d = %d, want %d", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix prefixes the environment variable for each setting, which is
+// named after its TOML key in upper case, e.g. LOLMATCHUP_RIOT_API_KEY.
+const EnvPrefix = "LOLMATCHUP_"
+
+// Redacted replaces secret values in logged configuration.
+const Redacted = "[redacted]"
+
+// secretKeys are the settings whose values are never logged.
+var secretKeys = map[string]bool{
+	"riot_api_key":   true,
+	"redis_password": true,
+	"debug_token":    true,
+	"invite_token":   true,
+	"session_secret": true,
+}
+
+// setting is one configurable field of AppConfig, addressed by its TOML key.
+type setting struct {
+	key   string
+	field reflect.Value
+}
+
+// settings returns the string, int and bool fields of cfg that have a TOML
+// key, sorted by key.
+func (cfg *AppConfig) settings() []setting {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	var out []setting
+	for i := 0; i < t.NumField(); i++ {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		switch t.Field(i).Type.Kind() {
+		case reflect.String, reflect.Int, reflect.Bool:
+			out = append(out, setting{key: key, field: v.Field(i)})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].key < out[j].key })
+	return out
+}
+
+// set parses raw according to the field's type and stores it.
+func (s setting) set(raw string) error {
+	switch s.field.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%s: %q is not an integer", s.key, raw)
+		}
+		s.field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a boolean", s.key, raw)
+		}
+		s.field.SetBool(b)
+	default:
+		s.field.SetString(raw)
+	}
+	return nil
+}
+
+// EnvName returns the environment variable that sets the TOML key key.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+// LoadEnv applies LOLMATCHUP_* environment variables over the current
+// values. lookup is normally os.LookupEnv.
+func (cfg *AppConfig) LoadEnv(lookup func(string) (string, bool)) error {
+	for _, s := range cfg.settings() {
+		raw, ok := lookup(EnvName(s.key))
+		if !ok {
+			continue
+		}
+		if err := s.set(raw); err != nil {
+			return fmt.Errorf("environment variable %s: %w", EnvName(s.key), err)
+		}
+	}
+	return nil
+}
+
+// Overrides holds settings given on the command line. They are recorded while
+// flags are parsed and applied with Apply once the config file and
+// environment have been loaded, so flags take precedence over both.
+type Overrides struct {
+	keys   []string
+	values map[string]string
+}
+
+// BindFlags registers a flag on fs for every setting, named by its TOML key
+// (e.g. -riot_region euw1). Values are validated as they are parsed.
+func BindFlags(fs *flag.FlagSet) *Overrides {
+	o := &Overrides{values: make(map[string]string)}
+	for _, s := range New().settings() {
+		key, kind := s.key, s.field.Kind()
+		record := func(raw string) error {
+			// Validate against a scratch config; Apply sets the real one.
+			if err := s.set(raw); err != nil {
+				return err
+			}
+			if _, seen := o.values[key]; !seen {
+				o.keys = append(o.keys, key)
+			}
+			o.values[key] = raw
+			return nil
+		}
+		usage := fmt.Sprintf("config: overrides %s (env %s)", key, EnvName(key))
+		if kind == reflect.Bool {
+			fs.BoolFunc(key, usage, record)
+		} else {
+			fs.Func(key, usage, record)
+		}
+	}
+	return o
+}
+
+// IsConfigFlag reports whether name is a flag registered by BindFlags.
+func IsConfigFlag(name string) bool {
+	for _, s := range New().settings() {
+		if s.key == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply sets the recorded flag values on cfg. A nil Overrides does nothing.
+func (o *Overrides) Apply(cfg *AppConfig) error {
+	if o == nil {
+		return nil
+	}
+	byKey := make(map[string]setting)
+	for _, s := range cfg.settings() {
+		byKey[s.key] = s
+	}
+	for _, key := range o.keys {
+		if err := byKey[key].set(o.values[key]); err != nil {
+			return fmt.Errorf("flag -%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ResolveSecrets reads secrets referenced by file. When riot_api_key_file is
+// set, the key is read from that file (as mounted by Docker or Kubernetes
+// secrets) and replaces any riot_api_key given directly; surrounding
+// whitespace is trimmed.
+func (cfg *AppConfig) ResolveSecrets() error {
+	if cfg.RiotAPIKeyFile == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(cfg.RiotAPIKeyFile)
+	if err != nil {
+		return fmt.Errorf("reading riot_api_key_file: %w", err)
+	}
+	key := strings.TrimSpace(string(raw))
+	if key == "" {
+		return fmt.Errorf("riot_api_key_file %s is empty", cfg.RiotAPIKeyFile)
+	}
+	cfg.RiotAPIKey = key
+	return nil
+}
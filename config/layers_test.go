@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+// envMap returns a lookup function over env, standing in for os.LookupEnv.
+func envMap(env map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	cfg := New()
+	cfg.RiotRegion = "na1" // as if set by the config file
+	err := cfg.LoadEnv(envMap(map[string]string{
+		"LOLMATCHUP_RIOT_REGION":  "euw1",
+		"LOLMATCHUP_PORT":         "8080",
+		"LOLMATCHUP_DEBUG":        "false",
+		"LOLMATCHUP_RIOT_API_KEY": "RGAPI-env",
+		"RIOT_REGION":             "kr", // unprefixed variables are ignored
+	}))
+	if err != nil {
+		t.Fatalf("LoadEnv() error: %v", err)
+	}
+	if cfg.RiotRegion != "euw1" || cfg.Port != 8080 || cfg.Debug || cfg.RiotAPIKey != "RGAPI-env" {
+		t.Errorf("unexpected config: region=%q port=%d debug=%v key=%q", cfg.RiotRegion, cfg.Port, cfg.Debug, cfg.RiotAPIKey)
+	}
+	if cfg.ListenAddr != "127.0.0.1" {
+		t.Errorf("unset variable changed ListenAddr to %q", cfg.ListenAddr)
+	}
+}
+
+func TestLoadEnv_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"LOLMATCHUP_PORT":  "eighty",
+		"LOLMATCHUP_DEBUG": "maybe",
+	}
+	for name, value := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := New().LoadEnv(envMap(map[string]string{name: value}))
+			if err == nil || !strings.Contains(err.Error(), name) {
+				t.Errorf("LoadEnv() error = %v, want one naming %s", err, name)
+			}
+		})
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	o := BindFlags(fs)
+	if err := fs.Parse([]string{"-riot_region=kr", "-port", "9000", "-debug=false", "-tracing_exporter", "stdout"}); err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	cfg := New()
+	if err := cfg.LoadEnv(envMap(map[string]string{"LOLMATCHUP_RIOT_REGION": "euw1", "LOLMATCHUP_REDIS_DB": "2"})); err != nil {
+		t.Fatalf("LoadEnv() error: %v", err)
+	}
+	if err := o.Apply(cfg); err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	if cfg.RiotRegion != "kr" {
+		t.Errorf("flag should override environment: RiotRegion = %q", cfg.RiotRegion)
+	}
+	if cfg.RedisDB != 2 {
+		t.Errorf("environment should survive when no flag is given: RedisDB = %d", cfg.RedisDB)
+	}
+	if cfg.Port != 9000 || cfg.Debug || cfg.TracingExporter != "stdout" {
+		t.Errorf("unexpected config: port=%d debug=%v tracing=%q", cfg.Port, cfg.Debug, cfg.TracingExporter)
+	}
+}
+
+func TestOverrides_BoolWithoutValue(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	o := BindFlags(fs)
+	if err := fs.Parse([]string{"-debug"}); err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	cfg := New()
+	cfg.Debug = false
+	if err := o.Apply(cfg); err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	if !cfg.Debug {
+		t.Error("-debug should set Debug")
+	}
+}
+
+func TestOverrides_Invalid(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	BindFlags(fs)
+	if err := fs.Parse([]string{"-port=abc"}); err == nil {
+		t.Error("Parse() should reject a non-integer port")
+	}
+}
+
+func TestOverrides_NilApply(t *testing.T) {
+	var o *Overrides
+	if err := o.Apply(New()); err != nil {
+		t.Errorf("nil Apply() error: %v", err)
+	}
+}
+
+func TestIsConfigFlag(t *testing.T) {
+	if !IsConfigFlag("riot_api_key") || !IsConfigFlag("breaker_threshold") {
+		t.Error("TOML keys should be config flags")
+	}
+	if IsConfigFlag("config") || IsConfigFlag("Logger") {
+		t.Error("non-TOML names should not be config flags")
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "riot_api_key")
+	if err := os.WriteFile(keyPath, []byte("  RGAPI-from-file\n"), 0600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+	emptyPath := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyPath, []byte("\n"), 0600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	t.Run("file replaces key", func(t *testing.T) {
+		cfg := New()
+		cfg.RiotAPIKey = "RGAPI-inline"
+		cfg.RiotAPIKeyFile = keyPath
+		if err := cfg.ResolveSecrets(); err != nil {
+			t.Fatalf("ResolveSecrets() error: %v", err)
+		}
+		if cfg.RiotAPIKey != "RGAPI-from-file" {
+			t.Errorf("RiotAPIKey = %q, want trimmed file contents", cfg.RiotAPIKey)
+		}
+	})
+
+	t.Run("no file", func(t *testing.T) {
+		cfg := New()
+		cfg.RiotAPIKey = "RGAPI-inline"
+		if err := cfg.ResolveSecrets(); err != nil || cfg.RiotAPIKey != "RGAPI-inline" {
+			t.Errorf("ResolveSecrets() = %v, key %q", err, cfg.RiotAPIKey)
+		}
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		cfg := New()
+		cfg.RiotAPIKeyFile = emptyPath
+		if err := cfg.ResolveSecrets(); err == nil {
+			t.Error("expected error for empty key file")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		cfg := New()
+		cfg.RiotAPIKeyFile = filepath.Join(dir, "missing")
+		if err := cfg.ResolveSecrets(); err == nil {
+			t.Error("expected error for missing key file")
+		}
+	})
+}
+
+func TestLogConfig_RedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := New()
+	cfg.Logger = log.New(&buf)
+	cfg.RiotAPIKey = "RGAPI-secret"
+	cfg.RedisPassword = "hunter2"
+	cfg.RiotRegion = "euw1"
+
+	cfg.LogConfig()
+
+	out := buf.String()
+	for _, secret := range []string{"RGAPI-secret", "hunter2"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("LogConfig output contains secret %q:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "riot_api_key: "+Redacted) {
+		t.Errorf("LogConfig should show riot_api_key as redacted:\n%s", out)
+	}
+	if !strings.Contains(out, "riot_region: euw1") {
+		t.Errorf("LogConfig should show non-secret values:\n%s", out)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/klnstprx/lolMatchup/cache"
@@ -13,24 +14,40 @@ import (
 )
 
 // DataLoader handles obtaining the latest patch and champion data, caching as needed.
+//
+// Detailed champion data comes from the Meraki champion list whenever that is
+// fetched for the champion names: on a first run, a patch change and Refresh.
+// Details a cached patch lacks, e.g. in a cache file written by an older
+// version or after adopting another instance's shared backend, are filled in
+// by the warm-up one champion at a time.
 type DataLoader struct {
 	Config *config.AppConfig
 	Client *client.Client
 	Logger *log.Logger
-	Cache  *cache.Cache
+	Cache  cache.Store
+
+	// WarmupConcurrency is the number of champions WarmInBackground fetches
+	// at a time; zero disables the warm-up.
+	WarmupConcurrency int
+
+	warmup warmupState
 }
 
 // NewDataLoader creates a DataLoader with references to config, client, and cache.
-func NewDataLoader(cfg *config.AppConfig, client *client.Client, cache *cache.Cache) *DataLoader {
+func NewDataLoader(cfg *config.AppConfig, client *client.Client, store cache.Store) *DataLoader {
 	return &DataLoader{
 		Config: cfg,
 		Client: client,
 		Logger: cfg.Logger,
-		Cache:  cache,
+		Cache:  store,
+
+		WarmupConcurrency: cfg.WarmupConcurrency,
 	}
 }
 
 // Initialize checks the latest patch from DDragon and refreshes champion data if needed.
+// If champion data can be obtained neither upstream nor from the cache, the
+// bundled offline snapshot is used instead.
 func (dl *DataLoader) Initialize(ctx context.Context) error {
 	cachedPatch := dl.Cache.GetPatch()
 
@@ -39,70 +56,210 @@ func (dl *DataLoader) Initialize(ctx context.Context) error {
 		// Fallback to cached patch if available (offline mode)
 		if cachedPatch != "" {
 			dl.Logger.Warnf("Could not fetch latest patch, using cached patch %s: %v", cachedPatch, err)
-			dl.Config.PatchNumber = cachedPatch
+			dl.Config.SetPatch(cachedPatch)
 			return nil
 		}
-		return fmt.Errorf("failed to fetch latest patch: %w", err)
+		return dl.useSnapshot(fmt.Errorf("failed to fetch latest patch: %w", err))
 	}
 	dl.Logger.Infof("Latest patch version: %s", latestPatch)
-	dl.Config.PatchNumber = latestPatch
 
 	if cachedPatch != latestPatch {
-		dl.Logger.Infof("Patch changed from %s to %s; invalidating cache.", cachedPatch, latestPatch)
-		dl.Cache.Invalidate()
-		dl.Cache.SetPatch(latestPatch)
-
-		champions, err := dl.Client.FetchChampionList(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to fetch champion map: %w", err)
+		dl.Logger.Infof("Patch changed from %s to %s; refreshing cache.", cachedPatch, latestPatch)
+		if err := dl.swapPatch(ctx, latestPatch); err != nil {
+			if dl.Cache.GetChampionMapLen() == 0 {
+				return dl.useSnapshot(err)
+			}
+			return err
 		}
-		nameMap, keyMap := buildChampionMaps(champions)
-		dl.Cache.SetChampionMap(nameMap)
-		dl.Cache.SetChampionKeyMap(keyMap)
+		return nil
+	}
 
+	dl.Config.SetPatch(latestPatch)
+	dl.Logger.Info("Patch is up to date. Checking champion map in cache.")
+	// Details missing from a cached champion map are left to the warm-up.
+	if dl.Cache.GetChampionMapLen() == 0 {
+		dl.Logger.Info("Champion map is empty; fetching from Meraki.")
+		if err := dl.loadChampionList(ctx); err != nil {
+			return dl.useSnapshot(err)
+		}
+	}
+	if dl.Cache.GetSummonerSpellsLen() == 0 {
+		dl.Logger.Info("Summoner spells cache is empty; fetching from DDragon.")
 		spells, err := dl.Client.FetchSummonerSpells(ctx, latestPatch)
 		if err != nil {
 			dl.Logger.Errorf("Could not fetch summoner spells: %v", err)
 		} else {
 			dl.Cache.SetSummonerSpells(spells)
+			if err := dl.SaveCache(); err != nil {
+				dl.Logger.Errorf("Could not save cache: %v", err)
+			}
 		}
+	}
 
-		if err := dl.Cache.Save(); err != nil {
-			dl.Logger.Errorf("Could not save cache: %v", err)
-		}
-	} else {
-		dl.Logger.Info("Patch is up to date. Checking champion map in cache.")
-		if dl.Cache.GetChampionMapLen() == 0 {
-			dl.Logger.Info("Champion map is empty; fetching from Meraki.")
-			champions, err := dl.Client.FetchChampionList(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to fetch champion map: %w", err)
-			}
-			nameMap, keyMap := buildChampionMaps(champions)
-			dl.Cache.SetChampionMap(nameMap)
-			dl.Cache.SetChampionKeyMap(keyMap)
+	return nil
+}
 
-			if err := dl.Cache.Save(); err != nil {
-				dl.Logger.Errorf("Could not save cache: %v", err)
+// loadChampionList fetches every champion for the patch being served and
+// stores the name and key maps and the detailed data.
+func (dl *DataLoader) loadChampionList(ctx context.Context) error {
+	champions, err := dl.Client.FetchChampionList(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch champion map: %w", err)
+	}
+	nameMap, keyMap := buildChampionMaps(champions)
+	dl.Cache.SetChampionMap(nameMap)
+	dl.Cache.SetChampionKeyMap(keyMap)
+	dl.Cache.SetChampions(byKey(champions))
+
+	if err := dl.SaveCache(); err != nil {
+		dl.Logger.Errorf("Could not save cache: %v", err)
+	}
+	return nil
+}
+
+// SaveCache saves the cache unless it holds the bundled offline snapshot,
+// which must not be mistaken for fetched data on the next start. Every save
+// of champion data goes through it.
+func (dl *DataLoader) SaveCache() error {
+	if patch := dl.Config.SnapshotPatch(); patch != "" {
+		dl.Logger.Debug("Not saving cache: serving bundled snapshot", "patch", patch)
+		return nil
+	}
+	return dl.Cache.Save()
+}
+
+// useSnapshot serves the bundled offline snapshot after cause prevented
+// loading champion data. The snapshot is not saved to the cache file, so the
+// next start tries the network again; the patch watcher replaces it with live
+// data as soon as DDragon is reachable.
+func (dl *DataLoader) useSnapshot(cause error) error {
+	snap, err := LoadOfflineSnapshot()
+	if err != nil {
+		return fmt.Errorf("%w (bundled snapshot unavailable: %v)", cause, err)
+	}
+	dl.Cache.Swap(snap.Patch, snap.ChampionMap, snap.ChampionKeyMap, snap.SummonerSpells, nil)
+	dl.Config.SetPatch(snap.Patch)
+	dl.Config.SetSnapshotPatch(snap.Patch)
+	dl.Logger.Warn("Using bundled offline snapshot; champion data may be out of date",
+		"patch", snap.Patch, "generated", snap.GeneratedAt.Format(time.DateOnly), "error", cause)
+	return nil
+}
+
+// Watch polls DDragon every interval and hot-swaps champion and spell data
+// when a new patch is released. It blocks until ctx is cancelled; errors are
+// logged and retried on the next tick so a transient outage keeps the current
+// data in service.
+func (dl *DataLoader) Watch(ctx context.Context, interval time.Duration) {
+	dl.Logger.Info("Patch watcher started", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			dl.Logger.Debug("Patch watcher stopped")
+			return
+		case <-ticker.C:
+			swapped, err := dl.CheckForUpdate(ctx)
+			if err != nil && ctx.Err() == nil {
+				dl.Logger.Warn("Patch check failed", "error", err)
 			}
-		}
-		if dl.Cache.GetSummonerSpellsLen() == 0 {
-			dl.Logger.Info("Summoner spells cache is empty; fetching from DDragon.")
-			spells, err := dl.Client.FetchSummonerSpells(ctx, latestPatch)
-			if err != nil {
-				dl.Logger.Errorf("Could not fetch summoner spells: %v", err)
-			} else {
-				dl.Cache.SetSummonerSpells(spells)
-				if err := dl.Cache.Save(); err != nil {
-					dl.Logger.Errorf("Could not save cache: %v", err)
-				}
+			if swapped {
+				dl.WarmInBackground(ctx)
 			}
 		}
 	}
+}
+
+// CheckForUpdate fetches the latest patch once and, if it differs from the
+// patch being served or the bundled snapshot is in use, swaps in fresh data.
+// It reports whether a swap happened.
+func (dl *DataLoader) CheckForUpdate(ctx context.Context) (bool, error) {
+	latestPatch, err := dl.Client.FetchLatestPatch(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch latest patch: %w", err)
+	}
+	current := dl.Cache.GetPatch()
+	if latestPatch == current && dl.Config.SnapshotPatch() == "" {
+		return false, nil
+	}
+	if err := dl.swapPatch(ctx, latestPatch); err != nil {
+		return false, err
+	}
+	dl.Logger.Info("Patch changed", "from", current, "to", latestPatch,
+		"champions", dl.Cache.GetChampionMapLen(), "spells", dl.Cache.GetSummonerSpellsLen())
+	return true, nil
+}
+
+// swapPatch builds champion, key and spell maps for patch without touching the
+// cache, then swaps them in with a single call so lookups never see an empty
+// cache. If the shared cache backend already holds data for patch, that is
+// adopted instead of fetching it again. If the champion list cannot be fetched the current data is kept; a
+// failed spell fetch keeps the previous patch's spells.
+func (dl *DataLoader) swapPatch(ctx context.Context, patch string) error {
+	// Another instance sharing the cache backend may already have done the work.
+	if synced, err := dl.Cache.Sync(ctx); err != nil {
+		dl.Logger.Warn("Could not read shared cache", "error", err)
+	} else if synced && dl.Cache.GetPatch() == patch {
+		dl.Logger.Info("Adopted champion data from shared cache", "patch", patch)
+		dl.Config.SetPatch(patch)
+		dl.Config.SetSnapshotPatch("")
+		return nil
+	}
+	return dl.fetchPatch(ctx, patch)
+}
 
+// Refresh refetches champion and spell data for the latest patch even when
+// that patch is already being served, for when cached data is suspected to
+// be wrong. The current data stays in service until the new data has been
+// fetched. It returns the patch now being served.
+func (dl *DataLoader) Refresh(ctx context.Context) (string, error) {
+	latestPatch, err := dl.Client.FetchLatestPatch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch latest patch: %w", err)
+	}
+	if err := dl.fetchPatch(ctx, latestPatch); err != nil {
+		return "", err
+	}
+	dl.Logger.Info("Champion data refreshed", "patch", latestPatch,
+		"champions", dl.Cache.GetChampionMapLen(), "spells", dl.Cache.GetSummonerSpellsLen())
+	return latestPatch, nil
+}
+
+// fetchPatch fetches champion, key and spell maps for patch and swaps them in.
+func (dl *DataLoader) fetchPatch(ctx context.Context, patch string) error {
+	champions, err := dl.Client.FetchChampionList(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch champion map: %w", err)
+	}
+	nameMap, keyMap := buildChampionMaps(champions)
+
+	spells, err := dl.Client.FetchSummonerSpells(ctx, patch)
+	if err != nil {
+		dl.Logger.Errorf("Could not fetch summoner spells: %v", err)
+		spells = nil
+	}
+
+	dl.Cache.Swap(patch, nameMap, keyMap, spells, byKey(champions))
+	dl.Config.SetPatch(patch)
+	dl.Config.SetSnapshotPatch("")
+
+	if err := dl.SaveCache(); err != nil {
+		dl.Logger.Errorf("Could not save cache: %v", err)
+	}
 	return nil
 }
 
+// byKey re-keys the Meraki champion list by champion key, as the cache
+// stores detailed champion data.
+func byKey(champions map[string]models.Champion) map[string]models.Champion {
+	out := make(map[string]models.Champion, len(champions))
+	for _, champ := range champions {
+		out[champ.Key] = champ
+	}
+	return out
+}
+
 // buildChampionMaps returns a name->key map and a numeric ID->key map from champion data.
 func buildChampionMaps(champions map[string]models.Champion) (nameMap, keyMap map[string]string) {
 	nameMap = make(map[string]string, len(champions))
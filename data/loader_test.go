@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/klnstprx/lolMatchup/cache"
@@ -81,8 +82,8 @@ func TestInitialize_PatchChange(t *testing.T) {
 	if got := dl.Cache.GetPatch(); got != "15.1.1" {
 		t.Errorf("Patch: got %q, want %q", got, "15.1.1")
 	}
-	if dl.Config.PatchNumber != "15.1.1" {
-		t.Errorf("PatchNumber: got %q, want %q", dl.Config.PatchNumber, "15.1.1")
+	if dl.Config.Patch() != "15.1.1" {
+		t.Errorf("PatchNumber: got %q, want %q", dl.Config.Patch(), "15.1.1")
 	}
 	if dl.Cache.GetChampionMapLen() != 2 {
 		t.Errorf("ChampionMapLen: got %d, want 2", dl.Cache.GetChampionMapLen())
@@ -126,6 +127,40 @@ func TestInitialize_SamePatchEmptyMap(t *testing.T) {
 	}
 }
 
+func TestInitialize_SamePatchIncompleteChampions(t *testing.T) {
+	transport := &routingTransport{routes: map[string]*http.Response{
+		"versions.json":       makeResp(200, `["15.1.1"]`),
+		"champions/Ahri.json": makeResp(200, `{"id":103,"key":"Ahri","name":"Ahri"}`),
+	}}
+	var requested []string
+	dl := newTestLoader(t, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.Path)
+		return transport.RoundTrip(req)
+	}), "15.1.1")
+	// Names are cached, but only the champions looked up so far have details.
+	dl.Cache.SetChampionMap(map[string]string{"Aatrox": "Aatrox", "Ahri": "Ahri"})
+	dl.Cache.SetChampion(models.Champion{ID: 266, Key: "Aatrox", Name: "Aatrox"})
+	dl.Cache.SetSummonerSpells(map[string]models.SummonerSpell{"4": {Name: "Flash", Key: "4"}})
+
+	if err := dl.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error: %v", err)
+	}
+	if len(requested) != 1 {
+		t.Errorf("Initialize requested %v, want only the patch list", requested)
+	}
+
+	// The warm-up fetches only the champion without details.
+	if err := dl.Warmup(context.Background(), 2); err != nil {
+		t.Fatalf("Warmup() error: %v", err)
+	}
+	if len(requested) != 2 || !strings.HasSuffix(requested[1], "/Ahri.json") {
+		t.Errorf("requests = %v, want Ahri's details after the patch list", requested)
+	}
+	if got := len(dl.Cache.AllChampions()); got != 2 {
+		t.Errorf("AllChampions: got %d, want 2 (filled in)", got)
+	}
+}
+
 func TestInitialize_OfflineFallback(t *testing.T) {
 	transport := &routingTransport{routes: map[string]*http.Response{
 		"versions.json": makeResp(500, "server error"),
@@ -137,8 +172,8 @@ func TestInitialize_OfflineFallback(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected no error with offline fallback, got: %v", err)
 	}
-	if dl.Config.PatchNumber != "14.9.1" {
-		t.Errorf("PatchNumber: got %q, want cached %q", dl.Config.PatchNumber, "14.9.1")
+	if dl.Config.Patch() != "14.9.1" {
+		t.Errorf("PatchNumber: got %q, want cached %q", dl.Config.Patch(), "14.9.1")
 	}
 }
 
@@ -149,9 +184,19 @@ func TestInitialize_OfflineNoCachedPatch(t *testing.T) {
 
 	dl := newTestLoader(t, transport, "")
 
-	err := dl.Initialize(context.Background())
-	if err == nil {
-		t.Fatal("expected error with no cached patch and offline, got nil")
+	// With nothing cached, the bundled snapshot is the last resort.
+	if err := dl.Initialize(context.Background()); err != nil {
+		t.Fatalf("expected bundled snapshot fallback, got: %v", err)
+	}
+	snap, err := LoadOfflineSnapshot()
+	if err != nil {
+		t.Fatalf("LoadOfflineSnapshot() error: %v", err)
+	}
+	if dl.Config.Patch() != snap.Patch || dl.Config.SnapshotPatch() != snap.Patch {
+		t.Errorf("patch: got %q (snapshot %q), want %q", dl.Config.Patch(), dl.Config.SnapshotPatch(), snap.Patch)
+	}
+	if dl.Cache.GetChampionMapLen() != len(snap.ChampionMap) || dl.Cache.GetSummonerSpellsLen() == 0 {
+		t.Errorf("snapshot data not loaded: %d names, %d spells", dl.Cache.GetChampionMapLen(), dl.Cache.GetSummonerSpellsLen())
 	}
 }
 
@@ -183,3 +228,128 @@ func TestBuildChampionMaps(t *testing.T) {
 		t.Errorf("keyMap[103]: got %q, want %q", keyMap["103"], "Ahri")
 	}
 }
+
+func TestCheckForUpdate(t *testing.T) {
+	t.Run("new patch swaps data", func(t *testing.T) {
+		transport := &routingTransport{routes: map[string]*http.Response{
+			"versions.json":  makeResp(200, versionsJSON),
+			"champions.json": makeResp(200, champListJSON),
+			"summoner.json":  makeResp(200, `{"data":{"SummonerFlash":{"key":"4","name":"Flash"}}}`),
+		}}
+		dl := newTestLoader(t, transport, "14.9.1")
+		dl.Cache.SetChampionMap(map[string]string{"Old": "Old"})
+
+		changed, err := dl.CheckForUpdate(context.Background())
+		if err != nil {
+			t.Fatalf("CheckForUpdate() error: %v", err)
+		}
+		if !changed {
+			t.Fatal("expected a patch change")
+		}
+		if dl.Config.Patch() != "15.1.1" || dl.Cache.GetPatch() != "15.1.1" {
+			t.Errorf("patch: config %q, cache %q, want 15.1.1", dl.Config.Patch(), dl.Cache.GetPatch())
+		}
+		if _, ok := dl.Cache.GetChampionMap()["Ahri"]; !ok || dl.Cache.GetChampionMapLen() != 2 {
+			t.Errorf("champion map not swapped: %v", dl.Cache.GetChampionMap())
+		}
+		if dl.Cache.GetSummonerSpellsLen() != 1 {
+			t.Errorf("spells: got %d, want 1", dl.Cache.GetSummonerSpellsLen())
+		}
+	})
+
+	t.Run("same patch is a no-op", func(t *testing.T) {
+		transport := &routingTransport{routes: map[string]*http.Response{
+			"versions.json": makeResp(200, versionsJSON),
+		}}
+		dl := newTestLoader(t, transport, "15.1.1")
+		dl.Cache.SetChampionMap(map[string]string{"Aatrox": "Aatrox"})
+
+		changed, err := dl.CheckForUpdate(context.Background())
+		if err != nil || changed {
+			t.Fatalf("CheckForUpdate() = %v, %v; want false, nil", changed, err)
+		}
+		if dl.Cache.GetChampionMapLen() != 1 {
+			t.Errorf("champion map should be untouched, got %d entries", dl.Cache.GetChampionMapLen())
+		}
+	})
+
+	t.Run("failed champion fetch keeps current data", func(t *testing.T) {
+		transport := &routingTransport{routes: map[string]*http.Response{
+			"versions.json":  makeResp(200, versionsJSON),
+			"champions.json": makeResp(500, "down"),
+		}}
+		dl := newTestLoader(t, transport, "14.9.1")
+		dl.Config.SetPatch("14.9.1")
+		dl.Cache.SetChampionMap(map[string]string{"Aatrox": "Aatrox"})
+
+		if _, err := dl.CheckForUpdate(context.Background()); err == nil {
+			t.Fatal("expected error when champion list fetch fails")
+		}
+		if dl.Cache.GetPatch() != "14.9.1" || dl.Config.Patch() != "14.9.1" {
+			t.Errorf("patch should be unchanged, got cache %q config %q", dl.Cache.GetPatch(), dl.Config.Patch())
+		}
+		if dl.Cache.GetChampionMapLen() != 1 {
+			t.Errorf("champion map should be kept, got %d entries", dl.Cache.GetChampionMapLen())
+		}
+	})
+}
+
+func TestRefresh_RefetchesSamePatch(t *testing.T) {
+	transport := &routingTransport{routes: map[string]*http.Response{
+		"versions.json":  makeResp(200, `["15.1.1"]`),
+		"champions.json": makeResp(200, champListJSON),
+	}}
+	dl := newTestLoader(t, transport, "15.1.1")
+	dl.Cache.SetChampionMap(map[string]string{"Stale": "Stale"})
+
+	patch, err := dl.Refresh(context.Background())
+	if err != nil || patch != "15.1.1" {
+		t.Fatalf("Refresh() = %q, %v", patch, err)
+	}
+	if _, ok := dl.Cache.GetChampionMap()["Ahri"]; !ok || dl.Cache.GetChampionMapLen() != 2 {
+		t.Errorf("champion map not refetched: %v", dl.Cache.GetChampionMap())
+	}
+}
+
+func TestWatch_StopsOnCancel(t *testing.T) {
+	dl := newTestLoader(t, &routingTransport{}, "15.1.1")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dl.Watch(ctx, time.Hour)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Watch did not return after context cancellation")
+	}
+}
+
+func TestCheckForUpdate_AdoptsSharedBackend(t *testing.T) {
+	// The champion list endpoint is not routed, so any fetch would fail.
+	transport := &routingTransport{routes: map[string]*http.Response{
+		"versions.json": makeResp(200, versionsJSON),
+	}}
+	dl := newTestLoader(t, transport, "14.9.1")
+	dl.Cache.SetChampionMap(map[string]string{"Old": "Old"})
+
+	// Simulate another instance having refreshed the shared backend.
+	shared := dl.Config.Cache.Backend
+	if err := shared.SaveSnapshot(context.Background(), cache.Snapshot{
+		Patch:       "15.1.1",
+		ChampionMap: map[string]string{"Ahri": "Ahri", "Aatrox": "Aatrox"},
+	}); err != nil {
+		t.Fatalf("SaveSnapshot() error: %v", err)
+	}
+
+	changed, err := dl.CheckForUpdate(context.Background())
+	if err != nil {
+		t.Fatalf("CheckForUpdate() error: %v", err)
+	}
+	if !changed || dl.Config.Patch() != "15.1.1" || dl.Cache.GetChampionMapLen() != 2 {
+		t.Errorf("expected shared data adopted: changed=%v patch=%q names=%d",
+			changed, dl.Config.Patch(), dl.Cache.GetChampionMapLen())
+	}
+}
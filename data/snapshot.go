@@ -0,0 +1,86 @@
+package data
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/cache"
+	"github.com/klnstprx/lolMatchup/client"
+)
+
+// SnapshotPath is where the bundled snapshot lives, relative to the repository
+// root. Refresh it with `make snapshot`.
+const SnapshotPath = "data/snapshot.json.gz"
+
+//go:embed snapshot.json.gz
+var bundledSnapshot []byte
+
+// OfflineSnapshot is champion and summoner spell data for one patch, compiled
+// into the binary so a first run without network access and without a cache
+// file can still start. Detailed champion data is not included; it is fetched
+// from Meraki on demand once the network is back.
+type OfflineSnapshot struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	cache.Snapshot
+}
+
+// LoadOfflineSnapshot decodes the bundled snapshot.
+func LoadOfflineSnapshot() (OfflineSnapshot, error) {
+	return ReadOfflineSnapshot(bytes.NewReader(bundledSnapshot))
+}
+
+// ReadOfflineSnapshot decodes a gzip-compressed snapshot from r.
+func ReadOfflineSnapshot(r io.Reader) (OfflineSnapshot, error) {
+	var s OfflineSnapshot
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return s, fmt.Errorf("failed to decompress snapshot: %w", err)
+	}
+	defer zr.Close()
+	if err := json.NewDecoder(zr).Decode(&s); err != nil {
+		return s, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if s.Patch == "" || len(s.ChampionMap) == 0 {
+		return s, errors.New("snapshot has no champion data")
+	}
+	return s, nil
+}
+
+// Write encodes s gzip-compressed to w.
+func (s OfflineSnapshot) Write(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(s); err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return zw.Close()
+}
+
+// BuildOfflineSnapshot fetches the latest patch's champion list and summoner
+// spells, as Initialize would, for writing a fresh bundled snapshot.
+func BuildOfflineSnapshot(ctx context.Context, c *client.Client) (OfflineSnapshot, error) {
+	var s OfflineSnapshot
+	patch, err := c.FetchLatestPatch(ctx)
+	if err != nil {
+		return s, fmt.Errorf("failed to fetch latest patch: %w", err)
+	}
+	champions, err := c.FetchChampionList(ctx)
+	if err != nil {
+		return s, fmt.Errorf("failed to fetch champion map: %w", err)
+	}
+	spells, err := c.FetchSummonerSpells(ctx, patch)
+	if err != nil {
+		return s, err
+	}
+	s.GeneratedAt = time.Now().UTC().Truncate(time.Second)
+	s.Patch = patch
+	s.ChampionMap, s.ChampionKeyMap = buildChampionMaps(champions)
+	s.SummonerSpells = spells
+	return s, nil
+}
@@ -0,0 +1,103 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klnstprx/lolMatchup/mockriot"
+)
+
+func TestBundledSnapshot(t *testing.T) {
+	snap, err := LoadOfflineSnapshot()
+	if err != nil {
+		t.Fatalf("LoadOfflineSnapshot() error: %v", err)
+	}
+	if snap.GeneratedAt.IsZero() || len(snap.ChampionKeyMap) != len(snap.ChampionMap) {
+		t.Errorf("incomplete snapshot: generated %v, %d names, %d keys",
+			snap.GeneratedAt, len(snap.ChampionMap), len(snap.ChampionKeyMap))
+	}
+	if _, ok := snap.SummonerSpells["4"]; !ok {
+		t.Error("snapshot should include Flash (key 4)")
+	}
+	// A snapshot built from cmd/mockriot has display names equal to the keys
+	// and only the champions seen in its fixtures.
+	if snap.Patch == mockriot.DefaultPatch || len(snap.ChampionMap) < 160 {
+		t.Errorf("snapshot looks built from mock data: patch %q, %d champions", snap.Patch, len(snap.ChampionMap))
+	}
+	for name, key := range map[string]string{"Wukong": "MonkeyKing", "Kai'Sa": "Kaisa", "Dr. Mundo": "DrMundo", "Alistar": "Alistar"} {
+		if got := snap.ChampionMap[name]; got != key {
+			t.Errorf("ChampionMap[%q] = %q, want %q", name, got, key)
+		}
+	}
+	if got := snap.ChampionKeyMap["62"]; got != "MonkeyKing" {
+		t.Errorf("ChampionKeyMap[62] = %q, want MonkeyKing", got)
+	}
+}
+
+func TestBuildOfflineSnapshotRoundTrip(t *testing.T) {
+	ts := httptest.NewServer(mockriot.New())
+	defer ts.Close()
+	// Summoner spells are requested from the Data Dragon host; send
+	// everything to the mock.
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		out := req.Clone(req.Context())
+		out.URL.Scheme, out.URL.Host = "http", ts.Listener.Addr().String()
+		return ts.Client().Transport.RoundTrip(out)
+	})}
+
+	snap, err := BuildOfflineSnapshot(context.Background(), mockriot.ClientFor(ts.URL, httpClient))
+	if err != nil {
+		t.Fatalf("BuildOfflineSnapshot() error: %v", err)
+	}
+	if snap.Patch != mockriot.DefaultPatch || snap.ChampionKeyMap["266"] != "Aatrox" || len(snap.SummonerSpells) == 0 {
+		t.Errorf("unexpected snapshot: patch %q, 266 -> %q, %d spells", snap.Patch, snap.ChampionKeyMap["266"], len(snap.SummonerSpells))
+	}
+
+	var buf bytes.Buffer
+	if err := snap.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadOfflineSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("ReadOfflineSnapshot() error: %v", err)
+	}
+	if got.Patch != snap.Patch || len(got.ChampionMap) != len(snap.ChampionMap) || !got.GeneratedAt.Equal(snap.GeneratedAt) {
+		t.Errorf("round trip mismatch: %+v", got)
+	}
+
+	if _, err := ReadOfflineSnapshot(bytes.NewReader([]byte("not gzip"))); err == nil {
+		t.Error("expected an error for a corrupt snapshot")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestCheckForUpdate_ReplacesSnapshot(t *testing.T) {
+	transport := &routingTransport{routes: map[string]*http.Response{
+		"versions.json": makeResp(200, versionsJSON),
+	}}
+	dl := newTestLoader(t, transport, "")
+	if err := dl.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error: %v", err)
+	}
+	if dl.Config.SnapshotPatch() == "" {
+		t.Fatal("expected the snapshot to be in use when the champion list is unreachable")
+	}
+
+	// Meraki comes back: even at the same patch, live data replaces the snapshot.
+	transport.routes["versions.json"] = makeResp(200, versionsJSON)
+	transport.routes["champions.json"] = makeResp(200, champListJSON)
+	dl.Cache.SetPatch("15.1.1")
+	changed, err := dl.CheckForUpdate(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("CheckForUpdate() = %v, %v; want a swap", changed, err)
+	}
+	if dl.Config.SnapshotPatch() != "" || dl.Cache.GetChampionMapLen() != 2 {
+		t.Errorf("snapshot not replaced: snapshot patch %q, %d names", dl.Config.SnapshotPatch(), dl.Cache.GetChampionMapLen())
+	}
+}
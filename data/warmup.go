@@ -0,0 +1,178 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// warmupSaveEvery is how many fetched champions a warm-up stores between
+// cache saves, so that an interrupted warm-up resumes close to where it
+// stopped.
+const warmupSaveEvery = 25
+
+// WarmupProgress reports the state of the champion data warm-up.
+type WarmupProgress struct {
+	Running    bool      `json:"running"`
+	Patch      string    `json:"patch,omitempty"`
+	Total      int       `json:"total"`  // champions missing details when the run started
+	Done       int       `json:"done"`   // fetched and stored
+	Failed     int       `json:"failed"` // left for the next run
+	StartedAt  time.Time `json:"startedAt,omitzero"`
+	FinishedAt time.Time `json:"finishedAt,omitzero"`
+}
+
+// warmupState guards the progress of the current or last warm-up.
+type warmupState struct {
+	mu       sync.Mutex
+	progress WarmupProgress
+}
+
+// WarmupProgress returns the progress of the current or last warm-up.
+func (dl *DataLoader) WarmupProgress() WarmupProgress {
+	dl.warmup.mu.Lock()
+	defer dl.warmup.mu.Unlock()
+	return dl.warmup.progress
+}
+
+// ErrWarmupRunning is returned by Warmup when another warm-up is under way.
+var ErrWarmupRunning = errors.New("champion warm-up already running")
+
+// Warmup fetches detailed data for every champion in the champion map that
+// does not have it yet, at most concurrency at a time, so that autocomplete
+// and the champion browser do not wait for each champion's first lookup.
+// Champions already cached are skipped, which makes a warm-up interrupted by
+// a restart resume where it stopped. The cache is saved every
+// warmupSaveEvery champions and at the end. Failed fetches are logged and
+// retried by the next warm-up. A patch change stops the run, since its data
+// would belong to the old patch. Nothing is fetched while the bundled
+// snapshot is served; the patch watcher warms up once live data replaces it.
+func (dl *DataLoader) Warmup(ctx context.Context, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if patch := dl.Config.SnapshotPatch(); patch != "" {
+		dl.Logger.Debug("Champion warm-up skipped: serving bundled snapshot", "patch", patch)
+		return nil
+	}
+	dl.warmup.mu.Lock()
+	if dl.warmup.progress.Running {
+		dl.warmup.mu.Unlock()
+		return ErrWarmupRunning
+	}
+	patch := dl.Cache.GetPatch()
+	missing := dl.missingChampions()
+	dl.warmup.progress = WarmupProgress{Running: true, Patch: patch, Total: len(missing), StartedAt: time.Now()}
+	dl.warmup.mu.Unlock()
+	defer func() {
+		dl.warmup.mu.Lock()
+		dl.warmup.progress.Running = false
+		dl.warmup.progress.FinishedAt = time.Now()
+		dl.warmup.mu.Unlock()
+	}()
+
+	if len(missing) == 0 {
+		dl.Logger.Debug("Champion warm-up: all champions cached", "patch", patch)
+		return nil
+	}
+	dl.Logger.Info("Champion warm-up started", "patch", patch, "champions", len(missing), "concurrency", concurrency)
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	keys := make(chan string)
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				dl.warmChampion(ctx, cancel, patch, key)
+			}
+		}()
+	}
+feed:
+	for _, key := range missing {
+		select {
+		case keys <- key:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(keys)
+	wg.Wait()
+
+	if err := dl.SaveCache(); err != nil {
+		dl.Logger.Errorf("Could not save cache: %v", err)
+	}
+	p := dl.WarmupProgress()
+	dl.Logger.Info("Champion warm-up finished", "patch", patch, "fetched", p.Done, "failed", p.Failed,
+		"remaining", p.Total-p.Done-p.Failed, "took", time.Since(p.StartedAt).Round(time.Millisecond))
+	return parent.Err()
+}
+
+// WarmInBackground starts a warm-up with WarmupConcurrency workers unless
+// that is zero. It returns at once.
+func (dl *DataLoader) WarmInBackground(ctx context.Context) {
+	if dl.WarmupConcurrency <= 0 {
+		return
+	}
+	go func() {
+		if err := dl.Warmup(ctx, dl.WarmupConcurrency); err != nil && ctx.Err() == nil {
+			dl.Logger.Warn("Champion warm-up not started", "error", err)
+		}
+	}()
+}
+
+// warmChampion fetches and stores one champion for a warm-up of patch,
+// cancelling the warm-up if the patch has changed since it started.
+func (dl *DataLoader) warmChampion(ctx context.Context, cancel context.CancelFunc, patch, key string) {
+	champ, err := dl.Client.FetchChampionData(ctx, key)
+	if ctx.Err() != nil {
+		return
+	}
+	if dl.Cache.GetPatch() != patch {
+		dl.Logger.Info("Champion warm-up stopped: patch changed", "from", patch, "to", dl.Cache.GetPatch())
+		cancel()
+		return
+	}
+
+	if err != nil {
+		dl.warmup.mu.Lock()
+		dl.warmup.progress.Failed++
+		dl.warmup.mu.Unlock()
+		dl.Logger.Warn("Champion warm-up fetch failed", "champion", key, "error", err)
+		return
+	}
+
+	dl.Cache.SetChampion(champ)
+	dl.warmup.mu.Lock()
+	dl.warmup.progress.Done++
+	p := dl.warmup.progress
+	dl.warmup.mu.Unlock()
+	if p.Done%warmupSaveEvery == 0 {
+		dl.Logger.Info("Champion warm-up progress", "done", p.Done, "failed", p.Failed, "total", p.Total)
+		if err := dl.SaveCache(); err != nil {
+			dl.Logger.Errorf("Could not save cache: %v", err)
+		}
+	}
+}
+
+// missingChampions returns the keys of champions in the champion map without
+// detailed data, sorted.
+func (dl *DataLoader) missingChampions() []string {
+	have := make(map[string]bool)
+	for _, champ := range dl.Cache.AllChampions() {
+		have[champ.Key] = true
+	}
+	var missing []string
+	for _, key := range dl.Cache.GetChampionMap() {
+		if !have[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,157 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/cache"
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+// championTransport serves Meraki champion details, failing for the keys in
+// fail, and records the most requests it saw in flight at once.
+type championTransport struct {
+	fail     map[string]bool
+	inFlight atomic.Int32
+	maxSeen  atomic.Int32
+
+	mu        sync.Mutex
+	requested []string
+}
+
+func (ct *championTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := ct.inFlight.Add(1)
+	defer ct.inFlight.Add(-1)
+	for {
+		max := ct.maxSeen.Load()
+		if n <= max || ct.maxSeen.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond) // let the workers overlap
+
+	key := strings.TrimSuffix(req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:], ".json")
+	ct.mu.Lock()
+	ct.requested = append(ct.requested, key)
+	ct.mu.Unlock()
+	if ct.fail[key] {
+		return makeResp(http.StatusInternalServerError, ""), nil
+	}
+	return makeResp(http.StatusOK, fmt.Sprintf(`{"key":%q,"name":%q,"title":"warmed"}`, key, key)), nil
+}
+
+func TestWarmup(t *testing.T) {
+	ct := &championTransport{fail: map[string]bool{"Zed": true}}
+	dl := newTestLoader(t, ct, "15.1.1")
+	names := map[string]string{}
+	for _, key := range []string{"Aatrox", "Ahri", "Akali", "Annie", "Ashe", "Jinx", "Lux", "Zed"} {
+		names[key] = key
+	}
+	dl.Cache.SetChampionMap(names)
+	dl.Cache.SetChampion(models.Champion{Key: "Ahri", Name: "Ahri", Title: "cached"})
+
+	if err := dl.Warmup(context.Background(), 3); err != nil {
+		t.Fatalf("Warmup() error: %v", err)
+	}
+
+	p := dl.WarmupProgress()
+	if p.Running || p.Total != 7 || p.Done != 6 || p.Failed != 1 {
+		t.Errorf("progress = %+v, want 7 missing, 6 fetched, 1 failed", p)
+	}
+	for _, key := range ct.requested {
+		if key == "Ahri" {
+			t.Error("a cached champion was fetched again")
+		}
+	}
+	if max := ct.maxSeen.Load(); max > 3 {
+		t.Errorf("%d fetches in flight, want at most 3", max)
+	}
+	if c, _ := dl.Cache.GetChampionByID("Ahri"); c.Title != "cached" {
+		t.Errorf("cached champion replaced: %+v", c)
+	}
+
+	// The result was saved, so a restart only retries the failure.
+	reloaded := cache.New(dl.Config.Cache.Path, 3)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	dl.Cache = reloaded
+	ct.requested = nil
+	if err := dl.Warmup(context.Background(), 3); err != nil {
+		t.Fatalf("second Warmup() error: %v", err)
+	}
+	if len(ct.requested) != 1 || ct.requested[0] != "Zed" {
+		t.Errorf("second warm-up fetched %v, want only Zed", ct.requested)
+	}
+}
+
+func TestWarmup_StopsOnPatchChange(t *testing.T) {
+	ct := &championTransport{}
+	dl := newTestLoader(t, ct, "15.1.1")
+	names := map[string]string{}
+	for i := range 20 {
+		key := fmt.Sprintf("Champ%02d", i)
+		names[key] = key
+	}
+	dl.Cache.SetChampionMap(names)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- dl.Warmup(ctx, 1) }()
+	for dl.WarmupProgress().Done == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	dl.Cache.SetPatch("15.2.1")
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Warmup() error = %v, want nil after a patch change", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Warmup() did not stop after the patch changed")
+	}
+	if p := dl.WarmupProgress(); p.Done >= 20 {
+		t.Errorf("progress = %+v, want the run cut short", p)
+	}
+}
+
+func TestWarmup_SkipsSnapshot(t *testing.T) {
+	ct := &championTransport{}
+	dl := newTestLoader(t, ct, "")
+	ct.fail = map[string]bool{"versions": true}
+	if err := dl.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error: %v", err)
+	}
+	if dl.Config.SnapshotPatch() == "" {
+		t.Fatal("expected the bundled snapshot to be in use")
+	}
+
+	if err := dl.Warmup(context.Background(), 2); err != nil {
+		t.Fatalf("Warmup() error: %v", err)
+	}
+	if len(ct.requested) != 1 {
+		t.Errorf("warm-up fetched %v while serving the snapshot", ct.requested[1:])
+	}
+	// The snapshot must not end up in the cache file as if it were fetched.
+	if _, err := os.Stat(dl.Config.Cache.Path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("cache file written while serving the snapshot: %v", err)
+	}
+}
+
+func TestWarmup_AlreadyRunning(t *testing.T) {
+	dl := newTestLoader(t, &championTransport{}, "15.1.1")
+	dl.warmup.progress.Running = true
+	if err := dl.Warmup(context.Background(), 2); err != ErrWarmupRunning {
+		t.Errorf("Warmup() error = %v, want ErrWarmupRunning", err)
+	}
+}
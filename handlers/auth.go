@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/auth"
+	"github.com/klnstprx/lolMatchup/components"
+	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/renderer"
+)
+
+// AuthHandler serves sign-in, sign-out and the account page where users
+// manage their personal API tokens.
+type AuthHandler struct {
+	Logger *log.Logger
+	Auth   *auth.Authenticator
+}
+
+// NewAuthHandler creates an AuthHandler.
+func NewAuthHandler(cfg *config.AppConfig, a *auth.Authenticator) *AuthHandler {
+	return &AuthHandler{
+		Logger: cfg.Logger,
+		Auth:   a,
+	}
+}
+
+// LoginGET renders the sign-in form, or sends visitors who need not sign in
+// on to where they were going.
+func (h *AuthHandler) LoginGET(c *gin.Context) {
+	next := safeNext(c.Query("next"))
+	if _, signedIn := auth.FromContext(c.Request.Context()); signedIn || !h.Auth.Enabled() {
+		c.Redirect(http.StatusSeeOther, next)
+		return
+	}
+	h.renderLogin(c, http.StatusOK, components.LoginView{Next: next})
+}
+
+// LoginPOST checks the submitted credentials and, if they are valid, sets the
+// session cookie and redirects to the page that asked for sign-in.
+func (h *AuthHandler) LoginPOST(c *gin.Context) {
+	if !sameOrigin(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	if !h.Auth.Enabled() {
+		c.Redirect(http.StatusSeeOther, "/")
+		return
+	}
+	next := safeNext(c.PostForm("next"))
+	name := strings.TrimSpace(c.PostForm("username"))
+	p, ok := h.Auth.Login(name, c.PostForm("password"))
+	if !ok {
+		h.Logger.Warn("Failed sign-in", "user", name, "ip", c.ClientIP())
+		h.renderLogin(c, http.StatusUnauthorized, components.LoginView{
+			Next:  next,
+			Name:  name,
+			Error: "Sign-in failed: check your credentials and try again.",
+		})
+		return
+	}
+	h.Logger.Info("Signed in", "user", p.DisplayName(), "ip", c.ClientIP())
+	http.SetCookie(c.Writer, h.Auth.SessionCookie(p, secureRequest(c.Request)))
+	c.Redirect(http.StatusSeeOther, next)
+}
+
+// LogoutPOST clears the session cookie.
+func (h *AuthHandler) LogoutPOST(c *gin.Context) {
+	if !sameOrigin(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	http.SetCookie(c.Writer, auth.ClearCookie(secureRequest(c.Request)))
+	if h.Auth.Enabled() {
+		c.Redirect(http.StatusSeeOther, "/login")
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/")
+}
+
+// AccountGET renders the signed-in user's account page.
+func (h *AuthHandler) AccountGET(c *gin.Context) {
+	user, ok := h.accountUser(c)
+	if !ok {
+		return
+	}
+	h.renderAccount(c, user, "")
+}
+
+// TokenPOST creates a personal API token labelled by the "label" field. The
+// token is shown once: on the account page for browsers, or as JSON.
+func (h *AuthHandler) TokenPOST(c *gin.Context) {
+	if !sameOrigin(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	user, ok := h.accountUser(c)
+	if !ok {
+		return
+	}
+	raw, token, err := h.Auth.Users.CreateToken(user.Name, c.PostForm("label"))
+	if err != nil {
+		h.Logger.Error("Creating API token failed", "user", user.Name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create token"})
+		return
+	}
+	h.Logger.Info("API token created", "user", user.Name, "token", token.ID)
+
+	if wantsJSON(c) {
+		c.JSON(http.StatusCreated, gin.H{"token": raw, "id": token.ID, "label": token.Label})
+		return
+	}
+	user, _ = h.Auth.Users.Lookup(user.Name)
+	h.renderAccount(c, user, raw)
+}
+
+// TokenRevokePOST deletes the token named by the "id" field.
+func (h *AuthHandler) TokenRevokePOST(c *gin.Context) {
+	if !sameOrigin(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	user, ok := h.accountUser(c)
+	if !ok {
+		return
+	}
+	id := c.PostForm("id")
+	if err := h.Auth.Users.RevokeToken(user.Name, id); err != nil {
+		if errors.Is(err, auth.ErrTokenNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.Logger.Error("Revoking API token failed", "user", user.Name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not revoke token"})
+		return
+	}
+	h.Logger.Info("API token revoked", "user", user.Name, "token", id)
+
+	if wantsJSON(c) {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/account")
+}
+
+// accountUser returns the signed-in local account, answering 404 when
+// accounts are not in use (such as for invite-token guests).
+func (h *AuthHandler) accountUser(c *gin.Context) (auth.User, bool) {
+	p, ok := auth.FromContext(c.Request.Context())
+	if ok && h.Auth.Accounts() && p.Name != "" {
+		if user, ok := h.Auth.Users.Lookup(p.Name); ok {
+			return user, true
+		}
+	}
+	c.AbortWithStatus(http.StatusNotFound)
+	return auth.User{}, false
+}
+
+func (h *AuthHandler) renderLogin(c *gin.Context, status int, v components.LoginView) {
+	v.Invite = h.Auth.Mode == auth.ModeInvite
+	c.Render(status, renderer.New(c.Request.Context(), status, components.LoginPage(v)))
+}
+
+func (h *AuthHandler) renderAccount(c *gin.Context, user auth.User, newToken string) {
+	v := components.AccountView{Name: user.Name, Role: user.Role, Tokens: user.Tokens, NewToken: newToken}
+	c.Render(http.StatusOK, renderer.New(c.Request.Context(), http.StatusOK, components.AccountPage(v)))
+}
+
+// safeNext returns next if it is a local path, so the login form cannot be
+// used to redirect visitors to another site, and "/" otherwise.
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+// secureRequest reports whether r arrived over HTTPS, directly or through a
+// TLS-terminating proxy, so cookies can be marked Secure.
+func secureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// wantsJSON reports whether the client asked for JSON rather than a page.
+func wantsJSON(c *gin.Context) bool {
+	return c.Query("format") == "json" || c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON
+}
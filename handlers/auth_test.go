@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/auth"
+	"github.com/klnstprx/lolMatchup/middleware"
+)
+
+// newTestAuthRouter serves the auth routes in accounts mode with a user
+// "alice" (password "correct horse").
+func newTestAuthRouter(t *testing.T) (*gin.Engine, *auth.Authenticator) {
+	t.Helper()
+	cfg := newTestConfig()
+	cfg.AuthMode = "accounts"
+	cfg.UsersFile = filepath.Join(t.TempDir(), "users.json")
+	a, err := auth.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Users.SetUser("alice", "correct horse", auth.RoleUser); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewAuthHandler(cfg, a)
+	r := gin.New()
+	r.Use(middleware.AuthMiddleware(a))
+	r.GET("/login", h.LoginGET)
+	r.POST("/login", h.LoginPOST)
+	r.POST("/logout", h.LogoutPOST)
+	site := r.Group("/", middleware.RequireLogin(a))
+	site.GET("/account", h.AccountGET)
+	site.POST("/account/tokens", h.TokenPOST)
+	site.POST("/account/tokens/revoke", h.TokenRevokePOST)
+	return r, a
+}
+
+// postForm sends form to path with the given cookies and headers.
+func postForm(r *gin.Engine, path string, form url.Values, cookies []*http.Cookie, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLogin(t *testing.T) {
+	r, _ := newTestAuthRouter(t)
+
+	t.Run("success", func(t *testing.T) {
+		w := postForm(r, "/login", url.Values{"username": {"alice"}, "password": {"correct horse"}, "next": {"/player?q=a%231"}}, nil, nil)
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/player?q=a%231" {
+			t.Fatalf("got %d to %q", w.Code, w.Header().Get("Location"))
+		}
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != auth.CookieName || !cookies[0].HttpOnly {
+			t.Errorf("unexpected cookies: %+v", cookies)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		w := postForm(r, "/login", url.Values{"username": {"alice"}, "password": {"nope"}}, nil, nil)
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "Sign-in failed") {
+			t.Errorf("got %d: %s", w.Code, w.Body.String())
+		}
+		if len(w.Result().Cookies()) != 0 {
+			t.Error("a failed sign-in must not set a cookie")
+		}
+	})
+
+	t.Run("offsite next", func(t *testing.T) {
+		w := postForm(r, "/login", url.Values{"username": {"alice"}, "password": {"correct horse"}, "next": {"//evil.example/"}}, nil, nil)
+		if got := w.Header().Get("Location"); got != "/" {
+			t.Errorf("Location: got %q, want /", got)
+		}
+	})
+
+	t.Run("cross-site", func(t *testing.T) {
+		w := postForm(r, "/login", url.Values{"username": {"alice"}, "password": {"correct horse"}}, nil, map[string]string{"Sec-Fetch-Site": "cross-site"})
+		if w.Code != http.StatusForbidden {
+			t.Errorf("status: got %d, want 403", w.Code)
+		}
+	})
+
+	t.Run("logout", func(t *testing.T) {
+		w := postForm(r, "/logout", nil, nil, nil)
+		cookies := w.Result().Cookies()
+		if w.Code != http.StatusSeeOther || len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+			t.Errorf("got %d, cookies %+v", w.Code, cookies)
+		}
+	})
+}
+
+func TestAccountTokens(t *testing.T) {
+	r, a := newTestAuthRouter(t)
+	login := postForm(r, "/login", url.Values{"username": {"alice"}, "password": {"correct horse"}}, nil, nil)
+	session := login.Result().Cookies()
+
+	w := postForm(r, "/account/tokens", url.Values{"label": {"script"}}, session, map[string]string{"Accept": "application/json"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status: got %d, want 201: %s", w.Code, w.Body.String())
+	}
+	var created struct{ Token, ID, Label string }
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if p, ok := a.Users.AuthenticateToken(created.Token); !ok || p.Name != "alice" || created.Label != "script" {
+		t.Errorf("created token %+v does not authenticate", created)
+	}
+
+	// The new token can be used in place of the session.
+	req := httptest.NewRequest(http.MethodGet, "/account", nil)
+	req.Header.Set("Authorization", "Bearer "+created.Token)
+	page := httptest.NewRecorder()
+	r.ServeHTTP(page, req)
+	if page.Code != http.StatusOK || !strings.Contains(page.Body.String(), created.ID) || strings.Contains(page.Body.String(), created.Token) {
+		t.Errorf("account page: %d", page.Code)
+	}
+
+	w = postForm(r, "/account/tokens/revoke", url.Values{"id": {created.ID}}, session, nil)
+	if w.Code != http.StatusSeeOther {
+		t.Errorf("revoke status: got %d, want 303", w.Code)
+	}
+	if _, ok := a.Users.AuthenticateToken(created.Token); ok {
+		t.Error("revoked token should no longer authenticate")
+	}
+
+	if w := postForm(r, "/account/tokens", nil, nil, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("creating a token signed out: got %d, want 401", w.Code)
+	}
+}
@@ -11,46 +11,71 @@ import (
 	"github.com/klnstprx/lolMatchup/components"
 	"github.com/klnstprx/lolMatchup/config"
 	"github.com/klnstprx/lolMatchup/renderer"
+	"github.com/klnstprx/lolMatchup/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const defaultAutocompleteLimit = 10
 
 type AutocompleteHandler struct {
-	Logger *log.Logger
-	Cache  *cache.Cache
-	Config *config.AppConfig
-	Client *client.Client
+	Logger  *log.Logger
+	Cache   cache.Store
+	Players *cache.Players // Riot IDs seen so far; nil disables player suggestions
+	Config  *config.AppConfig
+	Client  *client.Client
 }
 
 func NewAutocompleteHandler(cfg *config.AppConfig, client *client.Client) *AutocompleteHandler {
 	return &AutocompleteHandler{
-		Logger: cfg.Logger,
-		Cache:  cfg.Cache,
-		Config: cfg,
-		Client: client,
+		Logger:  cfg.Logger,
+		Cache:   cfg.Cache,
+		Players: cfg.Players,
+		Config:  cfg,
+		Client:  client,
 	}
 }
 
 // AutocompleteGET handles /autocomplete requests for champion names.
 // Accepts "champion" or "q" query param to support both champion form and unified search.
-// When the query contains "#", it returns a player search suggestion instead.
+// When the query contains "#", it returns a player search suggestion instead,
+// followed by matching players seen before. In unified search, seen players
+// are also suggested when no champion matches.
 func (h *AutocompleteHandler) AutocompleteGET(c *gin.Context) {
 	userQuery := strings.TrimSpace(c.Query("champion"))
-	if userQuery == "" {
+	unified := userQuery == ""
+	if unified {
 		userQuery = strings.TrimSpace(c.Query("q"))
 	}
 
 	// If query contains #, show a player search suggestion
 	if strings.Contains(userQuery, "#") {
-		comp := components.PlayerSearchSuggestion(userQuery)
+		comp := components.PlayerSearchSuggestion(userQuery, h.searchPlayers(c, userQuery))
 		c.Render(http.StatusOK, renderer.New(c.Request.Context(), http.StatusOK, comp))
 		return
 	}
 
 	var suggestions []cache.AutocompleteResult
 	if userQuery != "" {
+		_, span := tracing.Start(c.Request.Context(), "cache.autocomplete", attribute.String("autocomplete.query", userQuery))
 		suggestions = h.Cache.AutocompleteRich(userQuery, defaultAutocompleteLimit)
+		span.SetAttributes(attribute.Int("autocomplete.results", len(suggestions)))
+		span.End()
 	}
-	comp := components.ChampionAutocomplete(suggestions, userQuery, h.Config.PatchNumber)
+	if len(suggestions) == 0 && unified && userQuery != "" {
+		if seen := h.searchPlayers(c, userQuery); len(seen) > 0 {
+			c.Render(http.StatusOK, renderer.New(c.Request.Context(), http.StatusOK, components.PlayerAutocomplete(seen)))
+			return
+		}
+	}
+	comp := components.ChampionAutocomplete(suggestions, userQuery, h.Config.Patch())
 	c.Render(http.StatusOK, renderer.New(c.Request.Context(), http.StatusOK, comp))
 }
+
+// searchPlayers returns the seen players whose Riot ID starts with query.
+func (h *AutocompleteHandler) searchPlayers(c *gin.Context, query string) []cache.SeenPlayer {
+	_, span := tracing.Start(c.Request.Context(), "players.autocomplete", attribute.String("autocomplete.query", query))
+	defer span.End()
+	seen := h.Players.Search(query, defaultAutocompleteLimit)
+	span.SetAttributes(attribute.Int("autocomplete.results", len(seen)))
+	return seen
+}
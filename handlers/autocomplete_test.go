@@ -17,17 +17,24 @@ import (
 func newTestAutocompleteHandler() *AutocompleteHandler {
 	c := cache.New("", 3)
 	c.SetChampionMap(map[string]string{
-		"Aatrox":     "Aatrox",
-		"Ahri":       "Ahri",
-		"Ashe":       "Ashe",
-		"Akali":      "Akali",
-		"Blitzcrank": "Blitzcrank",
-		"Brand":      "Brand",
+		"Aatrox":       "Aatrox",
+		"Ahri":         "Ahri",
+		"Ashe":         "Ashe",
+		"Akali":        "Akali",
+		"Blitzcrank":   "Blitzcrank",
+		"Brand":        "Brand",
+		"Miss Fortune": "MissFortune",
 	})
+	players := cache.NewPlayers("", 0)
+	players.Observe("kr", cache.SeenPlayer{PUUID: "p1", GameName: "Faker", TagLine: "KR1"})
+	players.Observe("euw1", cache.SeenPlayer{PUUID: "p2", GameName: "Ahri Main", TagLine: "EUW"})
+	cfg := &config.AppConfig{}
+	cfg.SetPatch("15.9.1")
 	return &AutocompleteHandler{
-		Logger: log.New(os.Stderr),
-		Cache:  c,
-		Config: &config.AppConfig{PatchNumber: "15.9.1"},
+		Logger:  log.New(os.Stderr),
+		Cache:   c,
+		Players: players,
+		Config:  cfg,
 	}
 }
 
@@ -77,6 +84,12 @@ func TestAutocompleteGET(t *testing.T) {
 			wantStatus:   http.StatusOK,
 			wantContains: "Blitzcrank",
 		},
+		{
+			name:         "alias match names the alias",
+			query:        "mf",
+			wantStatus:   http.StatusOK,
+			wantContains: "Miss Fortune <span class=\"ml-1 text-xs font-normal text-slate-400\">“mf”",
+		},
 	}
 
 	for _, tt := range tests {
@@ -109,3 +122,57 @@ func TestAutocompleteGET(t *testing.T) {
 		})
 	}
 }
+
+func TestAutocompleteGET_Players(t *testing.T) {
+	h := newTestAutocompleteHandler()
+	r := gin.New()
+	r.GET("/autocomplete", h.AutocompleteGET)
+
+	tests := []struct {
+		name        string
+		query       string
+		want        []string
+		wantMissing []string
+	}{
+		{
+			name:  "riot ID lists seen players after the search suggestion",
+			query: "q=" + url.QueryEscape("faker#k"),
+			want:  []string{"Search player: faker#k", "Faker<span class=\"font-normal text-slate-400\">#KR1", "/search?q=Faker%23KR1", "kr"},
+		},
+		{
+			name:        "unified search falls back to players",
+			query:       "q=faker",
+			want:        []string{"Faker"},
+			wantMissing: []string{"No champions found"},
+		},
+		{
+			name:        "champion matches come first",
+			query:       "q=ahri",
+			want:        []string{"/champion?champion=Ahri"},
+			wantMissing: []string{"Ahri Main"},
+		},
+		{
+			name:        "champion form never suggests players",
+			query:       "champion=faker",
+			want:        []string{"No champions found"},
+			wantMissing: []string{"Faker"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/autocomplete?"+tt.query, nil))
+			body := w.Body.String()
+			for _, s := range tt.want {
+				if !strings.Contains(body, s) {
+					t.Errorf("body does not contain %q:\n%s", s, body)
+				}
+			}
+			for _, s := range tt.wantMissing {
+				if strings.Contains(body, s) {
+					t.Errorf("body contains %q:\n%s", s, body)
+				}
+			}
+		})
+	}
+}
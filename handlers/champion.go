@@ -14,11 +14,13 @@ import (
 	"github.com/klnstprx/lolMatchup/components"
 	"github.com/klnstprx/lolMatchup/config"
 	"github.com/klnstprx/lolMatchup/renderer"
+	"github.com/klnstprx/lolMatchup/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type ChampionHandler struct {
 	Logger *log.Logger
-	Cache  *cache.Cache
+	Cache  cache.Store
 	Client *client.Client
 	Config *config.AppConfig
 }
@@ -84,6 +86,7 @@ func (h *ChampionHandler) ChampionGET(c *gin.Context) {
 // lookupChampion performs a champion lookup for server-side rendering.
 // On failure, returns a ChampionResult with the Error field set.
 func (h *ChampionHandler) lookupChampion(ctx context.Context, inputName string) *components.ChampionResult {
+	_, span := tracing.Start(ctx, "cache.lookupChampion", attribute.String("champion.query", inputName))
 	championID, err := h.Cache.SearchChampionName(inputName)
 	if err != nil {
 		h.Logger.Debug("champion lookup: name not found in cache", "error", err)
@@ -91,6 +94,8 @@ func (h *ChampionHandler) lookupChampion(ctx context.Context, inputName string)
 	}
 
 	champion, inCache := h.Cache.GetChampionByID(championID)
+	span.SetAttributes(attribute.String("champion.id", championID), attribute.Bool("cache.hit", inCache))
+	span.End()
 	if inCache {
 		return &components.ChampionResult{Champion: champion, Config: h.Config}
 	}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/components"
+	"github.com/klnstprx/lolMatchup/models"
+	"github.com/klnstprx/lolMatchup/renderer"
+)
+
+// ChampionsGET handles /champions, the champion browser. It filters every
+// champion by the position, role, resource, range and damage query params and
+// sorts by the base stat named by sort. HTMX requests (the filter form) get
+// just the grid; others get the whole page.
+func (h *ChampionHandler) ChampionsGET(c *gin.Context) {
+	f := components.ChampionFilter{
+		Position: c.Query("position"),
+		Role:     c.Query("role"),
+		Resource: c.Query("resource"),
+		Range:    c.Query("range"),
+		Damage:   c.Query("damage"),
+		Sort:     c.Query("sort"),
+		Order:    c.Query("order"),
+	}
+	all := h.Cache.AllChampions()
+	v := components.ChampionBrowser{
+		Filter:    f,
+		Champions: filterChampions(all, f),
+		Loaded:    len(all),
+		Known:     h.Cache.GetChampionMapLen(),
+		Patch:     h.Config.Patch(),
+	}
+	v.Positions, v.Roles, v.Resources = championChoices(all)
+
+	// The filter form pushes this URL into history, so a cached grid must not
+	// be reused for a reload, which needs the whole page.
+	c.Writer.Header().Add("Vary", "HX-Request")
+	ctx := c.Request.Context()
+	if c.GetHeader("HX-Request") == "true" {
+		c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, components.ChampionGrid(v)))
+		return
+	}
+	c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, components.ChampionsPage(v)))
+}
+
+// filterChampions returns the champions selected by f, which come sorted by
+// name, in f's order. Champions tied on the sort stat stay in name order.
+func filterChampions(all []models.Champion, f components.ChampionFilter) []models.Champion {
+	out := make([]models.Champion, 0, len(all))
+	for _, champ := range all {
+		switch {
+		case f.Position != "" && !slices.Contains(champ.Positions, f.Position),
+			f.Role != "" && !slices.Contains(champ.Roles, f.Role),
+			f.Resource != "" && champ.Resource != f.Resource,
+			// Range goes by base attack range, so champions that change form
+			// are filed under their starting one: Gnar's 175 makes him melee.
+			f.Range == "melee" && champ.Ranged(),
+			f.Range == "ranged" && !champ.Ranged(),
+			f.Damage != "" && champ.DamageType() != f.Damage:
+			continue
+		}
+		out = append(out, champ)
+	}
+	if stat, ok := models.LookupBaseStat(f.Sort); ok {
+		sort.SliceStable(out, func(i, j int) bool {
+			a, b := stat.Value(out[i].Stats), stat.Value(out[j].Stats)
+			if f.Order == "asc" {
+				return a < b
+			}
+			return a > b
+		})
+	}
+	return out
+}
+
+// championChoices collects the positions, roles and resources found in the
+// champion data, for the filter form.
+func championChoices(all []models.Champion) (positions, roles, resources []string) {
+	for _, champ := range all {
+		positions = append(positions, champ.Positions...)
+		roles = append(roles, champ.Roles...)
+		if champ.Resource != "" {
+			resources = append(resources, champ.Resource)
+		}
+	}
+	// Meraki positions in lane order rather than alphabetically.
+	lanes := map[string]int{"TOP": 0, "JUNGLE": 1, "MIDDLE": 2, "BOTTOM": 3, "SUPPORT": 4}
+	positions = uniqueSorted(positions)
+	sort.SliceStable(positions, func(i, j int) bool {
+		a, aok := lanes[positions[i]]
+		b, bok := lanes[positions[j]]
+		return aok && (!bok || a < b)
+	})
+	return positions, uniqueSorted(roles), uniqueSorted(resources)
+}
+
+func uniqueSorted(s []string) []string {
+	slices.Sort(s)
+	return slices.Compact(s)
+}
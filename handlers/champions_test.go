@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+func browserChampion(key string, positions, roles []string, resource, adaptive string, attackRange, armor float64) models.Champion {
+	champ := models.Champion{Key: key, Name: key, Positions: positions, Roles: roles, Resource: resource, Adaptive: adaptive}
+	champ.Stats.AttackRange.Flat = attackRange
+	champ.Stats.Armor.Flat = armor
+	return champ
+}
+
+func newTestBrowserHandler() *ChampionHandler {
+	h := newTestChampionHandler(nil)
+	h.Cache.SetChampionMap(map[string]string{"Aatrox": "Aatrox", "Ahri": "Ahri", "Jinx": "Jinx", "Zed": "Zed"})
+	h.Cache.SetChampions(map[string]models.Champion{
+		"Aatrox": browserChampion("Aatrox", []string{"TOP"}, []string{"JUGGERNAUT"}, "BLOOD_WELL", "PHYSICAL_DAMAGE", 175, 38),
+		"Ahri":   browserChampion("Ahri", []string{"MIDDLE"}, []string{"BURST"}, "MANA", "MAGIC_DAMAGE", 550, 21),
+		"Jinx":   browserChampion("Jinx", []string{"BOTTOM"}, []string{"MARKSMAN"}, "MANA", "PHYSICAL_DAMAGE", 525, 26),
+		"Zed":    browserChampion("Zed", []string{"MIDDLE"}, []string{"ASSASSIN"}, "ENERGY", "PHYSICAL_DAMAGE", 125, 32),
+	})
+	return h
+}
+
+func TestChampionsGET_Filters(t *testing.T) {
+	h := newTestBrowserHandler()
+	r := gin.New()
+	r.GET("/champions", h.ChampionsGET)
+
+	tests := []struct {
+		query string
+		want  []string // champion names in order
+	}{
+		{"", []string{"Aatrox", "Ahri", "Jinx", "Zed"}},
+		{"?position=MIDDLE", []string{"Ahri", "Zed"}},
+		{"?role=MARKSMAN", []string{"Jinx"}},
+		{"?resource=MANA", []string{"Ahri", "Jinx"}},
+		{"?range=melee", []string{"Aatrox", "Zed"}},
+		{"?range=ranged&damage=physical", []string{"Jinx"}},
+		{"?damage=magic", []string{"Ahri"}},
+		{"?sort=armor", []string{"Aatrox", "Zed", "Jinx", "Ahri"}},
+		{"?sort=armor&order=asc&position=MIDDLE", []string{"Ahri", "Zed"}},
+		{"?position=SUPPORT", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/champions"+tc.query, nil)
+			req.Header.Set("HX-Request", "true")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+			body := w.Body.String()
+			if strings.Contains(body, "<html") {
+				t.Error("HTMX request got a full page")
+			}
+			var got []string
+			for _, name := range []string{"Aatrox", "Ahri", "Jinx", "Zed"} {
+				if strings.Contains(body, "champion="+name+"&") {
+					got = append(got, name)
+				}
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("champions = %v, want %v", got, tc.want)
+			}
+			last := -1
+			for _, name := range tc.want {
+				i := strings.Index(body, "champion="+name+"&")
+				if i < last {
+					t.Errorf("%s out of order, want %v", name, tc.want)
+				}
+				last = i
+			}
+			if tc.want == nil && !strings.Contains(body, "No champions match") {
+				t.Error("expected an empty-result message")
+			}
+		})
+	}
+}
+
+func TestChampionsGET_FullPage(t *testing.T) {
+	h := newTestBrowserHandler()
+	h.Cache.SetChampionMap(map[string]string{"Aatrox": "Aatrox", "Ahri": "Ahri", "Jinx": "Jinx", "Zed": "Zed", "Lux": "Lux"})
+	r := gin.New()
+	r.GET("/champions", h.ChampionsGET)
+
+	req := httptest.NewRequest(http.MethodGet, "/champions?position=MIDDLE", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "<html") {
+		t.Fatalf("expected a full page, got %d: %s", w.Code, body)
+	}
+	if got := w.Header().Get("Vary"); got != "HX-Request" {
+		t.Errorf("Vary = %q, want HX-Request so cached grids are not reused for the page", got)
+	}
+	for _, want := range []string{
+		`<option value="MIDDLE" selected>Middle</option>`,
+		`<option value="BLOOD_WELL">Blood well</option>`,
+		"Details for 4 of 5 champions",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+}
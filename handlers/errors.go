@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/client"
 	"github.com/klnstprx/lolMatchup/components"
 	"github.com/klnstprx/lolMatchup/renderer"
 )
@@ -11,3 +12,12 @@ func renderError(c *gin.Context, status int, msg string) {
 	ctx := c.Request.Context()
 	c.Render(status, renderer.New(ctx, status, components.ErrorMessage(msg)))
 }
+
+// permissionDeniedMessage explains a 403 from Riot: once Riot has rejected the
+// key itself it has expired, otherwise the key may lack access to the region.
+func permissionDeniedMessage(c *client.Client) string {
+	if c.Keys.Expired() {
+		return components.KeyExpiredMessage
+	}
+	return "Permission denied: check your Riot API key and region."
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/cache"
+	"github.com/klnstprx/lolMatchup/client"
+	"github.com/klnstprx/lolMatchup/components"
+	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/data"
+	"github.com/klnstprx/lolMatchup/renderer"
+)
+
+// statusProbeTimeout bounds the upstream calls made while building /debug/status.
+const statusProbeTimeout = 5 * time.Second
+
+// HealthHandler serves liveness, readiness and diagnostics endpoints.
+type HealthHandler struct {
+	Logger    *log.Logger
+	Cache     cache.Store
+	Client    *client.Client
+	Config    *config.AppConfig
+	StartedAt time.Time
+
+	// Refresh refetches champion data for the latest patch and returns it;
+	// nil disables CacheRefreshPOST.
+	Refresh func(ctx context.Context) (string, error)
+	// Warmup reports the champion warm-up; nil leaves it off the report.
+	Warmup func() data.WarmupProgress
+}
+
+// NewHealthHandler creates a HealthHandler; uptime is measured from this call.
+func NewHealthHandler(cfg *config.AppConfig, apiClient *client.Client) *HealthHandler {
+	return &HealthHandler{
+		Logger:    cfg.Logger,
+		Cache:     cfg.Cache,
+		Client:    apiClient,
+		Config:    cfg,
+		StartedAt: time.Now(),
+	}
+}
+
+// HealthzGET reports that the process is up and serving requests.
+func (h *HealthHandler) HealthzGET(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ReadyzGET reports whether the server can answer champion lookups: a patch
+// must be set and the champion name map loaded. It answers 503 with the
+// failing checks otherwise.
+func (h *HealthHandler) ReadyzGET(c *gin.Context) {
+	problems := h.readinessProblems()
+	if len(problems) > 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "problems": problems})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "patch": h.Config.Patch()})
+}
+
+// readinessProblems lists the reasons the server is not ready, if any.
+func (h *HealthHandler) readinessProblems() []string {
+	var problems []string
+	if h.Config.Patch() == "" {
+		problems = append(problems, "patch version not set")
+	}
+	if h.Cache.GetChampionMapLen() == 0 {
+		problems = append(problems, "champion map not loaded")
+	}
+	return problems
+}
+
+// DebugStatusGET renders the diagnostics report. Browsers get an HTML page;
+// clients that prefer JSON (or pass format=json) get the raw report.
+func (h *HealthHandler) DebugStatusGET(c *gin.Context) {
+	status := h.buildStatus(c.Request.Context())
+
+	if c.Query("format") == "json" || c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(http.StatusOK, status)
+		return
+	}
+	ctx := c.Request.Context()
+	c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, components.StatusPage(status)))
+}
+
+// buildStatus collects the diagnostics report. The latest patch and the key
+// probe are fetched concurrently under statusProbeTimeout.
+func (h *HealthHandler) buildStatus(ctx context.Context) components.ServerStatus {
+	uptime := time.Since(h.StartedAt)
+	s := components.ServerStatus{
+		Ready:          len(h.readinessProblems()) == 0,
+		Uptime:         uptime.Truncate(time.Second).String(),
+		StartedAt:      h.StartedAt,
+		CurrentPatch:   h.Config.Patch(),
+		SnapshotPatch:  h.Config.SnapshotPatch(),
+		ChampionNames:  h.Cache.GetChampionMapLen(),
+		ChampionKeys:   len(h.Cache.GetChampionKeyMap()),
+		ChampionsData:  h.Cache.GetChampionsLen(),
+		SummonerSpells: h.Cache.GetSummonerSpellsLen(),
+		RiotRegion:     h.Config.RiotRegion,
+	}
+	if h.Warmup != nil {
+		w := h.Warmup()
+		s.Warmup = &w
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, statusProbeTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		latest, err := h.Client.FetchLatestPatch(probeCtx)
+		if err != nil {
+			h.Logger.Debug("status: latest patch probe failed", "error", err)
+			s.LatestPatchErr = err.Error()
+			return
+		}
+		s.LatestPatch = latest
+	}()
+	go func() {
+		defer wg.Done()
+		keyStatus, err := h.Client.ProbeAPIKey(probeCtx, h.Config.RiotRegion)
+		s.KeyStatus = keyStatus
+		if err != nil {
+			h.Logger.Debug("status: API key probe failed", "error", err)
+			s.KeyStatusErr = err.Error()
+		}
+	}()
+	wg.Wait()
+
+	// Read after the probes so their own outcomes are included.
+	s.UpstreamErrors = h.Client.RecentErrorRates()
+	s.Circuits = h.Client.Breakers.States()
+	s.Key = h.Client.Keys.Info()
+	return s
+}
+
+// KeyPOST replaces the Riot API key from the status page form: "key" sets a
+// new key, or action=reload re-reads it from the configuration and
+// riot_api_key_file. Browsers are redirected back to /debug/status; JSON
+// clients get the updated key description. Cross-site posts are refused, since
+// browsers resend Basic auth credentials with them.
+func (h *HealthHandler) KeyPOST(c *gin.Context) {
+	if !sameOrigin(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	keys := h.Client.Keys
+	if keys == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key rotation is not available"})
+		return
+	}
+	if c.PostForm("action") == "reload" {
+		if _, err := keys.Reload("admin reload"); err != nil {
+			h.Logger.Error("Reloading Riot API key failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		key := strings.TrimSpace(c.PostForm("key"))
+		if key == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "key is required"})
+			return
+		}
+		keys.Set(key, "admin")
+	}
+
+	if c.Query("format") == "json" || c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(http.StatusOK, keys.Info())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/debug/status")
+}
+
+// cacheRefreshTimeout bounds an admin cache refresh.
+const cacheRefreshTimeout = 30 * time.Second
+
+// CacheRefreshPOST refetches champion and spell data for the latest patch.
+// Browsers are redirected back to /debug/status; JSON clients get the patch
+// now being served.
+func (h *HealthHandler) CacheRefreshPOST(c *gin.Context) {
+	if !sameOrigin(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	if h.Refresh == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "cache refresh is not available"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), cacheRefreshTimeout)
+	defer cancel()
+	patch, err := h.Refresh(ctx)
+	if err != nil {
+		h.Logger.Error("Cache refresh failed", "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "json" || c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(http.StatusOK, gin.H{"patch": patch})
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/debug/status")
+}
+
+// sameOrigin reports whether r was not sent cross-site. Requests without
+// Origin or Sec-Fetch-Site headers (such as curl) are allowed.
+func sameOrigin(r *http.Request) bool {
+	if r.Header.Get("Sec-Fetch-Site") == "cross-site" {
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
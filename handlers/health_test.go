@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/client"
+	"github.com/klnstprx/lolMatchup/components"
+)
+
+func newTestHealthHandler(transport http.RoundTripper) *HealthHandler {
+	cfg := newTestConfig()
+	cfg.RiotRegion = "na1"
+	apiClient := &client.Client{
+		HTTPClient:        &http.Client{Transport: transport},
+		Logger:            cfg.Logger,
+		DDragonVersionURL: "http://ddragon.test/api/versions.json",
+		Keys:              client.NewKeyProvider("test-api-key", nil),
+	}
+	return NewHealthHandler(cfg, apiClient)
+}
+
+func TestHealthz(t *testing.T) {
+	h := newTestHealthHandler(multiTransport{})
+	r := gin.New()
+	r.GET("/healthz", h.HealthzGET)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status: got %d, want 200", w.Code)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	tests := []struct {
+		name       string
+		patch      string
+		champions  map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{"empty cache", "", nil, http.StatusServiceUnavailable, "champion map not loaded"},
+		{"missing patch", "", map[string]string{"Ahri": "Ahri"}, http.StatusServiceUnavailable, "patch version not set"},
+		{"ready", "15.1.1", map[string]string{"Ahri": "Ahri"}, http.StatusOK, "15.1.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHealthHandler(multiTransport{})
+			h.Config.SetPatch(tt.patch)
+			if tt.champions != nil {
+				h.Cache.SetChampionMap(tt.champions)
+			}
+			r := gin.New()
+			r.GET("/readyz", h.ReadyzGET)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body should contain %q, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDebugStatus(t *testing.T) {
+	h := newTestHealthHandler(multiTransport{routes: map[string]*http.Response{
+		"/api/versions.json": {
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`["15.2.1","15.1.1"]`)),
+		},
+		"/lol/status/v4/platform-data": {
+			StatusCode: http.StatusForbidden,
+			Body:       io.NopCloser(strings.NewReader(`{"status":{"status_code":403}}`)),
+		},
+	}})
+	h.Config.SetPatch("15.1.1")
+	h.Cache.SetChampionMap(map[string]string{"Ahri": "Ahri", "Aatrox": "Aatrox"})
+
+	r := gin.New()
+	r.GET("/debug/status", h.DebugStatusGET)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/status?format=json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200", w.Code)
+	}
+
+	var s components.ServerStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, w.Body.String())
+	}
+	if !s.Ready || s.ChampionNames != 2 {
+		t.Errorf("unexpected readiness/cache sizes: %+v", s)
+	}
+	if s.LatestPatch != "15.2.1" || !s.PatchStale() {
+		t.Errorf("latest patch: got %q (stale=%v)", s.LatestPatch, s.PatchStale())
+	}
+	if s.KeyStatus != client.KeyInvalid {
+		t.Errorf("key status: got %q, want %q", s.KeyStatus, client.KeyInvalid)
+	}
+	if !s.Key.Expired || s.Key.Hint != "…-key" {
+		t.Errorf("key info: got %+v, want expired with hint …-key", s.Key)
+	}
+
+	var riotHost *client.HostErrorRate
+	for i := range s.UpstreamErrors {
+		if s.UpstreamErrors[i].Host == "na1.api.riotgames.com" {
+			riotHost = &s.UpstreamErrors[i]
+		}
+	}
+	if riotHost == nil || riotHost.Errors != 1 || riotHost.Total != 1 {
+		t.Errorf("expected one failed call to the Riot host, got %+v", s.UpstreamErrors)
+	}
+}
+
+func TestDebugStatus_HTML(t *testing.T) {
+	h := newTestHealthHandler(multiTransport{})
+	r := gin.New()
+	r.GET("/debug/status", h.DebugStatusGET)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/debug/status", nil)
+	req.Header.Set("Accept", "text/html")
+	r.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	for _, want := range []string{"Server Status", "Not ready", "unavailable"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page should contain %q", want)
+		}
+	}
+}
+
+func TestKeyPOST(t *testing.T) {
+	post := func(h *HealthHandler, form url.Values, header map[string]string) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.POST("/debug/key", h.KeyPOST)
+		req := httptest.NewRequest(http.MethodPost, "/debug/key", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("set", func(t *testing.T) {
+		h := newTestHealthHandler(multiTransport{})
+		w := post(h, url.Values{"key": {" RGAPI-new-1234 "}}, nil)
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/debug/status" {
+			t.Errorf("got %d to %q, want 303 to /debug/status", w.Code, w.Header().Get("Location"))
+		}
+		if got := h.Client.Keys.Key(); got != "RGAPI-new-1234" {
+			t.Errorf("key: got %q", got)
+		}
+		if info := h.Client.Keys.Info(); info.Source != "admin" {
+			t.Errorf("source: got %q, want admin", info.Source)
+		}
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		h := newTestHealthHandler(multiTransport{})
+		h.Client.Keys = client.NewKeyProvider("RGAPI-old", func() (string, error) { return "RGAPI-rotated", nil })
+		w := post(h, url.Values{"action": {"reload"}}, map[string]string{"Accept": "application/json"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("status: got %d, want 200", w.Code)
+		}
+		var info client.KeyInfo
+		if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+			t.Fatalf("invalid JSON: %v", err)
+		}
+		if h.Client.Keys.Key() != "RGAPI-rotated" || info.Source != "admin reload" {
+			t.Errorf("key %q, info %+v", h.Client.Keys.Key(), info)
+		}
+		if strings.Contains(w.Body.String(), "RGAPI-rotated") {
+			t.Error("response must not include the key")
+		}
+	})
+
+	t.Run("empty key", func(t *testing.T) {
+		h := newTestHealthHandler(multiTransport{})
+		if w := post(h, url.Values{"key": {"  "}}, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("status: got %d, want 400", w.Code)
+		}
+		if h.Client.Keys.Key() != "test-api-key" {
+			t.Error("key should be unchanged")
+		}
+	})
+
+	t.Run("cross-site", func(t *testing.T) {
+		h := newTestHealthHandler(multiTransport{})
+		for _, header := range []map[string]string{
+			{"Origin": "https://evil.example"},
+			{"Sec-Fetch-Site": "cross-site"},
+		} {
+			if w := post(h, url.Values{"key": {"RGAPI-evil"}}, header); w.Code != http.StatusForbidden {
+				t.Errorf("%v: got %d, want 403", header, w.Code)
+			}
+		}
+		if h.Client.Keys.Key() != "test-api-key" {
+			t.Error("key should be unchanged")
+		}
+	})
+}
+
+func TestCacheRefreshPOST(t *testing.T) {
+	post := func(h *HealthHandler, header map[string]string) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.POST("/debug/cache/refresh", h.CacheRefreshPOST)
+		req := httptest.NewRequest(http.MethodPost, "/debug/cache/refresh", nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	h := newTestHealthHandler(multiTransport{})
+	if w := post(h, nil); w.Code != http.StatusNotFound {
+		t.Errorf("without a refresher: got %d, want 404", w.Code)
+	}
+
+	calls := 0
+	h.Refresh = func(ctx context.Context) (string, error) {
+		calls++
+		return "15.1.1", nil
+	}
+	w := post(h, map[string]string{"Accept": "application/json"})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"patch":"15.1.1"`) || calls != 1 {
+		t.Errorf("got %d (%d calls): %s", w.Code, calls, w.Body.String())
+	}
+	if w := post(h, nil); w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/debug/status" {
+		t.Errorf("browser: got %d to %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := post(h, map[string]string{"Origin": "https://evil.example"}); w.Code != http.StatusForbidden || calls != 2 {
+		t.Errorf("cross-site: got %d (%d calls)", w.Code, calls)
+	}
+
+	h.Refresh = func(ctx context.Context) (string, error) { return "", errors.New("ddragon down") }
+	if w := post(h, nil); w.Code != http.StatusBadGateway {
+		t.Errorf("failed refresh: got %d, want 502", w.Code)
+	}
+}
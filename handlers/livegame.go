@@ -8,15 +8,22 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/cache"
 	"github.com/klnstprx/lolMatchup/client"
 	"github.com/klnstprx/lolMatchup/components"
 	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/metrics"
 	"github.com/klnstprx/lolMatchup/models"
+	"github.com/klnstprx/lolMatchup/notes"
 	"github.com/klnstprx/lolMatchup/renderer"
+	"github.com/klnstprx/lolMatchup/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // LiveGameHandler handles live game search requests.
@@ -24,6 +31,7 @@ type LiveGameHandler struct {
 	Logger *log.Logger
 	Client *client.Client
 	Config *config.AppConfig
+	Notes  *notes.Store // optional; shows the team's notes on each matchup
 }
 
 // NewLiveGameHandler creates a LiveGameHandler.
@@ -55,7 +63,7 @@ func (h *LiveGameHandler) LiveGameGET(c *gin.Context) {
 	gameName, tagLine := idParts[0], idParts[1]
 
 	// Step 1: Fetch encrypted PUUID via account-v1
-	acct, err := h.Client.FetchAccountByRiotID(ctx, gameName, tagLine, h.Config.RiotRegion, h.Config.RiotAPIKey)
+	acct, err := h.Client.FetchAccountByRiotID(ctx, gameName, tagLine, h.Config.RiotRegion)
 	if err != nil {
 		switch {
 		case errors.Is(err, client.ErrAccountNotFound):
@@ -63,9 +71,13 @@ func (h *LiveGameHandler) LiveGameGET(c *gin.Context) {
 			h.Logger.Debug("Account not found", "riotID", gameName+"#"+tagLine)
 			return
 		case errors.Is(err, client.ErrPermissionDenied):
-			renderError(c, http.StatusForbidden, "Permission denied: check your Riot API key and region.")
+			renderError(c, http.StatusForbidden, permissionDeniedMessage(h.Client))
 			h.Logger.Error("Permission denied fetching account info", "error", err)
 			return
+		case client.IsDegraded(err):
+			h.Logger.Warn("Riot API degraded fetching account info", "error", err)
+			renderError(c, http.StatusServiceUnavailable, components.DegradedMessage)
+			return
 		default:
 			h.Logger.Error("Error fetching account info", "error", err)
 			renderError(c, http.StatusInternalServerError, "Error fetching account data.")
@@ -74,7 +86,7 @@ func (h *LiveGameHandler) LiveGameGET(c *gin.Context) {
 	}
 
 	// Step 2: Fetch current game via spectator-v5 using PUUID
-	activeGame, err := h.Client.FetchCurrentGameByPUUID(ctx, acct.PUUID, h.Config.RiotRegion, h.Config.RiotAPIKey)
+	activeGame, err := h.Client.FetchCurrentGameByPUUID(ctx, acct.PUUID, h.Config.RiotRegion)
 	if err != nil {
 		switch {
 		case errors.Is(err, client.ErrGameNotFound):
@@ -82,9 +94,13 @@ func (h *LiveGameHandler) LiveGameGET(c *gin.Context) {
 			h.Logger.Debug("No active game for account", "riotID", gameName+"#"+tagLine)
 			return
 		case errors.Is(err, client.ErrPermissionDenied):
-			renderError(c, http.StatusForbidden, "Permission denied: check your Riot API key and region.")
+			renderError(c, http.StatusForbidden, permissionDeniedMessage(h.Client))
 			h.Logger.Error("Permission denied fetching active game", "error", err)
 			return
+		case client.IsDegraded(err):
+			h.Logger.Warn("Riot API degraded fetching active game", "error", err)
+			renderError(c, http.StatusServiceUnavailable, components.DegradedMessage)
+			return
 		default:
 			h.Logger.Error("Error fetching active game", "error", err)
 			renderError(c, http.StatusInternalServerError, "Error fetching live game data.")
@@ -100,9 +116,9 @@ func (h *LiveGameHandler) LiveGameGET(c *gin.Context) {
 	}
 
 	// Enrich opponents with recent match data (best-effort, non-blocking)
-	h.enrichOpponents(ctx, vd.parts)
+	degraded := h.enrichOpponents(ctx, vd.parts)
 
-	cmp := components.LiveGameInfo(vd.parts, h.Config, riotID, vd.userChampionName, vd.userChampionID, vd.enemyBans, vd.userBans, vd.gameStartTime)
+	cmp := components.LiveGameInfo(vd.parts, h.Config, riotID, vd.userChampionName, vd.userChampionID, vd.enemyBans, vd.userBans, vd.gameStartTime, degraded)
 	c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, cmp))
 }
 
@@ -118,31 +134,32 @@ func (h *LiveGameHandler) PlayerLiveGameGET(c *gin.Context) {
 		return
 	}
 
-	activeGame, err := h.Client.FetchCurrentGameByPUUID(ctx, puuid, h.Config.RiotRegion, h.Config.RiotAPIKey)
+	activeGame, err := h.Client.FetchCurrentGameByPUUID(ctx, puuid, h.Config.RiotRegion)
 	if err != nil {
 		if errors.Is(err, client.ErrGameNotFound) {
 			// Not in game — render status with polling
-			cmp := components.LiveGameStatus(false, riotID, nil, h.Config, "", "", puuid, time.Now(), nil, nil, 0)
+			cmp := components.LiveGameStatus(false, riotID, nil, h.Config, "", "", puuid, time.Now(), nil, nil, 0, false)
 			c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, cmp))
 			return
 		}
-		// Other errors: graceful degradation, render not-in-game
+		// Other errors: graceful degradation, render not-in-game unless Riot's
+		// API is down, in which case say so rather than imply no game.
 		h.Logger.Debug("player livegame check failed", "puuid", puuid, "error", err)
-		cmp := components.LiveGameStatus(false, riotID, nil, h.Config, "", "", puuid, time.Now(), nil, nil, 0)
+		cmp := components.LiveGameStatus(false, riotID, nil, h.Config, "", "", puuid, time.Now(), nil, nil, 0, client.IsDegraded(err))
 		c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, cmp))
 		return
 	}
 
 	vd := h.buildViewData(activeGame, riotID)
 	if !vd.found {
-		cmp := components.LiveGameStatus(false, riotID, nil, h.Config, "", "", puuid, time.Now(), nil, nil, 0)
+		cmp := components.LiveGameStatus(false, riotID, nil, h.Config, "", "", puuid, time.Now(), nil, nil, 0, false)
 		c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, cmp))
 		return
 	}
 
-	h.enrichOpponents(ctx, vd.parts)
+	degraded := h.enrichOpponents(ctx, vd.parts)
 
-	cmp := components.LiveGameStatus(true, riotID, vd.parts, h.Config, vd.userChampionName, vd.userChampionID, puuid, time.Now(), vd.enemyBans, vd.userBans, vd.gameStartTime)
+	cmp := components.LiveGameStatus(true, riotID, vd.parts, h.Config, vd.userChampionName, vd.userChampionID, puuid, time.Now(), vd.enemyBans, vd.userBans, vd.gameStartTime, degraded)
 	c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, cmp))
 }
 
@@ -237,6 +254,19 @@ func (h *LiveGameHandler) buildViewData(game models.CurrentGameInfo, riotID stri
 		}
 	}
 
+	// Spectator data does not say who laned against whom, so every opponent
+	// gets the notes on facing them with the user's champion in any role,
+	// labelled with the role they are for, and the team's record in lane
+	// against their pick. Without a configured team there is no "our" side
+	// to count, so the record is left out.
+	team := h.Config.Team()
+	for i := range vd.parts {
+		vd.parts[i].Notes = h.Notes.For(vd.userChampionID, vd.parts[i].ChampionID, "")
+		if len(team) > 0 {
+			vd.parts[i].TeamRecord = h.Config.Matches.Total(cache.MatchupQuery{Opponent: vd.parts[i].ChampionID, Team: team})
+		}
+	}
+
 	return vd
 }
 
@@ -247,12 +277,27 @@ const (
 )
 
 // enrichOpponents fetches recent match data for each opponent and attaches enrichment stats.
-// Errors are logged but not propagated (graceful degradation).
-func (h *LiveGameHandler) enrichOpponents(ctx context.Context, opponents []components.OpponentView) {
+// Errors are logged but not propagated (graceful degradation). If any fetch
+// failed because Riot's API is down, all enrichment is dropped so opponents
+// are not compared on incomplete data, and it returns true.
+func (h *LiveGameHandler) enrichOpponents(ctx context.Context, opponents []components.OpponentView) (degraded bool) {
+	ctx, span := tracing.Start(ctx, "livegame.enrichOpponents", attribute.Int("opponents", len(opponents)))
 	enrichCtx, cancel := context.WithTimeout(ctx, enrichTimeout)
 	defer cancel()
 
+	start := time.Now()
+	defer func() {
+		metrics.EnrichmentDuration.Observe(time.Since(start).Seconds())
+		if errors.Is(enrichCtx.Err(), context.DeadlineExceeded) {
+			metrics.EnrichmentTimeouts.Inc()
+			h.Logger.Warn("opponent enrichment timed out", "timeout", enrichTimeout)
+			span.SetStatus(codes.Error, "enrichment timed out")
+		}
+		span.End()
+	}()
+
 	var wg sync.WaitGroup
+	var failed atomic.Bool
 	sem := make(chan struct{}, enrichParallel)
 
 	for i := range opponents {
@@ -265,11 +310,23 @@ func (h *LiveGameHandler) enrichOpponents(ctx context.Context, opponents []compo
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			enrichment := h.computeEnrichment(enrichCtx, opponents[idx].PUUID, opponents[idx].ChampionName)
+			oppCtx, oppSpan := tracing.Start(enrichCtx, "livegame.enrichOpponent",
+				attribute.String("puuid", opponents[idx].PUUID),
+				attribute.String("champion", opponents[idx].ChampionName),
+			)
+			defer oppSpan.End()
+
+			enrichment, enrichDegraded := h.computeEnrichment(oppCtx, opponents[idx].PUUID, opponents[idx].ChampionName)
 			opponents[idx].Enrichment = &enrichment
+			if enrichDegraded {
+				failed.Store(true)
+			}
 
 			// Fetch ranked tier (best-effort)
-			entries, err := h.Client.FetchLeagueEntries(enrichCtx, opponents[idx].PUUID, h.Config.RiotRegion, h.Config.RiotAPIKey)
+			entries, err := h.Client.FetchLeagueEntries(oppCtx, opponents[idx].PUUID, h.Config.RiotRegion)
+			if client.IsDegraded(err) {
+				failed.Store(true)
+			}
 			if err == nil {
 				for _, e := range entries {
 					if e.QueueType == "RANKED_SOLO_5x5" {
@@ -284,16 +341,27 @@ func (h *LiveGameHandler) enrichOpponents(ctx context.Context, opponents []compo
 		}(i)
 	}
 	wg.Wait()
+
+	if !failed.Load() {
+		return false
+	}
+	h.Logger.Warn("opponent enrichment dropped: Riot API degraded")
+	span.SetAttributes(attribute.Bool("degraded", true))
+	for i := range opponents {
+		opponents[i].Enrichment = nil
+		opponents[i].RankedTier, opponents[i].RankedColor = "", ""
+		opponents[i].RankedWins, opponents[i].RankedLosses = 0, 0
+	}
+	return true
 }
 
 // computeEnrichment computes enrichment stats for a single opponent from their recent matches.
-func (h *LiveGameHandler) computeEnrichment(ctx context.Context, puuid, currentChampName string) models.OpponentEnrichment {
-	var e models.OpponentEnrichment
-
-	ids, err := h.Client.FetchMatchIDs(ctx, puuid, h.Config.RiotRegion, h.Config.RiotAPIKey, enrichMatchCount, 0)
+// degraded reports whether a fetch failed because Riot's API is down.
+func (h *LiveGameHandler) computeEnrichment(ctx context.Context, puuid, currentChampName string) (e models.OpponentEnrichment, degraded bool) {
+	ids, err := h.Client.FetchMatchIDs(ctx, puuid, h.Config.RiotRegion, enrichMatchCount, 0)
 	if err != nil {
 		h.Logger.Debug("enrichment: failed to fetch match IDs", "puuid", puuid, "error", err)
-		return e
+		return e, client.IsDegraded(err)
 	}
 
 	e.TotalGames = len(ids)
@@ -305,8 +373,11 @@ func (h *LiveGameHandler) computeEnrichment(ctx context.Context, puuid, currentC
 	matchesFetched := 0
 
 	for _, matchID := range ids {
-		match, fetchErr := h.Client.FetchMatch(ctx, matchID, h.Config.RiotRegion, h.Config.RiotAPIKey)
+		match, fetchErr := h.Client.FetchMatch(ctx, matchID, h.Config.RiotRegion)
 		if fetchErr != nil {
+			if client.IsDegraded(fetchErr) {
+				degraded = true
+			}
 			continue
 		}
 		for _, p := range match.Info.Participants {
@@ -395,5 +466,5 @@ func (h *LiveGameHandler) computeEnrichment(ctx context.Context, puuid, currentC
 		e.PossiblyOffRole = true
 	}
 
-	return e
+	return e, degraded
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -13,7 +14,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/klnstprx/lolMatchup/cache"
 	"github.com/klnstprx/lolMatchup/client"
+	"github.com/klnstprx/lolMatchup/components"
 	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/mockriot"
 	"github.com/klnstprx/lolMatchup/models"
 )
 
@@ -56,7 +59,6 @@ func newTestLiveGameHandler(transport http.RoundTripper) *LiveGameHandler {
 	cfg.Logger = log.New(os.Stderr)
 	cfg.Cache = c
 	cfg.RiotRegion = "na1"
-	cfg.RiotAPIKey = "test-key"
 
 	httpClient := &http.Client{}
 	if transport != nil {
@@ -66,6 +68,7 @@ func newTestLiveGameHandler(transport http.RoundTripper) *LiveGameHandler {
 	return NewLiveGameHandler(cfg, &client.Client{
 		HTTPClient: httpClient,
 		Logger:     cfg.Logger,
+		Keys:       client.NewKeyProvider("test-key", nil),
 	})
 }
 
@@ -220,3 +223,44 @@ func TestLiveGameGET_Success(t *testing.T) {
 		t.Error("expected non-empty body")
 	}
 }
+
+func TestLiveGameGET_RiotDegraded(t *testing.T) {
+	transport := fakeTransport{
+		resp: &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       io.NopCloser(strings.NewReader("")),
+		},
+	}
+	h := newTestLiveGameHandler(transport)
+	r := gin.New()
+	r.GET("/livegame", h.LiveGameGET)
+
+	req := httptest.NewRequest(http.MethodGet, "/livegame?riotID=Player%23NA1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "Riot API degraded") {
+		t.Errorf("expected degraded message, got: %s", w.Body.String())
+	}
+}
+
+func TestEnrichOpponents_RiotDegraded(t *testing.T) {
+	srv := mockriot.New()
+	srv.SetScenario(mockriot.Scenario{Faults: []mockriot.Fault{
+		{PathPrefix: "/lol/league/", Status: http.StatusServiceUnavailable},
+	}})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	h := NewLiveGameHandler(newCassetteConfig(), mockriot.ClientFor(ts.URL, ts.Client()))
+
+	opponents := []components.OpponentView{{PUUID: zanzarahPUUID, ChampionName: "Poppy"}}
+	if !h.enrichOpponents(context.Background(), opponents) {
+		t.Fatal("enrichOpponents() should report degraded")
+	}
+	if opponents[0].Enrichment != nil || opponents[0].RankedTier != "" {
+		t.Errorf("partial enrichment should be dropped, got %+v", opponents[0])
+	}
+}
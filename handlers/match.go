@@ -38,7 +38,7 @@ func (h *MatchHandler) MatchGET(c *gin.Context) {
 
 	puuid := c.Query("puuid")
 
-	match, err := h.Client.FetchMatch(ctx, matchID, h.Config.RiotRegion, h.Config.RiotAPIKey)
+	match, err := h.Client.FetchMatch(ctx, matchID, h.Config.RiotRegion)
 	if err != nil {
 		if errors.Is(err, client.ErrMatchNotFound) {
 			renderError(c, http.StatusNotFound, fmt.Sprintf("Match '%s' not found.", matchID))
@@ -65,7 +65,7 @@ func (h *MatchHandler) MatchPlayerGET(c *gin.Context) {
 		return
 	}
 
-	match, err := h.Client.FetchMatch(ctx, matchID, h.Config.RiotRegion, h.Config.RiotAPIKey)
+	match, err := h.Client.FetchMatch(ctx, matchID, h.Config.RiotRegion)
 	if err != nil {
 		if errors.Is(err, client.ErrMatchNotFound) {
 			renderError(c, http.StatusNotFound, fmt.Sprintf("Match '%s' not found.", matchID))
@@ -77,7 +77,7 @@ func (h *MatchHandler) MatchPlayerGET(c *gin.Context) {
 		return
 	}
 
-	statsCtx := buildPlayerStatsContext(match, puuid, h.Config.PatchNumber)
+	statsCtx := buildPlayerStatsContext(match, puuid, h.Config.Patch())
 	if statsCtx == nil {
 		renderError(c, http.StatusNotFound, "Player not found in this match.")
 		return
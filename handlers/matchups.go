@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/cache"
+	"github.com/klnstprx/lolMatchup/components"
+	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/notes"
+	"github.com/klnstprx/lolMatchup/renderer"
+)
+
+// MatchupsHandler serves matchup statistics over the locally stored matches.
+type MatchupsHandler struct {
+	Logger  *log.Logger
+	Cache   cache.Store
+	Config  *config.AppConfig
+	Matches *cache.Matches
+}
+
+// NewMatchupsHandler creates a MatchupsHandler over the configured match
+// store.
+func NewMatchupsHandler(cfg *config.AppConfig) *MatchupsHandler {
+	return &MatchupsHandler{
+		Logger:  cfg.Logger,
+		Cache:   cfg.Cache,
+		Config:  cfg,
+		Matches: cfg.Matches,
+	}
+}
+
+// MatchupsGET handles GET /matchups/:champion, the lane record of the
+// champion against every opponent in the stored matches, optionally limited
+// by the role and patch query params. With a team configured, only the
+// team's games count unless scope=all. HTMX requests (the filter form) get
+// just the table; others get the whole page.
+func (h *MatchupsHandler) MatchupsGET(c *gin.Context) {
+	champion, ok := resolveChampionKey(h.Cache, c.Param("champion"))
+	if !ok {
+		renderError(c, http.StatusNotFound, "Champion not found.")
+		return
+	}
+	role := c.Query("role")
+	if !notes.ValidRole(role) {
+		role = ""
+	}
+	patches := h.Matches.Patches()
+	patch := c.Query("patch")
+	if !slices.Contains(patches, patch) {
+		patch = ""
+	}
+
+	team := h.Config.Team()
+	hasTeam := len(team) > 0
+	scope := "all"
+	if hasTeam && c.Query("scope") != "all" {
+		scope = "team"
+	} else {
+		team = nil
+	}
+	q := cache.MatchupQuery{Champion: champion, Role: role, Patch: patch, Team: team}
+
+	// Match data spells some champion names differently from the champion
+	// keys (FiddleSticks, Fiddlesticks), so names are looked up case-blind.
+	names := make(map[string]string)
+	for key, name := range championKeyNames(h.Cache) {
+		names[strings.ToLower(key)] = name
+	}
+	v := components.MatchupTable{
+		Champion:     champion,
+		ChampionName: names[strings.ToLower(champion)],
+		Role:         role,
+		Patch:        patch,
+		Patches:      patches,
+		Scope:        scope,
+		HasTeam:      hasTeam,
+		MinGames:     h.Config.MatchupMinGames,
+		Total:        h.Matches.Total(q),
+		Stored:       h.Matches.Len(),
+		AssetPatch:   h.Config.Patch(),
+	}
+	for _, s := range h.Matches.Matchups(q) {
+		row := components.MatchupRow{MatchupStat: s, OpponentName: names[strings.ToLower(s.Opponent)]}
+		if row.OpponentName == "" {
+			row.OpponentName = s.Opponent
+		}
+		if s.Games >= v.MinGames {
+			v.Rows = append(v.Rows, row)
+		} else {
+			v.Few = append(v.Few, row)
+		}
+	}
+
+	// The filter form pushes this URL into history, so a cached table must
+	// not be reused for a reload, which needs the whole page.
+	c.Writer.Header().Add("Vary", "HX-Request")
+	ctx := c.Request.Context()
+	if c.GetHeader("HX-Request") == "true" {
+		c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, components.MatchupTableSection(v)))
+		return
+	}
+	c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, components.MatchupTablePage(v)))
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/cache"
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+// storedMatch returns a ranked match on patch 14.10 where blue's blueChamp
+// and red's redChamp meet in role.
+func storedMatch(id, role, blueChamp, redChamp, blueID, redID string, blueWins bool) models.MatchDTO {
+	blueName, blueTag, _ := strings.Cut(blueID, "#")
+	redName, redTag, _ := strings.Cut(redID, "#")
+	return models.MatchDTO{
+		Metadata: models.MatchMetadata{MatchID: id},
+		Info: models.MatchInfo{GameDuration: 1800, GameVersion: "14.10.585.1", Participants: []models.MatchParticipant{
+			{PUUID: id + "b", RiotIDGameName: blueName, RiotIDTagline: blueTag, TeamID: 100, ChampionName: blueChamp,
+				IndividualPosition: role, Win: blueWins, Kills: 5, Deaths: 1, Assists: 3, GoldEarned: 12000},
+			{PUUID: id + "r", RiotIDGameName: redName, RiotIDTagline: redTag, TeamID: 200, ChampionName: redChamp,
+				IndividualPosition: role, Win: !blueWins, Kills: 1, Deaths: 5, Assists: 2, GoldEarned: 11000},
+		}},
+	}
+}
+
+func newTestMatchupsRouter(matches *cache.Matches, team string) *gin.Engine {
+	cfg := newTestConfig()
+	cfg.Cache.SetChampionMap(map[string]string{"Ahri": "Ahri", "Zed": "Zed", "Miss Fortune": "MissFortune", "Fiddlesticks": "Fiddlesticks"})
+	cfg.Matches = matches
+	cfg.TeamRiotIDs = team
+	cfg.MatchupMinGames = 2
+	h := NewMatchupsHandler(cfg)
+	r := gin.New()
+	r.GET("/matchups/:champion", h.MatchupsGET)
+	return r
+}
+
+func TestMatchupsGET(t *testing.T) {
+	matches := cache.NewMatches("", 0)
+	matches.Observe(storedMatch("EUW1_1", "MIDDLE", "Ahri", "Zed", "Mid#EUW", "Stranger#EUW", true))
+	matches.Observe(storedMatch("EUW1_2", "MIDDLE", "Zed", "Ahri", "Other#EUW", "Mid#EUW", true))
+	matches.Observe(storedMatch("EUW1_3", "MIDDLE", "Ahri", "Zed", "Stranger#EUW", "Other#EUW", true))
+	matches.Observe(storedMatch("EUW1_4", "BOTTOM", "Ahri", "MissFortune", "Mid#EUW", "Adc#EUW", true))
+
+	get := func(r *gin.Engine, path string) string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, w.Code)
+		}
+		if got := w.Header().Get("Vary"); got != "HX-Request" {
+			t.Errorf("GET %s: Vary = %q, want HX-Request", path, got)
+		}
+		return w.Body.String()
+	}
+
+	// Without a team, every stored player counts: Ahri is 2-1 against Zed,
+	// shown in the table, and 1-0 against Miss Fortune, too few games.
+	body := get(newTestMatchupsRouter(matches, ""), "/matchups/ahri")
+	for _, want := range []string{"Ahri in stored matches", "3–1 (75%)", "2–1 (67%)", "Zed", "1 matchups with fewer than 2 games", "Miss Fortune", "+1.0k"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("all players: page missing %q", want)
+		}
+	}
+
+	// With a team, only its games count, unless scope=all.
+	r := newTestMatchupsRouter(matches, "Mid#EUW")
+	body = get(r, "/matchups/Ahri?role=MIDDLE")
+	for _, want := range []string{"Our team on Ahri", "1–1 (50%)", "Our team"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("team: page missing %q", want)
+		}
+	}
+	if body = get(r, "/matchups/Ahri?role=MIDDLE&scope=all"); !strings.Contains(body, "2–1 (67%)") {
+		t.Error("scope=all: expected every player's games")
+	}
+
+	// Match data spells Fiddlesticks "FiddleSticks"; the row still gets
+	// the champion's name, not the raw key.
+	fiddle := cache.NewMatches("", 0)
+	fiddle.Observe(storedMatch("EUW1_5", "JUNGLE", "Zed", "FiddleSticks", "Mid#EUW", "Stranger#EUW", true))
+	if body = get(newTestMatchupsRouter(fiddle, ""), "/matchups/Zed"); !strings.Contains(body, ">Fiddlesticks</span>") {
+		t.Error("differently cased opponent: expected the champion's name")
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/matchups/nobody", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown champion: status %d, want 404", w.Code)
+	}
+}
+
+func TestBuildViewData_TeamRecord(t *testing.T) {
+	h := newTestLiveGameHandler(nil)
+	h.Config.Matches = cache.NewMatches("", 0)
+	h.Config.TeamRiotIDs = "Mid#EUW"
+	h.Config.Matches.Observe(storedMatch("EUW1_1", "MIDDLE", "Aatrox", "Ahri", "Mid#EUW", "Stranger#EUW", false))
+	h.Config.Matches.Observe(storedMatch("EUW1_2", "TOP", "Ahri", "Zed", "Mid#EUW", "Stranger#EUW", true))
+	h.Config.Matches.Observe(storedMatch("EUW1_3", "MIDDLE", "Zed", "Ahri", "Other#EUW", "Stranger#EUW", true))
+	game := models.CurrentGameInfo{Participants: []models.CurrentGameParticipant{
+		{ChampionID: 266, TeamID: 100, RiotID: "Player#NA1"},
+		{ChampionID: 103, TeamID: 200, RiotID: "Enemy#NA1"},
+	}}
+
+	vd := h.buildViewData(game, "Player#NA1")
+	if len(vd.parts) != 1 {
+		t.Fatalf("opponents = %+v", vd.parts)
+	}
+	if rec := vd.parts[0].TeamRecord; rec.Games != 1 || rec.Wins != 0 {
+		t.Errorf("team record against Ahri = %+v, want 0-1", rec)
+	}
+
+	// Without a team there is no side to count: every stored player's games,
+	// opponents' included, would make a meaningless record.
+	h.Config.TeamRiotIDs = ""
+	if rec := h.buildViewData(game, "Player#NA1").parts[0].TeamRecord; rec.Games != 0 {
+		t.Errorf("record without a team = %+v, want none", rec)
+	}
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/auth"
+	"github.com/klnstprx/lolMatchup/cache"
+	"github.com/klnstprx/lolMatchup/components"
+	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/notes"
+	"github.com/klnstprx/lolMatchup/renderer"
+)
+
+// maxAuthorLength bounds the name visitors who are not signed in may sign
+// notes with.
+const maxAuthorLength = 40
+
+// NotesHandler serves the team's matchup notes.
+type NotesHandler struct {
+	Logger *log.Logger
+	Cache  cache.Store
+	Config *config.AppConfig
+	Notes  *notes.Store
+}
+
+// NewNotesHandler creates a NotesHandler backed by store.
+func NewNotesHandler(cfg *config.AppConfig, store *notes.Store) *NotesHandler {
+	return &NotesHandler{
+		Logger: cfg.Logger,
+		Cache:  cfg.Cache,
+		Config: cfg,
+		Notes:  store,
+	}
+}
+
+// NotesGET handles GET /notes?champion=X[&opponent=Y][&role=Z], listing the
+// notes on playing champion against opponent, or against anyone, in role, or
+// in any. HTMX requests get the notes section; others get a page.
+func (h *NotesHandler) NotesGET(c *gin.Context) {
+	champion, ok := h.resolveChampion(c.Query("champion"))
+	if !ok {
+		renderError(c, http.StatusNotFound, "Champion not found.")
+		return
+	}
+	var f noteFilter
+	if q := c.Query("opponent"); q != "" {
+		if f.Opponent, ok = h.resolveChampion(q); !ok {
+			renderError(c, http.StatusNotFound, "Opponent not found.")
+			return
+		}
+	}
+	if role := c.Query("role"); notes.ValidRole(role) {
+		f.Role = role
+	}
+	h.render(c, http.StatusOK, champion, f, "")
+}
+
+// NotePOST adds a note from the form fields champion, opponent, role and
+// body. The note is signed with the visitor's name, or with the "author"
+// field when sign-in is off.
+func (h *NotesHandler) NotePOST(c *gin.Context) {
+	if !sameOrigin(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	champion, ok := h.resolveChampion(c.PostForm("champion"))
+	if !ok {
+		renderError(c, http.StatusUnprocessableEntity, "Champion not found.")
+		return
+	}
+	filter := h.filter(c)
+	opponent, ok := h.resolveChampion(c.PostForm("opponent"))
+	if !ok {
+		h.render(c, http.StatusUnprocessableEntity, champion, filter, "Choose the champion this note is about.")
+		return
+	}
+	note, err := h.Notes.Add(champion, opponent, c.PostForm("role"), c.PostForm("body"), h.author(c))
+	if err != nil {
+		h.renderStoreError(c, err, champion, filter)
+		return
+	}
+	h.Logger.Info("Matchup note added", "id", note.ID, "champion", champion, "opponent", opponent, "author", note.Author)
+	h.done(c, champion, filter)
+}
+
+// NoteEditPOST replaces the body and role of the note named by "id".
+func (h *NotesHandler) NoteEditPOST(c *gin.Context) {
+	if !sameOrigin(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	note, ok := h.editable(c)
+	if !ok {
+		return
+	}
+	filter := h.filter(c)
+	if _, err := h.Notes.Update(note.ID, c.PostForm("role"), c.PostForm("body"), h.author(c)); err != nil {
+		h.renderStoreError(c, err, note.Champion, filter)
+		return
+	}
+	h.Logger.Info("Matchup note edited", "id", note.ID, "editor", h.author(c))
+	h.done(c, note.Champion, filter)
+}
+
+// NoteDeletePOST deletes the note named by "id".
+func (h *NotesHandler) NoteDeletePOST(c *gin.Context) {
+	if !sameOrigin(c.Request) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	note, ok := h.editable(c)
+	if !ok {
+		return
+	}
+	if err := h.Notes.Delete(note.ID); err != nil {
+		h.renderStoreError(c, err, note.Champion, h.filter(c))
+		return
+	}
+	h.Logger.Info("Matchup note deleted", "id", note.ID, "by", h.author(c))
+	h.done(c, note.Champion, h.filter(c))
+}
+
+// editable checks the request may change the note named by the "id" form
+// field and returns it, answering the request otherwise.
+func (h *NotesHandler) editable(c *gin.Context) (notes.Note, bool) {
+	note, ok := h.Notes.Get(c.PostForm("id"))
+	if !ok {
+		renderError(c, http.StatusNotFound, "Note not found.")
+		return notes.Note{}, false
+	}
+	if !canEditNote(c, note) {
+		renderError(c, http.StatusForbidden, "Only the author or an admin may change this note.")
+		return notes.Note{}, false
+	}
+	return note, true
+}
+
+// canEditNote reports whether the caller may change note: its author, an
+// admin, or anyone while sign-in is off.
+func canEditNote(c *gin.Context, note notes.Note) bool {
+	p, ok := auth.FromContext(c.Request.Context())
+	return !ok || p.IsAdmin() || p.DisplayName() == note.Author
+}
+
+// author returns the name notes written by the caller are signed with.
+func (h *NotesHandler) author(c *gin.Context) string {
+	if p, ok := auth.FromContext(c.Request.Context()); ok {
+		return p.DisplayName()
+	}
+	name := strings.TrimSpace(c.PostForm("author"))
+	if name == "" {
+		return "Anonymous"
+	}
+	if r := []rune(name); len(r) > maxAuthorLength {
+		name = string(r[:maxAuthorLength])
+	}
+	return name
+}
+
+// noteFilter limits a notes section to the notes against Opponent and for
+// Role; empty fields do not limit.
+type noteFilter struct {
+	Opponent string // champion key
+	Role     string
+}
+
+// filter returns what the notes section the form came from was limited to.
+func (h *NotesHandler) filter(c *gin.Context) noteFilter {
+	var f noteFilter
+	f.Opponent, _ = h.resolveChampion(c.PostForm("opponent_filter"))
+	if role := c.PostForm("role_filter"); notes.ValidRole(role) {
+		f.Role = role
+	}
+	return f
+}
+
+// done answers a successful change: HTMX forms get the updated section,
+// browsers are redirected back to the notes page.
+func (h *NotesHandler) done(c *gin.Context, champion string, f noteFilter) {
+	if c.GetHeader("HX-Request") == "true" {
+		h.render(c, http.StatusOK, champion, f, "")
+		return
+	}
+	q := url.Values{"champion": {champion}}
+	if f.Opponent != "" {
+		q.Set("opponent", f.Opponent)
+	}
+	if f.Role != "" {
+		q.Set("role", f.Role)
+	}
+	c.Redirect(http.StatusSeeOther, "/notes?"+q.Encode())
+}
+
+func (h *NotesHandler) renderStoreError(c *gin.Context, err error, champion string, f noteFilter) {
+	switch {
+	case errors.Is(err, notes.ErrNotFound):
+		renderError(c, http.StatusNotFound, "Note not found.")
+	case errors.Is(err, notes.ErrEmptyBody), errors.Is(err, notes.ErrLongBody),
+		errors.Is(err, notes.ErrInvalidRole), errors.Is(err, notes.ErrNoChampions):
+		msg := err.Error()
+		h.render(c, http.StatusUnprocessableEntity, champion, f, strings.ToUpper(msg[:1])+msg[1:]+".")
+	default:
+		h.Logger.Error("Saving matchup notes failed", "error", err)
+		h.render(c, http.StatusInternalServerError, champion, f, "Could not save the note.")
+	}
+}
+
+// render renders the notes on champion limited by f.
+func (h *NotesHandler) render(c *gin.Context, status int, champion string, f noteFilter, errMsg string) {
+	names := h.championNames()
+	v := components.MatchupNotesView{
+		Champion:     champion,
+		ChampionName: names[champion],
+		Opponent:     f.Opponent,
+		OpponentName: names[f.Opponent],
+		Role:         f.Role,
+		Error:        errMsg,
+		Patch:        h.Config.Patch(),
+	}
+	_, v.SignedIn = auth.FromContext(c.Request.Context())
+	for _, n := range h.Notes.For(champion, f.Opponent, f.Role) {
+		v.Notes = append(v.Notes, components.NoteItem{Note: n, OpponentName: names[n.Opponent], CanEdit: canEditNote(c, n)})
+	}
+	if f.Opponent == "" {
+		for key, name := range names {
+			v.Opponents = append(v.Opponents, components.ChampionOption{Key: key, Name: name})
+		}
+		sort.Slice(v.Opponents, func(i, j int) bool { return v.Opponents[i].Name < v.Opponents[j].Name })
+	}
+
+	ctx := c.Request.Context()
+	if c.GetHeader("HX-Request") == "true" {
+		c.Render(status, renderer.New(ctx, status, components.MatchupNotes(v)))
+		return
+	}
+	c.Render(status, renderer.New(ctx, status, components.MatchupNotesPage(v)))
+}
+
+// championNames maps champion keys to names.
+func (h *NotesHandler) championNames() map[string]string {
+	return championKeyNames(h.Cache)
+}
+
+// championKeyNames maps the champion keys in store to names.
+func championKeyNames(store cache.Store) map[string]string {
+	byName := store.GetChampionMap()
+	names := make(map[string]string, len(byName))
+	for name, key := range byName {
+		names[key] = name
+	}
+	return names
+}
+
+// resolveChampion returns the key of the champion given by key or name.
+func (h *NotesHandler) resolveChampion(input string) (string, bool) {
+	return resolveChampionKey(h.Cache, input)
+}
+
+// resolveChampionKey returns the key of the champion given by key or name,
+// falling back to a fuzzy name search.
+func resolveChampionKey(store cache.Store, input string) (string, bool) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", false
+	}
+	for name, key := range store.GetChampionMap() {
+		if strings.EqualFold(key, input) || strings.EqualFold(name, input) {
+			return key, true
+		}
+	}
+	key, err := store.SearchChampionName(input)
+	return key, err == nil
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/auth"
+	"github.com/klnstprx/lolMatchup/models"
+	"github.com/klnstprx/lolMatchup/notes"
+)
+
+// newTestNotesRouter serves the notes routes as the principal p, or with
+// sign-in off when p is nil.
+func newTestNotesRouter(t *testing.T, store *notes.Store, p *auth.Principal) *gin.Engine {
+	t.Helper()
+	cfg := newTestConfig()
+	cfg.Cache.SetChampionMap(map[string]string{"Ahri": "Ahri", "Zed": "Zed", "Miss Fortune": "MissFortune"})
+	h := NewNotesHandler(cfg, store)
+	r := gin.New()
+	if p != nil {
+		r.Use(func(c *gin.Context) {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), *p))
+		})
+	}
+	r.GET("/notes", h.NotesGET)
+	r.POST("/notes", h.NotePOST)
+	r.POST("/notes/edit", h.NoteEditPOST)
+	r.POST("/notes/delete", h.NoteDeletePOST)
+	return r
+}
+
+func newTestNotesStore(t *testing.T) *notes.Store {
+	t.Helper()
+	s, err := notes.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestNotes_AddAndList(t *testing.T) {
+	store := newTestNotesStore(t)
+	r := newTestNotesRouter(t, store, &auth.Principal{Name: "alice", Role: auth.RoleUser})
+
+	w := postForm(r, "/notes", url.Values{
+		"champion": {"ahri"},
+		"opponent": {"zed"},
+		"role":     {"MIDDLE"},
+		"body":     {"Hold **E** until he ults. <b>"},
+		"author":   {"mallory"}, // ignored when signed in
+	}, nil, nil)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/notes?champion=Ahri" {
+		t.Fatalf("add: status %d, location %q", w.Code, w.Header().Get("Location"))
+	}
+	got := store.For("Ahri", "Zed", "")
+	if len(got) != 1 || got[0].Author != "alice" || got[0].Role != "MIDDLE" {
+		t.Fatalf("stored notes = %+v", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/notes?champion=Ahri&opponent=Zed", nil)
+	req.Header.Set("HX-Request", "true")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body := w.Body.String()
+	for _, want := range []string{"Ahri vs Zed notes", "Hold <strong>E</strong> until he ults. &lt;b&gt;", "alice", "Mid"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("notes section missing %q", want)
+		}
+	}
+	if strings.Contains(body, "<html") {
+		t.Error("HTMX request got a full page")
+	}
+
+	// An empty note is refused with the form shown again.
+	w = postForm(r, "/notes", url.Values{"champion": {"Ahri"}, "opponent": {"Zed"}, "body": {" "}}, nil, map[string]string{"HX-Request": "true"})
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "Note is empty.") {
+		t.Errorf("empty note: status %d, body %s", w.Code, w.Body.String())
+	}
+}
+
+func TestNotes_ByRole(t *testing.T) {
+	store := newTestNotesStore(t)
+	for _, n := range []struct{ role, body string }{{"", "Any lane tip"}, {"MIDDLE", "Mid tip"}, {"TOP", "Top tip"}} {
+		if _, err := store.Add("Ahri", "Zed", n.role, n.body, "alice"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := newTestNotesRouter(t, store, nil)
+
+	// A role lists its own notes and those for every role.
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/notes?champion=Ahri&opponent=Zed&role=MIDDLE", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "Any lane tip") || !strings.Contains(body, "Mid tip") || strings.Contains(body, "Top tip") {
+		t.Errorf("role=MIDDLE: want the Mid and every-role notes, got %s", body)
+	}
+	if !strings.Contains(body, `name="role_filter" value="MIDDLE"`) {
+		t.Error("role=MIDDLE: forms do not keep the role")
+	}
+
+	// Changes made from a role's section return to it.
+	w = postForm(r, "/notes", url.Values{"champion": {"Ahri"}, "opponent": {"Zed"}, "role": {"MIDDLE"}, "body": {"tip"},
+		"opponent_filter": {"Zed"}, "role_filter": {"MIDDLE"}}, nil, nil)
+	if loc := w.Header().Get("Location"); loc != "/notes?champion=Ahri&opponent=Zed&role=MIDDLE" {
+		t.Errorf("add from role section: location %q", loc)
+	}
+}
+
+func TestNotes_CrossOrigin(t *testing.T) {
+	store := newTestNotesStore(t)
+	note, err := store.Add("Ahri", "Zed", "", "original", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestNotesRouter(t, store, nil)
+
+	// Another site's page must not add, edit or delete notes, even with sign-in off.
+	for _, tc := range []struct {
+		path    string
+		form    url.Values
+		headers map[string]string
+	}{
+		{"/notes", url.Values{"champion": {"Ahri"}, "opponent": {"Zed"}, "body": {"x"}}, map[string]string{"Sec-Fetch-Site": "cross-site"}},
+		{"/notes", url.Values{"champion": {"Ahri"}, "opponent": {"Zed"}, "body": {"x"}}, map[string]string{"Origin": "https://evil.example"}},
+		{"/notes/edit", url.Values{"id": {note.ID}, "body": {"changed"}}, map[string]string{"Origin": "https://evil.example"}},
+		{"/notes/delete", url.Values{"id": {note.ID}}, map[string]string{"Sec-Fetch-Site": "cross-site"}},
+	} {
+		if w := postForm(r, tc.path, tc.form, nil, tc.headers); w.Code != http.StatusForbidden {
+			t.Errorf("cross-origin POST %s %v: status %d, want 403", tc.path, tc.headers, w.Code)
+		}
+	}
+	if n, ok := store.Get(note.ID); !ok || n.Body != "original" || store.Len() != 1 {
+		t.Errorf("notes changed by cross-origin posts: %+v (%d notes)", n, store.Len())
+	}
+}
+
+func TestNotes_EditPermissions(t *testing.T) {
+	store := newTestNotesStore(t)
+	note, err := store.Add("Ahri", "Zed", "", "original", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit := url.Values{"id": {note.ID}, "body": {"changed"}, "opponent_filter": {"Zed"}}
+
+	bob := newTestNotesRouter(t, store, &auth.Principal{Name: "bob", Role: auth.RoleUser})
+	if w := postForm(bob, "/notes/edit", edit, nil, nil); w.Code != http.StatusForbidden {
+		t.Errorf("another user's edit: status %d, want 403", w.Code)
+	}
+	if w := postForm(bob, "/notes/delete", edit, nil, nil); w.Code != http.StatusForbidden {
+		t.Errorf("another user's delete: status %d, want 403", w.Code)
+	}
+
+	admin := newTestNotesRouter(t, store, &auth.Principal{Name: "root", Role: auth.RoleAdmin})
+	w := postForm(admin, "/notes/edit", edit, nil, nil)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/notes?champion=Ahri&opponent=Zed" {
+		t.Fatalf("admin edit: status %d, location %q", w.Code, w.Header().Get("Location"))
+	}
+	if n, _ := store.Get(note.ID); n.Body != "changed" || n.EditedBy != "root" || n.Author != "alice" {
+		t.Errorf("edited note = %+v", n)
+	}
+
+	alice := newTestNotesRouter(t, store, &auth.Principal{Name: "alice", Role: auth.RoleUser})
+	if w := postForm(alice, "/notes/delete", edit, nil, map[string]string{"HX-Request": "true"}); w.Code != http.StatusOK {
+		t.Errorf("author delete: status %d", w.Code)
+	}
+	if store.Len() != 0 {
+		t.Error("note not deleted")
+	}
+}
+
+func TestNotes_SignInOff(t *testing.T) {
+	store := newTestNotesStore(t)
+	r := newTestNotesRouter(t, store, nil)
+	postForm(r, "/notes", url.Values{"champion": {"Ahri"}, "opponent": {"Miss Fortune"}, "body": {"tip"}, "author": {"Coach"}}, nil, nil)
+	got := store.For("Ahri", "MissFortune", "")
+	if len(got) != 1 || got[0].Author != "Coach" {
+		t.Fatalf("stored notes = %+v", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/notes?champion=Ahri", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body := w.Body.String()
+	for _, want := range []string{"<html", "Ahri matchup notes", "vs Miss Fortune", `name="author"`, "Edit"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("notes page missing %q", want)
+		}
+	}
+}
+
+func TestBuildViewData_Notes(t *testing.T) {
+	h := newTestLiveGameHandler(nil)
+	h.Notes = newTestNotesStore(t)
+	if _, err := h.Notes.Add("Aatrox", "Ahri", "TOP", "Respect her charm.", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	game := models.CurrentGameInfo{Participants: []models.CurrentGameParticipant{
+		{ChampionID: 266, TeamID: 100, RiotID: "Player#NA1"},
+		{ChampionID: 103, TeamID: 200, RiotID: "Enemy#NA1"},
+	}}
+
+	vd := h.buildViewData(game, "Player#NA1")
+	if len(vd.parts) != 1 || len(vd.parts[0].Notes) != 1 || vd.parts[0].Notes[0].Body != "Respect her charm." {
+		t.Fatalf("opponent notes = %+v", vd.parts)
+	}
+}
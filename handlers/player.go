@@ -9,7 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"time"
+	"sync/atomic"
 
 	"github.com/charmbracelet/log"
 	"github.com/gin-gonic/gin"
@@ -52,8 +52,14 @@ func (h *PlayerHandler) PlayerGET(c *gin.Context) {
 		return
 	}
 
+	// refresh=1 (the Refresh button) bypasses the response cache.
+	lookupCtx := ctx
+	if c.Query("refresh") == "1" {
+		lookupCtx = client.WithForceRefresh(ctx)
+	}
+
 	// Lookup player (returns result with Error set on failure)
-	result := h.lookupPlayer(ctx, riotID)
+	result := h.lookupPlayer(lookupCtx, riotID)
 
 	if isHTMX {
 		if result.Error != "" {
@@ -63,7 +69,7 @@ func (h *PlayerHandler) PlayerGET(c *gin.Context) {
 		cmp := components.PlayerComponent(
 			result.Account, result.Summoner, result.Matches, result.Matchups,
 			result.Config, result.MatchesLoaded, result.MatchesTotal, result.FetchedAt,
-			result.LeagueEntries, result.ChampionPool,
+			result.LeagueEntries, result.ChampionPool, result.Degraded,
 		)
 		c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, cmp))
 		return
@@ -75,44 +81,65 @@ func (h *PlayerHandler) PlayerGET(c *gin.Context) {
 }
 
 // lookupPlayer performs the full player lookup (account → summoner → matches).
-// On failure, returns a PlayerResult with the Error field set.
+// FetchedAt is when the oldest of the responses used was fetched upstream,
+// which may be earlier than now if they came from the response cache.
+// On failure, returns a PlayerResult with the Error field set. If Riot's API
+// fails while ranked data or match history is loading, the result is marked
+// Degraded and that data is left out instead of being shown incomplete.
 func (h *PlayerHandler) lookupPlayer(ctx context.Context, riotID string) *components.PlayerResult {
 	parts := strings.SplitN(riotID, "#", 2)
 	if len(parts) != 2 {
 		return &components.PlayerResult{Error: "Invalid format for Summoner; use nickname#tag."}
 	}
 	gameName, tagLine := parts[0], parts[1]
+	ctx, age := client.TrackDataAge(ctx)
 
-	acct, err := h.Client.FetchAccountByRiotID(ctx, gameName, tagLine, h.Config.RiotRegion, h.Config.RiotAPIKey)
+	acct, err := h.Client.FetchAccountByRiotID(ctx, gameName, tagLine, h.Config.RiotRegion)
 	if err != nil {
 		h.Logger.Debug("player page lookup: account error", "riotID", riotID, "error", err)
 		switch {
 		case errors.Is(err, client.ErrAccountNotFound):
 			return &components.PlayerResult{Error: fmt.Sprintf("Account '%s' not found.", riotID)}
 		case errors.Is(err, client.ErrPermissionDenied):
-			return &components.PlayerResult{Error: "Permission denied: check your Riot API key and region."}
+			return &components.PlayerResult{Error: permissionDeniedMessage(h.Client)}
+		case client.IsDegraded(err):
+			return &components.PlayerResult{Error: components.DegradedMessage}
 		default:
 			return &components.PlayerResult{Error: "Error fetching account data."}
 		}
 	}
 
-	player, err := h.Client.FetchSummonerByPUUID(ctx, acct.PUUID, h.Config.RiotRegion, h.Config.RiotAPIKey)
+	player, err := h.Client.FetchSummonerByPUUID(ctx, acct.PUUID, h.Config.RiotRegion)
 	if err != nil {
 		h.Logger.Debug("player page lookup: summoner error", "riotID", riotID, "error", err)
-		if errors.Is(err, client.ErrSummonerNotFound) {
+		switch {
+		case errors.Is(err, client.ErrSummonerNotFound):
 			return &components.PlayerResult{Error: fmt.Sprintf("Summoner '%s' not found.", riotID)}
+		case client.IsDegraded(err):
+			return &components.PlayerResult{Error: components.DegradedMessage}
 		}
 		return &components.PlayerResult{Error: "Error fetching summoner data."}
 	}
 
 	var leagueEntries []models.LeagueEntryDTO
-	if entries, err := h.Client.FetchLeagueEntries(ctx, acct.PUUID, h.Config.RiotRegion, h.Config.RiotAPIKey); err != nil {
+	entries, err := h.Client.FetchLeagueEntries(ctx, acct.PUUID, h.Config.RiotRegion)
+	if err != nil {
 		h.Logger.Debug("player page lookup: league error", "riotID", riotID, "error", err)
 	} else {
 		leagueEntries = entries
 	}
+	degraded := client.IsDegraded(err)
 
-	matches, fullMatches, loaded, total := h.fetchMatchHistory(ctx, acct.PUUID, 0)
+	matches, fullMatches, loaded, total, historyDegraded := h.fetchMatchHistory(ctx, acct.PUUID, 0)
+	if degraded || historyDegraded {
+		return &components.PlayerResult{
+			Account:   acct,
+			Summoner:  player,
+			Config:    h.Config,
+			FetchedAt: age.FetchedAt(),
+			Degraded:  true,
+		}
+	}
 	matchups := computeMatchupStats(fullMatches, acct.PUUID)
 	championPool := computeChampionPool(matches, 5)
 
@@ -122,7 +149,7 @@ func (h *PlayerHandler) lookupPlayer(ctx context.Context, riotID string) *compon
 		Matches:       matches,
 		Matchups:      matchups,
 		Config:        h.Config,
-		FetchedAt:     time.Now(),
+		FetchedAt:     age.FetchedAt(),
 		MatchesLoaded: loaded,
 		MatchesTotal:  total,
 		LeagueEntries: leagueEntries,
@@ -146,7 +173,12 @@ func (h *PlayerHandler) PlayerMatchesGET(c *gin.Context) {
 		start = 0
 	}
 
-	matches, _, loaded, _ := h.fetchMatchHistory(ctx, puuid, start)
+	matches, _, loaded, _, degraded := h.fetchMatchHistory(ctx, puuid, start)
+	if degraded {
+		cmp := components.DegradedNotice("More matches could not be loaded. Try again in a minute.")
+		c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, cmp))
+		return
+	}
 
 	hasMore := loaded == matchHistoryCount
 	nextStart := start + loaded
@@ -156,15 +188,17 @@ func (h *PlayerHandler) PlayerMatchesGET(c *gin.Context) {
 
 // fetchMatchHistory retrieves recent matches for a player and extracts summaries.
 // Returns condensed summaries, full match DTOs, and counts of loaded/total matches.
-// Errors are logged but not surfaced — match history is non-critical.
-func (h *PlayerHandler) fetchMatchHistory(ctx context.Context, puuid string, start int) ([]models.MatchSummary, []models.MatchDTO, int, int) {
-	ids, err := h.Client.FetchMatchIDs(ctx, puuid, h.Config.RiotRegion, h.Config.RiotAPIKey, matchHistoryCount, start)
+// Errors are logged but not surfaced — match history is non-critical — except
+// that degraded reports whether any fetch failed because Riot's API is down,
+// in which case the page is incomplete for reasons other than missing matches.
+func (h *PlayerHandler) fetchMatchHistory(ctx context.Context, puuid string, start int) ([]models.MatchSummary, []models.MatchDTO, int, int, bool) {
+	ids, err := h.Client.FetchMatchIDs(ctx, puuid, h.Config.RiotRegion, matchHistoryCount, start)
 	if err != nil {
 		h.Logger.Warn("failed to fetch match IDs", "error", err)
-		return nil, nil, 0, 0
+		return nil, nil, 0, 0, client.IsDegraded(err)
 	}
 	if len(ids) == 0 {
-		return nil, nil, 0, 0
+		return nil, nil, 0, 0, false
 	}
 
 	// Fetch all matches concurrently
@@ -176,14 +210,18 @@ func (h *PlayerHandler) fetchMatchHistory(ctx context.Context, puuid string, sta
 	}
 	results := make([]result, len(ids))
 	var wg sync.WaitGroup
+	var degraded atomic.Bool
 
 	for i, matchID := range ids {
 		wg.Add(1)
 		go func(idx int, mid string) {
 			defer wg.Done()
-			match, err := h.Client.FetchMatch(ctx, mid, h.Config.RiotRegion, h.Config.RiotAPIKey)
+			match, err := h.Client.FetchMatch(ctx, mid, h.Config.RiotRegion)
 			if err != nil {
 				h.Logger.Debug("failed to fetch match", "matchId", mid, "error", err)
+				if client.IsDegraded(err) {
+					degraded.Store(true)
+				}
 				return
 			}
 			// Find the target player's participant data
@@ -228,7 +266,7 @@ func (h *PlayerHandler) fetchMatchHistory(ctx context.Context, puuid string, sta
 			fullMatches = append(fullMatches, r.match)
 		}
 	}
-	return summaries, fullMatches, len(summaries), len(ids)
+	return summaries, fullMatches, len(summaries), len(ids), degraded.Load()
 }
 
 // computeChampionPool aggregates champion stats from match summaries.
@@ -6,12 +6,17 @@ import (
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/cache"
 	"github.com/klnstprx/lolMatchup/client"
+	"github.com/klnstprx/lolMatchup/components"
 	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/mockriot"
 	"github.com/klnstprx/lolMatchup/models"
 )
 
@@ -19,7 +24,6 @@ func newTestPlayerHandler(transport http.RoundTripper) *PlayerHandler {
 	cfg := config.New()
 	cfg.Logger = log.New(os.Stderr)
 	cfg.RiotRegion = "na1"
-	cfg.RiotAPIKey = "test-api-key"
 
 	httpClient := &http.Client{}
 	if transport != nil {
@@ -32,6 +36,7 @@ func newTestPlayerHandler(transport http.RoundTripper) *PlayerHandler {
 		Client: &client.Client{
 			HTTPClient: httpClient,
 			Logger:     cfg.Logger,
+			Keys:       client.NewKeyProvider("test-api-key", nil),
 		},
 	}
 }
@@ -141,7 +146,7 @@ func TestPlayerGET(t *testing.T) {
 				},
 			}},
 			wantStatus: http.StatusOK,
-			wantBody:   "Permission denied",
+			wantBody:   "Riot API key expired",
 		},
 	}
 
@@ -169,6 +174,58 @@ func TestPlayerGET(t *testing.T) {
 	}
 }
 
+// countingRiotTransport serves a fixed player and counts account lookups.
+type countingRiotTransport struct {
+	accountCalls atomic.Int32
+}
+
+func (ct *countingRiotTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := "[]"
+	switch {
+	case strings.Contains(req.URL.Path, "by-riot-id"):
+		ct.accountCalls.Add(1)
+		body = `{"puuid":"test-puuid","gameName":"TestPlayer","tagLine":"NA1"}`
+	case strings.Contains(req.URL.Path, "/summoners/"):
+		body = `{"puuid":"test-puuid","summonerLevel":30}`
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestPlayerGET_ResponseCache(t *testing.T) {
+	ct := &countingRiotTransport{}
+	h := newTestPlayerHandler(ct)
+	h.Client.Responses = &client.ResponseCache{
+		KV:   cache.NewMemoryBackend(),
+		TTLs: client.ResponseTTLs{Account: time.Hour, Summoner: time.Hour, League: time.Hour, MatchIDs: time.Hour},
+	}
+	r := gin.New()
+	r.GET("/player", h.PlayerGET)
+
+	get := func(query string) string {
+		req := httptest.NewRequest(http.MethodGet, query, nil)
+		req.Header.Set("HX-Request", "true")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", query, w.Code)
+		}
+		return w.Body.String()
+	}
+
+	body := get("/player?riotID=TestPlayer%23NA1")
+	if !strings.Contains(body, "Updated just now") || !strings.Contains(body, "refresh=1") {
+		t.Errorf("expected data age and a forcing refresh button, got:\n%s", body)
+	}
+	get("/player?riotID=TestPlayer%23NA1")
+	if n := ct.accountCalls.Load(); n != 1 {
+		t.Errorf("account lookups after cached hit: got %d, want 1", n)
+	}
+	get("/player?riotID=TestPlayer%23NA1&refresh=1")
+	if n := ct.accountCalls.Load(); n != 2 {
+		t.Errorf("account lookups after refresh=1: got %d, want 2", n)
+	}
+}
+
 func TestComputeChampionPool(t *testing.T) {
 	matches := []models.MatchSummary{
 		{ChampionName: "Ahri", ChampionID: 103, Win: true, Kills: 8, Deaths: 2, Assists: 10},
@@ -206,3 +263,39 @@ func TestComputeChampionPool(t *testing.T) {
 		t.Errorf("expected 0 champions for nil input, got %d", len(pool3))
 	}
 }
+
+func TestPlayerGET_RiotDegraded(t *testing.T) {
+	srv := mockriot.New()
+	srv.SetScenario(mockriot.Scenario{Faults: []mockriot.Fault{
+		{PathPrefix: "/lol/match/v5/matches/EUW1", Status: http.StatusServiceUnavailable},
+	}})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	cfg := newCassetteConfig()
+	h := NewPlayerHandler(cfg, mockriot.ClientFor(ts.URL, ts.Client()))
+	r := gin.New()
+	r.GET("/player", h.PlayerGET)
+
+	req := httptest.NewRequest(http.MethodGet, "/player?riotID=Zanzarah%231996", nil)
+	req.Header.Set("HX-Request", "true")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, "Zanzarah") {
+		t.Fatalf("profile should still render: status %d\n%s", w.Code, body)
+	}
+	if !strings.Contains(body, "Riot API degraded") {
+		t.Errorf("expected degraded notice, got:\n%s", body)
+	}
+	if strings.Contains(body, "Recent Results") || strings.Contains(body, "Loaded 0 of") {
+		t.Errorf("partial match history should not be shown:\n%s", body)
+	}
+
+	// An account lookup that fails upstream is reported as degraded, not missing.
+	srv.SetScenario(mockriot.Scenario{Faults: []mockriot.Fault{{RiotOnly: true, Status: http.StatusBadGateway}}})
+	if result := h.lookupPlayer(req.Context(), "Zanzarah#1996"); result.Error != components.DegradedMessage {
+		t.Errorf("account error: got %q, want the degraded message", result.Error)
+	}
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/client"
+	"github.com/klnstprx/lolMatchup/components"
+	"github.com/klnstprx/lolMatchup/models"
+	"github.com/klnstprx/lolMatchup/renderer"
+)
+
+const (
+	profileParallel      = 5   // match fetches at a time
+	profileRollingWindow = 5   // games per point of the trend charts
+	profileMinSeconds    = 300 // shorter games are remakes
+)
+
+// PlayerProfileGET handles GET /player/profile?puuid=X, the player's
+// per-role performance over their last profile_matches games. It is loaded
+// on request from the player page since it fetches every one of those
+// matches.
+func (h *PlayerHandler) PlayerProfileGET(c *gin.Context) {
+	ctx := c.Request.Context()
+	puuid := strings.TrimSpace(c.Query("puuid"))
+	if puuid == "" {
+		renderError(c, http.StatusBadRequest, "Missing puuid parameter.")
+		return
+	}
+
+	matches, requested, degraded := h.fetchProfileMatches(ctx, puuid)
+	if degraded {
+		cmp := components.DegradedNotice("The role profile could not be loaded. Try again in a minute.")
+		c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, cmp))
+		return
+	}
+	v := components.RoleProfileView{
+		Profiles:  computeRoleProfiles(matches, puuid, profileRollingWindow),
+		Loaded:    len(matches),
+		Requested: requested,
+		Window:    profileRollingWindow,
+	}
+	c.Render(http.StatusOK, renderer.New(ctx, http.StatusOK, components.RoleProfiles(v)))
+}
+
+// fetchProfileMatches fetches the player's last ProfileMatchCount matches,
+// profileParallel at a time, newest first. Matches that fail to load are
+// left out; degraded reports whether that was because Riot's API is down.
+func (h *PlayerHandler) fetchProfileMatches(ctx context.Context, puuid string) (matches []models.MatchDTO, requested int, degraded bool) {
+	ids, err := h.Client.FetchMatchIDs(ctx, puuid, h.Config.RiotRegion, h.Config.ProfileMatchCount(), 0)
+	if err != nil {
+		h.Logger.Warn("failed to fetch match IDs for role profile", "error", err)
+		return nil, 0, client.IsDegraded(err)
+	}
+
+	results := make([]*models.MatchDTO, len(ids))
+	sem := make(chan struct{}, profileParallel)
+	var wg sync.WaitGroup
+	var down atomic.Bool
+	for i, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			match, err := h.Client.FetchMatch(ctx, id, h.Config.RiotRegion)
+			if err != nil {
+				h.Logger.Debug("failed to fetch match", "matchId", id, "error", err)
+				if client.IsDegraded(err) {
+					down.Store(true)
+				}
+				return
+			}
+			results[i] = &match
+		}()
+	}
+	wg.Wait()
+
+	for _, m := range results {
+		if m != nil {
+			matches = append(matches, *m)
+		}
+	}
+	return matches, len(ids), down.Load()
+}
+
+// computeRoleProfiles aggregates the player's games in matches, newest
+// first, per role, most played role first. Games without a role (ARAM and
+// other modes) and remakes are left out. Rolling holds the average of each
+// game and the window-1 games before it.
+func computeRoleProfiles(matches []models.MatchDTO, puuid string, window int) []models.RoleProfile {
+	byRole := make(map[string][]models.GamePerformance)
+	wins := make(map[string]int)
+	for _, match := range slices.Backward(matches) {
+		if match.Info.GameDuration < profileMinSeconds {
+			continue
+		}
+		sc := buildPlayerStatsContext(match, puuid, "")
+		if sc == nil {
+			continue
+		}
+		role := sc.Player.Position()
+		if role == "" || role == "Invalid" {
+			continue
+		}
+		byRole[role] = append(byRole[role], gamePerformance(sc))
+		if sc.Player.Win {
+			wins[role]++
+		}
+	}
+
+	profiles := make([]models.RoleProfile, 0, len(byRole))
+	for role, games := range byRole {
+		rp := models.RoleProfile{Role: role, Games: len(games), Wins: wins[role], Average: averagePerformance(games)}
+		for i := range games {
+			rp.Rolling = append(rp.Rolling, averagePerformance(games[max(0, i-window+1):i+1]))
+		}
+		profiles = append(profiles, rp)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		if profiles[i].Games != profiles[j].Games {
+			return profiles[i].Games > profiles[j].Games
+		}
+		return profiles[i].Role < profiles[j].Role
+	})
+	return profiles
+}
+
+// gamePerformance scales a player's stats in one game by its length.
+func gamePerformance(sc *models.PlayerStatsContext) models.GamePerformance {
+	p := sc.Player
+	minutes := float64(sc.GameDuration) / 60
+	g := models.GamePerformance{
+		CSPerMin:          float64(p.TotalMinionsKilled+p.NeutralMinionsKilled) / minutes,
+		DamagePerMin:      float64(p.TotalDamageDealtToChampions) / minutes,
+		GoldPerMin:        float64(p.GoldEarned) / minutes,
+		VisionPerMin:      float64(p.VisionScore) / minutes,
+		KillParticipation: sc.KillParticipation,
+		MinutesPerDeath:   (minutes - float64(p.TotalTimeSpentDead)/60) / float64(max(p.Deaths, 1)),
+		DeadShare:         float64(p.TotalTimeSpentDead) / float64(sc.GameDuration),
+	}
+	if sc.TeamTotalDamage > 0 {
+		g.DamageShare = float64(p.TotalDamageDealtToChampions) / float64(sc.TeamTotalDamage)
+	}
+	return g
+}
+
+// averagePerformance returns the mean of each field over games.
+func averagePerformance(games []models.GamePerformance) models.GamePerformance {
+	var sum models.GamePerformance
+	for _, g := range games {
+		sum.CSPerMin += g.CSPerMin
+		sum.DamagePerMin += g.DamagePerMin
+		sum.DamageShare += g.DamageShare
+		sum.GoldPerMin += g.GoldPerMin
+		sum.VisionPerMin += g.VisionPerMin
+		sum.KillParticipation += g.KillParticipation
+		sum.MinutesPerDeath += g.MinutesPerDeath
+		sum.DeadShare += g.DeadShare
+	}
+	if len(games) == 0 {
+		return sum
+	}
+	n := float64(len(games))
+	return models.GamePerformance{
+		CSPerMin:          sum.CSPerMin / n,
+		DamagePerMin:      sum.DamagePerMin / n,
+		DamageShare:       sum.DamageShare / n,
+		GoldPerMin:        sum.GoldPerMin / n,
+		VisionPerMin:      sum.VisionPerMin / n,
+		KillParticipation: sum.KillParticipation / n,
+		MinutesPerDeath:   sum.MinutesPerDeath / n,
+		DeadShare:         sum.DeadShare / n,
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/models"
+)
+
+// profileMatch returns a 20-minute match where "me" plays role with cs
+// minions and deaths deaths, dealing half of its team's damage and taking
+// part in 6 of its 10 kills.
+func profileMatch(id, role string, cs, deaths int, win bool) models.MatchDTO {
+	return models.MatchDTO{
+		Metadata: models.MatchMetadata{MatchID: id},
+		Info: models.MatchInfo{GameDuration: 1200, Participants: []models.MatchParticipant{
+			{PUUID: "me", TeamID: 100, IndividualPosition: role, Win: win, Kills: 4, Deaths: deaths, Assists: 2,
+				TotalMinionsKilled: cs, TotalDamageDealtToChampions: 10000, GoldEarned: 8000, VisionScore: 20,
+				TotalTimeSpentDead: 60 * deaths},
+			{PUUID: "mate", TeamID: 100, Kills: 6, TotalDamageDealtToChampions: 10000},
+			{PUUID: "enemy", TeamID: 200, Kills: 3, TotalDamageDealtToChampions: 15000},
+		}},
+	}
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestComputeRoleProfiles(t *testing.T) {
+	remake := profileMatch("R", "TOP", 10, 0, false)
+	remake.Info.GameDuration = 180
+	matches := []models.MatchDTO{ // newest first
+		profileMatch("4", "MIDDLE", 200, 0, true),
+		profileMatch("3", "MIDDLE", 160, 2, false),
+		profileMatch("2", "TOP", 140, 1, true),
+		profileMatch("1", "MIDDLE", 120, 4, true),
+		profileMatch("A", "Invalid", 0, 0, true), // ARAM
+		remake,
+	}
+
+	got := computeRoleProfiles(matches, "me", 2)
+	if len(got) != 2 || got[0].Role != "MIDDLE" || got[1].Role != "TOP" {
+		t.Fatalf("roles = %+v, want MIDDLE then TOP", got)
+	}
+	mid := got[0]
+	if mid.Games != 3 || mid.Wins != 2 {
+		t.Errorf("MIDDLE games, wins = %d, %d; want 3, 2", mid.Games, mid.Wins)
+	}
+	avg := mid.Average
+	if !approx(avg.CSPerMin, 8) || !approx(avg.DamagePerMin, 500) || !approx(avg.DamageShare, 0.5) ||
+		!approx(avg.GoldPerMin, 400) || !approx(avg.VisionPerMin, 1) || !approx(avg.KillParticipation, 0.6) {
+		t.Errorf("MIDDLE averages = %+v", avg)
+	}
+	// Minutes alive per death: 16/4 = 4, 18/2 = 9 and 20 for the deathless game.
+	if !approx(avg.MinutesPerDeath, 11) || !approx(avg.DeadShare, 0.1) {
+		t.Errorf("MIDDLE death timing = %g min per death, %g dead", avg.MinutesPerDeath, avg.DeadShare)
+	}
+	// Rolling averages over 2 games, oldest first: 6, (6+8)/2, (8+10)/2.
+	var rolling []float64
+	for _, g := range mid.Rolling {
+		rolling = append(rolling, g.CSPerMin)
+	}
+	if len(rolling) != 3 || !approx(rolling[0], 6) || !approx(rolling[1], 7) || !approx(rolling[2], 9) {
+		t.Errorf("MIDDLE rolling CS/min = %v, want [6 7 9]", rolling)
+	}
+}
+
+func TestComputeRoleProfiles_AssignedRole(t *testing.T) {
+	// Riot guessed MIDDLE for a roaming support; the assigned role wins.
+	roamed := profileMatch("2", "MIDDLE", 20, 3, true)
+	roamed.Info.Participants[0].TeamPosition = "UTILITY"
+	matches := []models.MatchDTO{roamed, profileMatch("1", "UTILITY", 30, 2, false)}
+
+	got := computeRoleProfiles(matches, "me", 2)
+	if len(got) != 1 || got[0].Role != "UTILITY" || got[0].Games != 2 {
+		t.Errorf("roles = %+v, want two UTILITY games", got)
+	}
+}
+
+func TestPlayerProfileGET(t *testing.T) {
+	matches := map[string]models.MatchDTO{
+		"NA1_2": profileMatch("NA1_2", "JUNGLE", 100, 1, true),
+		"NA1_1": profileMatch("NA1_1", "JUNGLE", 80, 3, false),
+	}
+	var idsCount string
+	h := newTestPlayerHandler(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body any = []string{"NA1_2", "NA1_1", "NA1_missing"}
+		if strings.Contains(req.URL.Path, "/ids") {
+			idsCount = req.URL.Query().Get("count")
+		} else {
+			m, ok := matches[req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]]
+			if !ok {
+				return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			body = m
+		}
+		raw, _ := json.Marshal(body)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(string(raw)))}, nil
+	}))
+	h.Config.ProfileMatches = 3
+	r := gin.New()
+	r.GET("/player/profile", h.PlayerProfileGET)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/player/profile?puuid=me", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d", w.Code)
+	}
+	if idsCount != "3" {
+		t.Errorf("match IDs requested with count=%q, want 3", idsCount)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"Role Profile", "Last 2 games", "1 could not be loaded", "Jungle", "2 games · 1W 1L", "CS / min", "4.5", "<polyline"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("profile missing %q", want)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/player/profile", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("missing puuid: status %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/client"
+	"github.com/klnstprx/lolMatchup/components"
+	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/mockriot"
+	"github.com/klnstprx/lolMatchup/replay"
+)
+
+// cassetteBaseURL is the upstream base URL recorded in cassettes. Recording
+// routes it to an in-process mockriot server.
+const cassetteBaseURL = "http://mockriot.test"
+
+const zanzarahPUUID = "5O4UWTGk3J65dGoWrf1SDPumvDNqAw4Yv5nVAk3klS-IeT3Wv3nOuoH16RLv6dbUVldHWvKJX9_DYQ"
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// newCassetteClient returns a client whose requests are replayed from
+// testdata/cassettes/<name>. Run with LOLMATCHUP_RECORD=1 to re-record the
+// cassette against the mockriot fixtures.
+func newCassetteClient(t *testing.T, name string) *client.Client {
+	t.Helper()
+	var next http.RoundTripper
+	if replay.Recording() {
+		ts := httptest.NewServer(mockriot.New())
+		t.Cleanup(ts.Close)
+		next = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			out := req.Clone(req.Context())
+			out.URL.Host = ts.Listener.Addr().String()
+			return ts.Client().Transport.RoundTrip(out)
+		})
+	}
+	tr := replay.ForTest(t, filepath.Join("testdata", "cassettes", name), next)
+	c := mockriot.ClientFor(cassetteBaseURL, &http.Client{Transport: tr})
+	c.Keys = client.NewKeyProvider("RGAPI-test", nil)
+	return c
+}
+
+func newCassetteConfig() *config.AppConfig {
+	cfg := config.New()
+	cfg.Logger = log.New(io.Discard)
+	cfg.RiotRegion = "euw1"
+	cfg.SetPatch(mockriot.DefaultPatch)
+	return cfg
+}
+
+func TestLookupPlayer_Replay(t *testing.T) {
+	cfg := newCassetteConfig()
+	h := NewPlayerHandler(cfg, newCassetteClient(t, "lookup_player.json.gz"))
+
+	result := h.lookupPlayer(context.Background(), "Zanzarah#1996")
+	if result.Error != "" {
+		t.Fatalf("lookupPlayer() error: %s", result.Error)
+	}
+	if result.Account.PUUID != zanzarahPUUID || result.Summoner.SummonerLevel != 722 {
+		t.Errorf("account/summoner: got %+v / level %d", result.Account, result.Summoner.SummonerLevel)
+	}
+	if len(result.LeagueEntries) != 2 {
+		t.Errorf("league entries: got %d, want 2", len(result.LeagueEntries))
+	}
+	if result.MatchesLoaded != 5 || result.MatchesTotal != 5 || len(result.Matches) != 5 {
+		t.Errorf("matches: loaded %d of %d (%d summaries), want 5 of 5",
+			result.MatchesLoaded, result.MatchesTotal, len(result.Matches))
+	}
+	if result.Matches[0].MatchID != "EUW1_7823196843" || result.Matches[0].ChampionName != "Ornn" {
+		t.Errorf("newest match: got %s on %s", result.Matches[0].MatchID, result.Matches[0].ChampionName)
+	}
+	if len(result.ChampionPool) == 0 || result.ChampionPool[0].ChampionName != "Poppy" || result.ChampionPool[0].Games != 2 {
+		t.Errorf("champion pool: got %+v, want Poppy with 2 games first", result.ChampionPool)
+	}
+	if len(result.Matchups) == 0 {
+		t.Error("expected lane matchups from the match history")
+	}
+	if result.FetchedAt.IsZero() {
+		t.Error("FetchedAt should be set")
+	}
+}
+
+func TestEnrichOpponents_Replay(t *testing.T) {
+	cfg := newCassetteConfig()
+	h := NewLiveGameHandler(cfg, newCassetteClient(t, "enrich_opponents.json.gz"))
+
+	opponents := []components.OpponentView{
+		{PUUID: zanzarahPUUID, ChampionName: "Poppy"},
+		{ChampionName: "Vayne"}, // hidden player: no PUUID, not enriched
+	}
+	h.enrichOpponents(context.Background(), opponents)
+
+	e := opponents[0].Enrichment
+	if e == nil {
+		t.Fatal("opponent was not enriched")
+	}
+	if e.TotalGames != 5 || e.ChampionGames != 2 || e.ChampionWins != 2 {
+		t.Errorf("games: total %d, on champion %d (%d wins); want 5, 2 (2)", e.TotalGames, e.ChampionGames, e.ChampionWins)
+	}
+	if e.WinStreak != 5 || e.RecentWinRate != 1 {
+		t.Errorf("form: streak %d, win rate %.2f; want 5, 1.00", e.WinStreak, e.RecentWinRate)
+	}
+	if opponents[0].RankedTier != "Gold II" || opponents[0].RankedWins != 87 {
+		t.Errorf("ranked: got %q (%dW); want Gold II (87W)", opponents[0].RankedTier, opponents[0].RankedWins)
+	}
+	if opponents[1].Enrichment != nil {
+		t.Error("opponent without PUUID should not be enriched")
+	}
+}
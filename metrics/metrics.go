@@ -0,0 +1,121 @@
+// Package metrics defines the Prometheus collectors exported on /metrics.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "lolmatchup"
+
+// Registry holds every collector in this package plus Go runtime and process
+// metrics. It is separate from the Prometheus default registry so tests and
+// embedding programs don't pick up unrelated collectors.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var (
+	// UpstreamRequests counts outbound API calls by host, client method and
+	// HTTP status ("error" when no response was received).
+	UpstreamRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "requests_total",
+		Help:      "Outbound API requests by host, client method and status.",
+	}, []string{"host", "method", "status"})
+
+	// UpstreamDuration observes outbound API call latency by host and client method.
+	UpstreamDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "request_duration_seconds",
+		Help:      "Outbound API request latency by host and client method.",
+		Buckets:   []float64{.025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"host", "method"})
+
+	// UpstreamRetries counts retried outbound API calls by host and client method.
+	UpstreamRetries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "retries_total",
+		Help:      "Outbound API requests retried after a transient failure, by host and client method.",
+	}, []string{"host", "method"})
+
+	// UpstreamCircuitState reports each upstream host's circuit breaker state:
+	// 0 closed, 1 open, 2 half-open.
+	UpstreamCircuitState = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "upstream",
+		Name:      "circuit_state",
+		Help:      "Circuit breaker state per upstream host (0 closed, 1 open, 2 half-open).",
+	}, []string{"host"})
+
+	// CacheLookups counts cache lookups by cache name and result ("hit" or "miss").
+	CacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "lookups_total",
+		Help:      "Cache lookups by cache and result.",
+	}, []string{"cache", "result"})
+
+	// RateLimitRejections counts requests rejected by the rate limiter, by
+	// route and reason ("client" when the caller used up its own quota,
+	// "busy" when the shared upstream budget stayed exhausted).
+	RateLimitRejections = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ratelimit",
+		Name:      "rejections_total",
+		Help:      "Requests rejected by the rate limiter, by route and reason.",
+	}, []string{"route", "reason"})
+
+	// RateLimitQueued reports requests waiting for the shared upstream budget.
+	RateLimitQueued = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "ratelimit",
+		Name:      "queued_requests",
+		Help:      "Requests waiting for the shared upstream budget.",
+	})
+
+	// EnrichmentDuration observes how long live game opponent enrichment takes.
+	EnrichmentDuration = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "livegame",
+		Name:      "enrichment_duration_seconds",
+		Help:      "Time spent enriching live game opponents with recent match data.",
+		Buckets:   []float64{.25, .5, 1, 2, 4, 6, 8, 10, 15},
+	})
+
+	// EnrichmentTimeouts counts enrichment runs cut short by their deadline.
+	EnrichmentTimeouts = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "livegame",
+		Name:      "enrichment_timeouts_total",
+		Help:      "Live game enrichment runs that hit their timeout.",
+	})
+
+	// HTTPRequestDuration observes handler latency by method, route template and status.
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP handler latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// Handler returns an http.Handler serving Registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerExposesCollectors(t *testing.T) {
+	UpstreamRequests.WithLabelValues("example.test", "FetchMatch", "200").Inc()
+	CacheLookups.WithLabelValues("champion", "hit").Inc()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`lolmatchup_upstream_requests_total{host="example.test",method="FetchMatch",status="200"}`,
+		`lolmatchup_cache_lookups_total{cache="champion",result="hit"}`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
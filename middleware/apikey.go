@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/components"
+)
+
+// KeyExpiredBannerMiddleware marks each request's context while Riot is
+// rejecting the API key, so full pages render a banner. expired reports the
+// key's current state; it clears as soon as the key is replaced.
+func KeyExpiredBannerMiddleware(expired func() bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if expired() {
+			c.Request = c.Request.WithContext(components.WithKeyExpired(c.Request.Context()))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/components"
+	"github.com/klnstprx/lolMatchup/renderer"
+)
+
+func TestKeyExpiredBannerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, expired := range []bool{false, true} {
+		r := gin.New()
+		r.Use(KeyExpiredBannerMiddleware(func() bool { return expired }))
+		r.GET("/", func(c *gin.Context) {
+			c.Render(http.StatusOK, renderer.New(c.Request.Context(), http.StatusOK, components.HomePage()))
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if shown := strings.Contains(w.Body.String(), "Riot API key expired"); shown != expired {
+			t.Errorf("expired %v: banner shown = %v", expired, shown)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/auth"
+	"github.com/klnstprx/lolMatchup/config"
+)
+
+// newTestAuthenticator returns an accounts-mode Authenticator with a user
+// "alice" and an admin "root", and API tokens for each.
+func newTestAuthenticator(t *testing.T) (a *auth.Authenticator, userToken, adminToken string) {
+	t.Helper()
+	cfg := config.New()
+	cfg.AuthMode = "accounts"
+	cfg.UsersFile = filepath.Join(t.TempDir(), "users.json")
+	a, err := auth.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, role := range map[string]auth.Role{"alice": auth.RoleUser, "root": auth.RoleAdmin} {
+		if _, err := a.Users.SetUser(name, "correct horse", role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	userToken, _, _ = a.Users.CreateToken("alice", "")
+	adminToken, _, _ = a.Users.CreateToken("root", "")
+	return a, userToken, adminToken
+}
+
+func TestRequireLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a, userToken, _ := newTestAuthenticator(t)
+	newRouter := func(a *auth.Authenticator) *gin.Engine {
+		r := gin.New()
+		r.Use(AuthMiddleware(a))
+		r.GET("/player", RequireLogin(a), func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+		return r
+	}
+
+	tests := []struct {
+		name         string
+		a            *auth.Authenticator
+		header       map[string]string
+		wantStatus   int
+		wantLocation string
+	}{
+		{"disabled", &auth.Authenticator{Mode: auth.ModeNone}, nil, http.StatusOK, ""},
+		{"browser redirected", a, map[string]string{"Accept": "text/html"}, http.StatusSeeOther, "/login?next=%2Fplayer%3Fq%3Dx"},
+		{"htmx redirected", a, map[string]string{"HX-Request": "true"}, http.StatusUnauthorized, ""},
+		{"api client", a, nil, http.StatusUnauthorized, ""},
+		{"api token", a, map[string]string{"Authorization": "Bearer " + userToken}, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/player?q=x", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			newRouter(tt.a).ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location: got %q, want %q", got, tt.wantLocation)
+			}
+			if tt.header["HX-Request"] != "" && w.Header().Get("HX-Redirect") == "" {
+				t.Error("HTMX requests should get HX-Redirect")
+			}
+		})
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a, userToken, adminToken := newTestAuthenticator(t)
+	open := &auth.Authenticator{Mode: auth.ModeNone}
+	newRouter := func(a *auth.Authenticator, debugToken string) *gin.Engine {
+		r := gin.New()
+		r.Use(AuthMiddleware(a))
+		r.GET("/debug/status", RequireAdmin(a, debugToken, "test"), func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+		return r
+	}
+
+	tests := []struct {
+		name       string
+		a          *auth.Authenticator
+		debugToken string
+		bearer     string
+		wantStatus int
+	}{
+		{"disabled without sign-in or token", open, "", "", http.StatusNotFound},
+		{"debug token without sign-in", open, "s3cret", "s3cret", http.StatusOK},
+		{"missing debug token", open, "s3cret", "", http.StatusUnauthorized},
+		{"anonymous with sign-in", a, "", "", http.StatusUnauthorized},
+		{"non-admin", a, "s3cret", userToken, http.StatusForbidden},
+		{"admin", a, "", adminToken, http.StatusOK},
+		{"debug token with sign-in", a, "s3cret", "s3cret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/debug/status", nil)
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			w := httptest.NewRecorder()
+			newRouter(tt.a, tt.debugToken).ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/metrics"
 )
 
 // LoggerMiddleware logs requests with timing, status, client IP, etc.
@@ -19,6 +21,10 @@ func LoggerMiddleware(logger *log.Logger) gin.HandlerFunc {
 		status := c.Writer.Status()
 		reqID, _ := c.Get(requestIDHeader)
 
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Request.Method, routeLabel(c), strconv.Itoa(status)).
+			Observe(latency.Seconds())
+
 		if c.Request.URL.Path == "/autocomplete" {
 			logger.Debug("Incoming request",
 				"requestID", reqID,
@@ -43,3 +49,12 @@ func LoggerMiddleware(logger *log.Logger) gin.HandlerFunc {
 		)
 	}
 }
+
+// routeLabel returns the matched route template for metric labels, so path
+// parameters and unmatched paths don't create unbounded label values.
+func routeLabel(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return "unmatched"
+}
@@ -1,27 +1,306 @@
 package middleware
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/auth"
 	"github.com/klnstprx/lolMatchup/components"
+	"github.com/klnstprx/lolMatchup/metrics"
 	"github.com/klnstprx/lolMatchup/renderer"
 	"golang.org/x/time/rate"
 )
 
-// RateLimitMiddleware returns a Gin middleware that limits requests using a
-// token bucket. rps is requests per second; burst is the maximum burst size.
-// This is a global (not per-IP) limiter, suitable for protecting upstream API
-// quotas such as Riot API rate limits.
-func RateLimitMiddleware(rps rate.Limit, burst int) gin.HandlerFunc {
-	limiter := rate.NewLimiter(rps, burst)
+// RateLimitConfig sizes a RateLimiter. Rates and bursts are in cost units,
+// where one unit is roughly one upstream Riot API call, so routes can be
+// charged by what they actually spend.
+type RateLimitConfig struct {
+	Rate        rate.Limit    // shared upstream budget, units per second; 0 disables it
+	Burst       int           // shared budget bucket size
+	ClientRate  rate.Limit    // per-client quota, units per second; 0 disables it
+	ClientBurst int           // per-client bucket size
+	MaxWait     time.Duration // how long a request may queue for the shared budget; 0 rejects at once
+}
+
+// RateLimiter protects the Riot API quota with two layers of token buckets.
+// Each client (keyed by API token or IP) has its own quota, so one client
+// spamming refresh is throttled without affecting anyone else. Requests
+// within their quota then draw from a shared budget sized to the upstream
+// limit; when it runs short they queue, and the queue is served round-robin
+// across clients so a client with many queued requests cannot starve the
+// others.
+type RateLimiter struct {
+	cfg    RateLimitConfig
+	global *rate.Limiter
+
+	// Key identifies the client a request is charged to. It defaults to
+	// ClientKey.
+	Key func(*gin.Context) string
+
+	mu          sync.Mutex
+	clients     map[string]*rate.Limiter
+	queues      map[string][]*waiter // pending requests per client, FIFO
+	ring        []string             // clients with pending requests, in service order
+	next        int                  // index into ring of the next client to serve
+	dispatching bool
+	lastSweep   time.Time
+}
+
+// waiter is a request queued for the shared budget. ready is closed once it
+// has been granted or refused; gone is closed if it gives up while the
+// dispatcher waits out its reservation res.
+type waiter struct {
+	cost     int
+	ready    chan struct{}
+	gone     chan struct{}
+	res      *rate.Reservation
+	granted  bool
+	canceled bool
+}
+
+// clientSweepInterval is how often idle clients are dropped from the map.
+const clientSweepInterval = time.Minute
+
+// NewRateLimiter returns a RateLimiter sized by cfg.
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		cfg:     cfg,
+		global:  rate.NewLimiter(cfg.Rate, cfg.Burst),
+		Key:     ClientKey,
+		clients: make(map[string]*rate.Limiter),
+		queues:  make(map[string][]*waiter),
+	}
+}
+
+// ClientKey identifies a client by its account when the auth middleware has
+// signed it in, and otherwise by IP address. Request headers are never used
+// on their own: a client could send a new made-up token with every request
+// and get a fresh quota each time.
+func ClientKey(c *gin.Context) string {
+	if p, ok := auth.FromContext(c.Request.Context()); ok && p.Name != "" {
+		return "user:" + p.Name
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// IPKey identifies a client by IP address alone.
+func IPKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// FormKey returns a Key identifying requests by the value of a form field,
+// case-insensitively, such as the account name a sign-in is for.
+func FormKey(field string) func(*gin.Context) string {
+	return func(c *gin.Context) string {
+		return field + ":" + strings.ToLower(strings.TrimSpace(c.PostForm(field)))
+	}
+}
+
+// Handler returns middleware charging each request cost units. Every
+// response carries RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset
+// headers describing the client's quota; rejected requests get 429 with
+// Retry-After.
+func (l *RateLimiter) Handler(cost int) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !limiter.Allow() {
-			ctx := c.Request.Context()
-			c.Render(http.StatusTooManyRequests, renderer.New(ctx, http.StatusTooManyRequests, components.ErrorMessage("Rate limit exceeded. Please try again shortly.")))
-			c.Abort()
+		now := time.Now()
+		key := l.Key(c)
+
+		var res *rate.Reservation
+		if l.cfg.ClientRate > 0 {
+			quota := l.quota(key, now)
+			res = quota.ReserveN(now, min(cost, l.cfg.ClientBurst))
+			wait := res.DelayFrom(now)
+			if wait > 0 {
+				res.CancelAt(now)
+			}
+			l.setHeaders(c, quota, now)
+			if wait > 0 {
+				l.reject(c, "client", wait, fmt.Sprintf("Too many requests from you. Try again in %d seconds.", retryAfterSeconds(wait)))
+				return
+			}
+		}
+
+		if !l.acquire(c, key, min(cost, l.cfg.Burst)) {
+			if res != nil {
+				// Give the client its quota back. The rate package only refunds
+				// reservations cancelled no later than they took effect.
+				res.CancelAt(now)
+			}
+			l.reject(c, "busy", time.Second, "The server is busy talking to Riot. Please try again shortly.")
 			return
 		}
 		c.Next()
 	}
 }
+
+// quota returns the bucket for key, creating it on first use, and
+// occasionally drops clients whose buckets have refilled.
+func (l *RateLimiter) quota(key string, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) > clientSweepInterval {
+		l.lastSweep = now
+		for k, q := range l.clients {
+			if _, queued := l.queues[k]; !queued && q.TokensAt(now) >= float64(l.cfg.ClientBurst) {
+				delete(l.clients, k)
+			}
+		}
+	}
+	q, ok := l.clients[key]
+	if !ok {
+		q = rate.NewLimiter(l.cfg.ClientRate, l.cfg.ClientBurst)
+		l.clients[key] = q
+	}
+	return q
+}
+
+// acquire takes cost units from the shared budget, queueing for up to MaxWait
+// when it is short. It reports whether the request may proceed.
+func (l *RateLimiter) acquire(c *gin.Context, key string, cost int) bool {
+	if l.cfg.Rate <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	if len(l.ring) == 0 && l.global.AllowN(time.Now(), cost) {
+		l.mu.Unlock()
+		return true
+	}
+	if l.cfg.MaxWait <= 0 {
+		l.mu.Unlock()
+		return false
+	}
+	w := &waiter{cost: cost, ready: make(chan struct{}), gone: make(chan struct{})}
+	if _, ok := l.queues[key]; !ok {
+		l.ring = append(l.ring, key)
+	}
+	l.queues[key] = append(l.queues[key], w)
+	metrics.RateLimitQueued.Inc()
+	if !l.dispatching {
+		l.dispatching = true
+		go l.dispatch()
+	}
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.cfg.MaxWait)
+	defer timer.Stop()
+	select {
+	case <-w.ready:
+	case <-timer.C:
+	case <-c.Request.Context().Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-w.ready:
+		return w.granted
+	default:
+		w.canceled = true // the dispatcher skips it
+		if w.res != nil {
+			// Its reservation has not taken effect yet, so the rate package
+			// still refunds it; wake the dispatcher for the next waiter.
+			w.res.Cancel()
+			close(w.gone)
+		}
+		return false
+	}
+}
+
+// dispatch grants queued requests one at a time, taking the next client in
+// round-robin order each time, until the queue is empty.
+func (l *RateLimiter) dispatch() {
+	for {
+		l.mu.Lock()
+		w := l.popNext()
+		if w == nil {
+			l.dispatching = false
+			l.mu.Unlock()
+			return
+		}
+		now := time.Now()
+		res := l.global.ReserveN(now, w.cost)
+		delay := res.DelayFrom(now)
+		if delay > l.cfg.MaxWait {
+			// It would time out before its turn; refuse it now.
+			res.CancelAt(now)
+			close(w.ready)
+			l.mu.Unlock()
+			continue
+		}
+		w.res = res
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-w.gone:
+			timer.Stop()
+		}
+
+		l.mu.Lock()
+		if !w.canceled {
+			w.granted = true
+			close(w.ready)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// popNext removes and returns the next live waiter, rotating through clients.
+// l.mu must be held.
+func (l *RateLimiter) popNext() *waiter {
+	for len(l.ring) > 0 {
+		if l.next >= len(l.ring) {
+			l.next = 0
+		}
+		key := l.ring[l.next]
+		q := l.queues[key]
+		w := q[0]
+		q = q[1:]
+		metrics.RateLimitQueued.Dec()
+		if len(q) == 0 {
+			delete(l.queues, key)
+			l.ring = append(l.ring[:l.next], l.ring[l.next+1:]...)
+		} else {
+			l.queues[key] = q
+			l.next++
+		}
+		if !w.canceled {
+			return w
+		}
+	}
+	return nil
+}
+
+// setHeaders describes the client's quota using the IETF RateLimit header
+// fields: the bucket size, the units left, and seconds until it is full.
+func (l *RateLimiter) setHeaders(c *gin.Context, q *rate.Limiter, now time.Time) {
+	tokens := max(0, q.TokensAt(now))
+	reset := 0
+	if missing := float64(l.cfg.ClientBurst) - tokens; missing > 0 && l.cfg.ClientRate > 0 {
+		reset = int(math.Ceil(missing / float64(l.cfg.ClientRate)))
+	}
+	c.Header("RateLimit-Limit", strconv.Itoa(l.cfg.ClientBurst))
+	c.Header("RateLimit-Remaining", strconv.Itoa(int(tokens)))
+	c.Header("RateLimit-Reset", strconv.Itoa(reset))
+}
+
+// reject answers 429 with Retry-After and msg.
+func (l *RateLimiter) reject(c *gin.Context, reason string, wait time.Duration, msg string) {
+	metrics.RateLimitRejections.WithLabelValues(routeLabel(c), reason).Inc()
+	c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(wait)))
+	ctx := c.Request.Context()
+	c.Render(http.StatusTooManyRequests, renderer.New(ctx, http.StatusTooManyRequests, components.ErrorMessage(msg)))
+	c.Abort()
+}
+
+// retryAfterSeconds rounds wait up to whole seconds, at least one.
+func retryAfterSeconds(wait time.Duration) int {
+	return max(1, int(math.Ceil(wait.Seconds())))
+}
@@ -1,58 +1,247 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/auth"
 	"golang.org/x/time/rate"
 )
 
-func TestRateLimitMiddleware(t *testing.T) {
+// newLimitedRouter serves GET /test behind l, charging cost per request.
+func newLimitedRouter(l *RateLimiter, cost int) *gin.Engine {
+	r := gin.New()
+	r.GET("/test", l.Handler(cost), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+// get requests /test from ip, with an optional bearer token.
+func get(r *gin.Engine, ip, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.RemoteAddr = ip + ":1234"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiter(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	t.Run("allows requests within limit", func(t *testing.T) {
-		r := gin.New()
-		r.Use(RateLimitMiddleware(10, 10))
-		r.GET("/test", func(c *gin.Context) {
-			c.String(http.StatusOK, "ok")
-		})
-
+		r := newLimitedRouter(NewRateLimiter(RateLimitConfig{Rate: 10, Burst: 10, ClientRate: 10, ClientBurst: 10}), 1)
 		for range 5 {
-			w := httptest.NewRecorder()
-			req := httptest.NewRequest(http.MethodGet, "/test", nil)
-			r.ServeHTTP(w, req)
-			if w.Code != http.StatusOK {
+			if w := get(r, "10.0.0.1", ""); w.Code != http.StatusOK {
 				t.Errorf("expected 200, got %d", w.Code)
 			}
 		}
 	})
 
-	t.Run("rejects requests over limit", func(t *testing.T) {
-		r := gin.New()
-		// burst=2 means only 2 immediate requests allowed
-		r.Use(RateLimitMiddleware(rate.Limit(1), 2))
-		r.GET("/test", func(c *gin.Context) {
-			c.String(http.StatusOK, "ok")
-		})
+	t.Run("limits each client separately", func(t *testing.T) {
+		r := newLimitedRouter(NewRateLimiter(RateLimitConfig{Rate: 100, Burst: 100, ClientRate: rate.Limit(1), ClientBurst: 2}), 1)
 
-		// Exhaust the burst
-		for range 2 {
-			w := httptest.NewRecorder()
-			req := httptest.NewRequest(http.MethodGet, "/test", nil)
-			r.ServeHTTP(w, req)
+		for i := range 2 {
+			w := get(r, "10.0.0.1", "")
 			if w.Code != http.StatusOK {
-				t.Errorf("expected 200 within burst, got %d", w.Code)
+				t.Fatalf("request %d within burst: got %d", i, w.Code)
+			}
+			if got, want := w.Header().Get("RateLimit-Remaining"), []string{"1", "0"}[i]; got != want {
+				t.Errorf("request %d RateLimit-Remaining: got %q, want %q", i, got, want)
 			}
 		}
+		w := get(r, "10.0.0.1", "")
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429 after burst, got %d", w.Code)
+		}
+		if w.Header().Get("Retry-After") != "1" || w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Reset") != "2" {
+			t.Errorf("unexpected headers: %v", w.Header())
+		}
+		if !strings.Contains(w.Body.String(), "Too many requests") {
+			t.Errorf("unexpected body: %s", w.Body.String())
+		}
+
+		if w := get(r, "10.0.0.2", ""); w.Code != http.StatusOK {
+			t.Errorf("another IP should have its own quota, got %d", w.Code)
+		}
+		if w := get(r, "10.0.0.1", "made-up-token"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("an unverified token should share its IP's quota, got %d", w.Code)
+		}
+	})
 
-		// Next request should be rate limited
+	t.Run("charges route cost", func(t *testing.T) {
+		r := newLimitedRouter(NewRateLimiter(RateLimitConfig{Rate: 100, Burst: 100, ClientRate: rate.Limit(0.1), ClientBurst: 10}), 4)
+		for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+			if w := get(r, "10.0.0.1", ""); w.Code != want {
+				t.Errorf("request %d: got %d, want %d", i, w.Code, want)
+			}
+		}
+	})
+
+	t.Run("queues for the shared budget", func(t *testing.T) {
+		r := newLimitedRouter(NewRateLimiter(RateLimitConfig{Rate: 50, Burst: 1, MaxWait: time.Second}), 1)
+		start := time.Now()
+		for i := range 3 {
+			if w := get(r, "10.0.0.1", ""); w.Code != http.StatusOK {
+				t.Errorf("request %d: got %d, want 200 after queueing", i, w.Code)
+			}
+		}
+		if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+			t.Errorf("queued requests should wait for the budget to refill, took %v", elapsed)
+		}
+	})
+
+	t.Run("refunds a waiter that gives up", func(t *testing.T) {
+		r := newLimitedRouter(NewRateLimiter(RateLimitConfig{Rate: 5, Burst: 1, MaxWait: time.Second}), 1)
+		if w := get(r, "10.0.0.1", ""); w.Code != http.StatusOK {
+			t.Fatalf("first request: got %d", w.Code)
+		}
+
+		// The second request queues for 200ms and is canceled halfway.
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx)
+		req.RemoteAddr = "10.0.0.2:1234"
+		time.AfterFunc(100*time.Millisecond, cancel)
 		w := httptest.NewRecorder()
-		req := httptest.NewRequest(http.MethodGet, "/test", nil)
 		r.ServeHTTP(w, req)
 		if w.Code != http.StatusTooManyRequests {
-			t.Errorf("expected 429 after burst, got %d", w.Code)
+			t.Fatalf("canceled request: got %d, want 429", w.Code)
+		}
+
+		// Once the first request's unit has refilled, the next one must not
+		// wait on the canceled reservation.
+		time.Sleep(150 * time.Millisecond)
+		start := time.Now()
+		if w := get(r, "10.0.0.3", ""); w.Code != http.StatusOK {
+			t.Fatalf("next request: got %d", w.Code)
+		}
+		if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+			t.Errorf("next request waited %v for a canceled reservation", elapsed)
+		}
+	})
+
+	t.Run("rejects when the shared budget is exhausted", func(t *testing.T) {
+		l := NewRateLimiter(RateLimitConfig{Rate: rate.Limit(0.01), Burst: 1, ClientRate: rate.Limit(0.01), ClientBurst: 5})
+		r := newLimitedRouter(l, 1)
+		if w := get(r, "10.0.0.1", ""); w.Code != http.StatusOK {
+			t.Fatalf("first request: got %d", w.Code)
+		}
+		w := get(r, "10.0.0.2", "")
+		if w.Code != http.StatusTooManyRequests || !strings.Contains(w.Body.String(), "busy") {
+			t.Fatalf("expected busy 429, got %d: %s", w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != "4" {
+			t.Errorf("the client's quota should only show this request, got remaining %q", got)
+		}
+		if tokens := l.clients["ip:10.0.0.2"].Tokens(); tokens < 4.9 {
+			t.Errorf("a busy rejection should refund the client's quota, tokens %.2f", tokens)
+		}
+	})
+
+	t.Run("zero rates disable limiting", func(t *testing.T) {
+		r := newLimitedRouter(NewRateLimiter(RateLimitConfig{}), 50)
+		for range 3 {
+			w := get(r, "10.0.0.1", "")
+			if w.Code != http.StatusOK {
+				t.Errorf("expected 200, got %d", w.Code)
+			}
+			if w.Header().Get("RateLimit-Limit") != "" {
+				t.Error("no quota headers without a per-client limit")
+			}
 		}
 	})
 }
+
+func TestRateLimiter_FairQueue(t *testing.T) {
+	l := NewRateLimiter(RateLimitConfig{Rate: 1, Burst: 1, MaxWait: time.Second})
+	enqueue := func(key string) *waiter {
+		w := &waiter{cost: 1, ready: make(chan struct{})}
+		if _, ok := l.queues[key]; !ok {
+			l.ring = append(l.ring, key)
+		}
+		l.queues[key] = append(l.queues[key], w)
+		return w
+	}
+	var spam []*waiter
+	for range 3 {
+		spam = append(spam, enqueue("spammer"))
+	}
+	quiet := enqueue("quiet")
+	spam[1].canceled = true
+
+	want := []*waiter{spam[0], quiet, spam[2]}
+	for i, w := range want {
+		if got := l.popNext(); got != w {
+			t.Fatalf("pop %d: got %p, want %p", i, got, w)
+		}
+	}
+	if got := l.popNext(); got != nil || len(l.ring) != 0 || len(l.queues) != 0 {
+		t.Errorf("queue should be empty, got %v (ring %v)", got, l.ring)
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.RemoteAddr = "192.0.2.7:5555"
+	if got := ClientKey(c); got != "ip:192.0.2.7" {
+		t.Errorf("got %q, want ip:192.0.2.7", got)
+	}
+	// A bearer token nobody has checked must not buy a fresh quota.
+	c.Request.Header.Set("Authorization", "Bearer made-up-token")
+	if got := ClientKey(c); got != "ip:192.0.2.7" {
+		t.Errorf("unverified token: got %q, want ip:192.0.2.7", got)
+	}
+
+	c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), auth.Principal{Name: "alice"}))
+	if got := ClientKey(c); got != "user:alice" {
+		t.Errorf("signed-in clients should be keyed by account, got %q", got)
+	}
+}
+
+func TestLoginKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("username=+Alice+&password=x"))
+	c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Request.Header.Set("Authorization", "Bearer made-up-token")
+	c.Request.RemoteAddr = "192.0.2.7:5555"
+	c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), auth.Principal{Name: "bob"}))
+
+	if got := IPKey(c); got != "ip:192.0.2.7" {
+		t.Errorf("IPKey = %q, want ip:192.0.2.7", got)
+	}
+	if got := FormKey("username")(c); got != "username:alice" {
+		t.Errorf("FormKey(username) = %q, want username:alice", got)
+	}
+}
+
+func TestRateLimiter_LoginByName(t *testing.T) {
+	l := NewRateLimiter(RateLimitConfig{ClientRate: rate.Limit(0.01), ClientBurst: 2})
+	l.Key = FormKey("username")
+	r := gin.New()
+	r.POST("/login", l.Handler(1), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Guesses at one account from ever-changing IPs and tokens share a bucket.
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("username=alice&password=guess"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer fake-"+strconv.Itoa(i))
+		req.RemoteAddr = "10.0.0." + strconv.Itoa(i+1) + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("guess %d: got %d, want %d", i, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/components"
+)
+
+// SnapshotBannerMiddleware marks each request's context while the app is
+// serving the bundled offline snapshot, so full pages render a banner. patch
+// returns the snapshot's patch, or "" once live data has replaced it.
+func SnapshotBannerMiddleware(patch func() string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p := patch(); p != "" {
+			c.Request = c.Request.WithContext(components.WithSnapshotPatch(c.Request.Context(), p))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/components"
+	"github.com/klnstprx/lolMatchup/renderer"
+)
+
+func TestSnapshotBannerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, patch := range []string{"", "15.1.1"} {
+		r := gin.New()
+		r.Use(SnapshotBannerMiddleware(func() string { return patch }))
+		r.GET("/", func(c *gin.Context) {
+			c.Render(http.StatusOK, renderer.New(c.Request.Context(), http.StatusOK, components.HomePage()))
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		shown := strings.Contains(w.Body.String(), "Offline data")
+		if shown != (patch != "") {
+			t.Errorf("snapshot patch %q: banner shown = %v", patch, shown)
+		}
+		if patch != "" && !strings.Contains(w.Body.String(), patch) {
+			t.Errorf("banner should name patch %s", patch)
+		}
+	}
+}
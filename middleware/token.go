@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenAuthMiddleware guards a route with a shared secret. The token may be
+// sent as "Authorization: Bearer <token>" or as the password of HTTP Basic auth
+// (so browsers can prompt for it). When token is empty the route is treated as
+// disabled and answers 404.
+func TokenAuthMiddleware(token string, realm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if !tokenMatches(c.Request, token) {
+			c.Header("WWW-Authenticate", `Basic realm="`+realm+`"`)
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
+
+// tokenMatches reports whether the request carries token as a bearer token or
+// Basic auth password, comparing in constant time.
+func tokenMatches(r *http.Request, token string) bool {
+	var got string
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		got = strings.TrimPrefix(auth, "Bearer ")
+	} else if _, pass, ok := r.BasicAuth(); ok {
+		got = pass
+	}
+	return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTokenAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(token string) *gin.Engine {
+		r := gin.New()
+		r.GET("/debug", TokenAuthMiddleware(token, "test"), func(c *gin.Context) {
+			c.String(http.StatusOK, "ok")
+		})
+		return r
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		setAuth    func(*http.Request)
+		wantStatus int
+	}{
+		{"disabled without token", "", func(*http.Request) {}, http.StatusNotFound},
+		{"missing credentials", "s3cret", func(*http.Request) {}, http.StatusUnauthorized},
+		{"wrong bearer", "s3cret", func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, http.StatusUnauthorized},
+		{"valid bearer", "s3cret", func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }, http.StatusOK},
+		{"valid basic password", "s3cret", func(r *http.Request) { r.SetBasicAuth("admin", "s3cret") }, http.StatusOK},
+		{"wrong basic password", "s3cret", func(r *http.Request) { r.SetBasicAuth("admin", "x") }, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/debug", nil)
+			tt.setAuth(req)
+			newRouter(tt.token).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status: got %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 response should include WWW-Authenticate")
+			}
+		})
+	}
+}
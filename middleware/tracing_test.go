@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	r := gin.New()
+	r.Use(RequestIDMiddleware())
+	r.Use(TracingMiddleware())
+	r.GET("/player/:id", func(c *gin.Context) {
+		_, span := tracing.Start(c.Request.Context(), "child")
+		span.End()
+		c.String(http.StatusBadGateway, "upstream down")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/player/42", nil)
+	req.Header.Set("X-Request-ID", "trace-req-1")
+	r.ServeHTTP(w, req)
+
+	ended := rec.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(ended))
+	}
+	child, server := ended[0], ended[1]
+
+	if server.Name() != "GET /player/:id" {
+		t.Errorf("server span name: got %q", server.Name())
+	}
+	if child.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Error("handler span should be a child of the server span")
+	}
+	if server.Status().Code != codes.Error {
+		t.Errorf("5xx response should mark span as error, got %v", server.Status().Code)
+	}
+
+	attrs := attribute.NewSet(server.Attributes()...)
+	if v, _ := attrs.Value(tracing.RequestIDKey); v.AsString() != "trace-req-1" {
+		t.Errorf("server span request.id: got %q", v.AsString())
+	}
+	if v, _ := attrs.Value("http.response.status_code"); v.AsInt64() != http.StatusBadGateway {
+		t.Errorf("server span status code: got %d", v.AsInt64())
+	}
+	childAttrs := attribute.NewSet(child.Attributes()...)
+	if v, _ := childAttrs.Value(tracing.RequestIDKey); v.AsString() != "trace-req-1" {
+		t.Errorf("child span request.id: got %q", v.AsString())
+	}
+}
@@ -0,0 +1,128 @@
+package mockriot
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// fixtureFS holds the recorded Riot API responses. They are shared with the
+// Python dev server in cmd/mockserver, whose fetch_fixtures.py refreshes them.
+//
+//go:embed fixtures/*.json fixtures/matches/*.json
+var fixtureFS embed.FS
+
+// fixtures is the parsed fixture set. Player data is kept as raw JSON so it is
+// served exactly as recorded.
+type fixtures struct {
+	accounts  map[string]json.RawMessage   // lower-cased "gameName#tagLine"
+	summoners map[string]json.RawMessage   // by PUUID
+	spectator map[string]json.RawMessage   // by PUUID
+	league    map[string]json.RawMessage   // by PUUID
+	matchIDs  map[string][]string          // by PUUID, newest first
+	matches   map[string]json.RawMessage   // by match ID
+	champions map[string]championFixture   // by champion key, derived from matches
+	spells    map[string]summonerSpellData // DDragon summoner.json entries
+}
+
+// championFixture is the subset of Meraki champion data the app needs, built
+// from the champions that appear in the match fixtures.
+type championFixture struct {
+	ID   int    `json:"id"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Icon string `json:"icon"`
+}
+
+type summonerSpellData struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Key      string    `json:"key"`
+	Cooldown []float64 `json:"cooldown"`
+	Image    struct {
+		Full string `json:"full"`
+	} `json:"image"`
+}
+
+// loadFixtures parses the embedded fixture files.
+func loadFixtures() (*fixtures, error) {
+	f := &fixtures{matches: make(map[string]json.RawMessage)}
+	files := map[string]any{
+		"accounts.json":       &f.accounts,
+		"summoners.json":      &f.summoners,
+		"spectator.json":      &f.spectator,
+		"league_entries.json": &f.league,
+		"match_ids.json":      &f.matchIDs,
+	}
+	for name, target := range files {
+		raw, err := fixtureFS.ReadFile("fixtures/" + name)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", name, err)
+		}
+	}
+
+	entries, err := fixtureFS.ReadDir("fixtures/matches")
+	if err != nil {
+		return nil, err
+	}
+	f.champions = make(map[string]championFixture)
+	for _, e := range entries {
+		raw, err := fixtureFS.ReadFile("fixtures/matches/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+		var m struct {
+			Info struct {
+				Participants []struct {
+					ChampionID   int    `json:"championId"`
+					ChampionName string `json:"championName"`
+				} `json:"participants"`
+			} `json:"info"`
+		}
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return nil, fmt.Errorf("fixture matches/%s: %w", e.Name(), err)
+		}
+		f.matches[strings.TrimSuffix(e.Name(), path.Ext(e.Name()))] = raw
+		for _, p := range m.Info.Participants {
+			f.champions[p.ChampionName] = championFixture{
+				ID:   p.ChampionID,
+				Key:  p.ChampionName,
+				Name: p.ChampionName,
+				Icon: fmt.Sprintf("https://ddragon.leagueoflegends.com/cdn/%s/img/champion/%s.png", DefaultPatch, p.ChampionName),
+			}
+		}
+	}
+
+	f.spells = defaultSpells()
+	return f, nil
+}
+
+// defaultSpells returns the Summoner's Rift summoner spells keyed like DDragon.
+func defaultSpells() map[string]summonerSpellData {
+	spells := []struct {
+		id, name, key string
+		cooldown      float64
+	}{
+		{"SummonerBoost", "Cleanse", "1", 240},
+		{"SummonerExhaust", "Exhaust", "3", 240},
+		{"SummonerFlash", "Flash", "4", 300},
+		{"SummonerHaste", "Ghost", "6", 240},
+		{"SummonerHeal", "Heal", "7", 240},
+		{"SummonerSmite", "Smite", "11", 15},
+		{"SummonerTeleport", "Teleport", "12", 360},
+		{"SummonerDot", "Ignite", "14", 180},
+		{"SummonerBarrier", "Barrier", "21", 180},
+	}
+	m := make(map[string]summonerSpellData, len(spells))
+	for _, s := range spells {
+		d := summonerSpellData{ID: s.id, Name: s.name, Key: s.key, Cooldown: []float64{s.cooldown}}
+		d.Image.Full = s.id + ".png"
+		m[s.id] = d
+	}
+	return m
+}
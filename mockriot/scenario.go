@@ -0,0 +1,105 @@
+package mockriot
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fault makes the server answer matching requests with an error status
+// instead of fixture data.
+type Fault struct {
+	// PathPrefix limits the fault to requests whose path starts with it;
+	// empty matches every request.
+	PathPrefix string
+	// RiotOnly limits the fault to Riot API endpoints, leaving DDragon and
+	// Meraki data unaffected.
+	RiotOnly bool
+	// Status is the HTTP status returned, e.g. 429, 403 or 503.
+	Status int
+	// RetryAfter, when set, is sent as the Retry-After header in whole seconds.
+	RetryAfter time.Duration
+	// Every injects the fault on every Nth matching request; 0 or 1 means all.
+	Every int
+	// Limit stops injecting after this many faults; 0 means no limit.
+	Limit int
+}
+
+// matches reports whether the fault applies to a request for path.
+func (f Fault) matches(path string) bool {
+	if f.RiotOnly && !isRiotPath(path) {
+		return false
+	}
+	return strings.HasPrefix(path, f.PathPrefix)
+}
+
+// isRiotPath reports whether path is a Riot API endpoint.
+func isRiotPath(path string) bool {
+	return strings.HasPrefix(path, "/lol/") || strings.HasPrefix(path, "/riot/")
+}
+
+// Scenario describes how the server misbehaves.
+type Scenario struct {
+	Name        string
+	Description string
+	// Latency delays every response.
+	Latency time.Duration
+	// Faults are checked in order; the first one that fires answers the request.
+	Faults []Fault
+	// GameCycle flips a player's spectator game between in progress and ended
+	// every GameCycle spectator requests for that player; 0 keeps games static.
+	GameCycle int
+}
+
+// Scenarios are the named scenarios selectable with cmd/mockriot -scenario.
+var Scenarios = map[string]Scenario{
+	"ok": {
+		Name:        "ok",
+		Description: "serve fixtures without faults",
+	},
+	"rate-limited": {
+		Name:        "rate-limited",
+		Description: "every 3rd Riot request gets 429 with Retry-After: 1",
+		Faults:      []Fault{{RiotOnly: true, Status: 429, RetryAfter: time.Second, Every: 3}},
+	},
+	"expired-key": {
+		Name:        "expired-key",
+		Description: "every Riot request gets 403 Forbidden, as with an expired key",
+		Faults:      []Fault{{RiotOnly: true, Status: 403}},
+	},
+	"flaky": {
+		Name:        "flaky",
+		Description: "every 4th request gets 503 Service Unavailable",
+		Faults:      []Fault{{Status: 503, Every: 4}},
+	},
+	"slow": {
+		Name:        "slow",
+		Description: "every response is delayed by 750ms",
+		Latency:     750 * time.Millisecond,
+	},
+	"game-cycle": {
+		Name:        "game-cycle",
+		Description: "spectator games end and reappear every 3 polls",
+		GameCycle:   3,
+	},
+	"chaos": {
+		Name:        "chaos",
+		Description: "200ms latency, 429s, 500s and cycling spectator games",
+		Latency:     200 * time.Millisecond,
+		Faults: []Fault{
+			{RiotOnly: true, Status: 429, RetryAfter: 2 * time.Second, Every: 7},
+			{Status: 500, Every: 5},
+		},
+		GameCycle: 2,
+	},
+}
+
+// ScenarioNames returns the names of the predefined scenarios, sorted.
+func ScenarioNames() []string {
+	names := make([]string, 0, len(Scenarios))
+	for name := range Scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
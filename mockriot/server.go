@@ -0,0 +1,415 @@
+// Package mockriot is a fake Riot API, Data Dragon and Meraki server backed by
+// recorded fixtures. It can inject Riot's failure modes (rate limiting,
+// expired keys, server errors, latency, games starting and ending) so the
+// client and handlers can be exercised against them, either in tests through
+// httptest or as a standalone dev server (cmd/mockriot).
+package mockriot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/klnstprx/lolMatchup/client"
+)
+
+// DefaultPatch is the game version reported by the versions endpoint.
+const DefaultPatch = "15.1.1"
+
+// Base paths of the non-Riot data sources, relative to the server URL.
+const (
+	MerakiPath          = "/meraki/"
+	DDragonVersionsPath = "/api/versions.json"
+	controlPrefix       = "/_mock/"
+)
+
+// ErrNoGame is returned by StartGame for players without a spectator fixture.
+var ErrNoGame = errors.New("no spectator fixture for player")
+
+// Server serves the fixtures and applies the current Scenario. It is safe for
+// concurrent use.
+type Server struct {
+	// APIKey, when set, must be sent in X-Riot-Token on Riot requests:
+	// a missing key gets 401 and a different one 403.
+	APIKey string
+
+	fx  *fixtures
+	mux *http.ServeMux
+
+	mu          sync.Mutex
+	scenario    Scenario
+	faultHits   []int          // matching requests seen, per scenario fault
+	faultsFired []int          // faults injected, per scenario fault
+	polls       map[string]int // spectator requests per PUUID
+	ended       map[string]bool
+	startedAt   map[string]int64 // game start overrides (epoch ms) for restarted games
+	requests    int
+}
+
+// New returns a server with the "ok" scenario. It panics if the embedded
+// fixtures cannot be parsed.
+func New() *Server {
+	fx, err := loadFixtures()
+	if err != nil {
+		panic(fmt.Sprintf("mockriot: %v", err))
+	}
+	s := &Server{
+		fx:        fx,
+		polls:     make(map[string]int),
+		ended:     make(map[string]bool),
+		startedAt: make(map[string]int64),
+	}
+	s.SetScenario(Scenarios["ok"])
+	s.routes()
+	return s
+}
+
+// SetScenario replaces the active scenario and resets its fault counters.
+func (s *Server) SetScenario(sc Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenario = sc
+	s.faultHits = make([]int, len(sc.Faults))
+	s.faultsFired = make([]int, len(sc.Faults))
+}
+
+// Scenario returns the active scenario.
+func (s *Server) Scenario() Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scenario
+}
+
+// Requests returns how many requests the server has answered.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// StartGame makes puuid's spectator game appear, starting now.
+func (s *Server) StartGame(puuid string) error {
+	if _, ok := s.fx.spectator[puuid]; !ok {
+		return fmt.Errorf("%w %s", ErrNoGame, puuid)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended[puuid] = false
+	s.startedAt[puuid] = time.Now().UnixMilli()
+	return nil
+}
+
+// EndGame makes puuid's spectator game disappear, as when the game is over.
+func (s *Server) EndGame(puuid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended[puuid] = true
+}
+
+// ClientFor returns a client.Client that sends every request (Riot, DDragon
+// versions and Meraki) to baseURL, typically an httptest.Server's URL. Its
+// logger discards output.
+func ClientFor(baseURL string, httpClient *http.Client) *client.Client {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return &client.Client{
+		HTTPClient:        httpClient,
+		Logger:            log.New(io.Discard),
+		RiotAPIBaseURL:    baseURL,
+		ChampionDataURL:   baseURL + MerakiPath,
+		DDragonVersionURL: baseURL + DDragonVersionsPath,
+	}
+}
+
+func (s *Server) routes() {
+	m := http.NewServeMux()
+	m.HandleFunc("GET /riot/account/v1/accounts/by-riot-id/{gameName}/{tagLine}", s.account)
+	m.HandleFunc("GET /lol/summoner/v4/summoners/by-puuid/{puuid}", s.byPUUID(s.fx.summoners, "Data not found - No results found for player"))
+	m.HandleFunc("GET /lol/league/v4/entries/by-puuid/{puuid}", s.league)
+	m.HandleFunc("GET /lol/spectator/v5/active-games/by-summoner/{puuid}", s.spectator)
+	m.HandleFunc("GET /lol/match/v5/matches/by-puuid/{puuid}/ids", s.matchIDs)
+	m.HandleFunc("GET /lol/match/v5/matches/{matchId}", s.match)
+	m.HandleFunc("GET /lol/status/v4/platform-data", s.platformData)
+	m.HandleFunc("GET "+DDragonVersionsPath, s.versions)
+	m.HandleFunc("GET /cdn/{version}/data/en_US/summoner.json", s.summonerSpells)
+	m.HandleFunc("GET "+MerakiPath+"champions.json", s.championList)
+	m.HandleFunc("GET "+MerakiPath+"champions/{file}", s.champion)
+	m.HandleFunc("GET "+controlPrefix+"scenario", s.getScenario)
+	m.HandleFunc("PUT "+controlPrefix+"scenario/{name}", s.putScenario)
+	m.HandleFunc("PUT "+controlPrefix+"games/{puuid}", s.putGame)
+	m.HandleFunc("DELETE "+controlPrefix+"games/{puuid}", s.deleteGame)
+	s.mux = m
+}
+
+// ServeHTTP applies the scenario's latency, key check and faults, then
+// serves the request. Control endpoints under /_mock/ are never faulted.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, controlPrefix) {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests++
+	latency := s.scenario.Latency
+	fault, faulted := s.nextFault(r.URL.Path)
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if isRiotPath(r.URL.Path) && s.APIKey != "" {
+		switch r.Header.Get("X-Riot-Token") {
+		case s.APIKey:
+		case "":
+			writeStatus(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		default:
+			writeStatus(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+	}
+
+	if faulted {
+		writeFault(w, fault)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// nextFault advances the fault counters for a request and returns the first
+// fault that fires. s.mu must be held.
+func (s *Server) nextFault(path string) (Fault, bool) {
+	for i, f := range s.scenario.Faults {
+		if !f.matches(path) {
+			continue
+		}
+		s.faultHits[i]++
+		if f.Limit > 0 && s.faultsFired[i] >= f.Limit {
+			continue
+		}
+		if f.Every > 1 && s.faultHits[i]%f.Every != 0 {
+			continue
+		}
+		s.faultsFired[i]++
+		return f, true
+	}
+	return Fault{}, false
+}
+
+// writeFault answers with the fault's status in Riot's error format.
+func writeFault(w http.ResponseWriter, f Fault) {
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Round(time.Second)/time.Second)))
+	}
+	message := http.StatusText(f.Status)
+	if f.Status == http.StatusTooManyRequests {
+		w.Header().Set("X-Rate-Limit-Type", "application")
+		message = "Rate limit exceeded"
+	}
+	writeStatus(w, f.Status, message)
+}
+
+// writeStatus writes a Riot-style {"status": {...}} error body.
+func writeStatus(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]any{
+		"status": map[string]any{"status_code": code, "message": message},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(code)
+	if raw, ok := v.(json.RawMessage); ok {
+		w.Write(raw)
+		return
+	}
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) account(w http.ResponseWriter, r *http.Request) {
+	gameName, tagLine := r.PathValue("gameName"), r.PathValue("tagLine")
+	acct, ok := s.fx.accounts[strings.ToLower(gameName+"#"+tagLine)]
+	if !ok {
+		writeStatus(w, http.StatusNotFound,
+			fmt.Sprintf("Data not found - No results found for player with riot id %s#%s", gameName, tagLine))
+		return
+	}
+	writeJSON(w, http.StatusOK, acct)
+}
+
+func (s *Server) byPUUID(data map[string]json.RawMessage, notFound string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, ok := data[r.PathValue("puuid")]
+		if !ok {
+			writeStatus(w, http.StatusNotFound, notFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+func (s *Server) league(w http.ResponseWriter, r *http.Request) {
+	entries, ok := s.fx.league[r.PathValue("puuid")]
+	if !ok {
+		entries = json.RawMessage("[]") // unranked
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// spectator serves a player's game unless it has ended, advancing the
+// scenario's game cycle.
+func (s *Server) spectator(w http.ResponseWriter, r *http.Request) {
+	puuid := r.PathValue("puuid")
+	game, ok := s.fx.spectator[puuid]
+
+	s.mu.Lock()
+	if cycle := s.scenario.GameCycle; ok && cycle > 0 {
+		s.polls[puuid]++
+		if s.polls[puuid]%cycle == 0 {
+			s.ended[puuid] = !s.ended[puuid]
+			if !s.ended[puuid] {
+				s.startedAt[puuid] = time.Now().UnixMilli()
+			}
+		}
+	}
+	ended := s.ended[puuid]
+	startedAt := s.startedAt[puuid]
+	s.mu.Unlock()
+
+	if !ok || ended {
+		writeJSON(w, http.StatusNotFound, map[string]any{
+			"httpStatus":            404,
+			"errorCode":             "NOT_FOUND",
+			"message":               "Not Found",
+			"implementationDetails": "spectator game info isn't found",
+		})
+		return
+	}
+	if startedAt != 0 {
+		game = withGameStart(game, startedAt)
+	}
+	writeJSON(w, http.StatusOK, game)
+}
+
+// withGameStart returns game with gameStartTime replaced and gameLength reset.
+func withGameStart(game json.RawMessage, startMs int64) json.RawMessage {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(game, &doc); err != nil {
+		return game
+	}
+	doc["gameStartTime"] = json.RawMessage(strconv.FormatInt(startMs, 10))
+	doc["gameLength"] = json.RawMessage("0")
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return game
+	}
+	return out
+}
+
+func (s *Server) matchIDs(w http.ResponseWriter, r *http.Request) {
+	ids := s.fx.matchIDs[r.PathValue("puuid")]
+	start := queryInt(r, "start", 0)
+	count := queryInt(r, "count", 20)
+	if start > len(ids) {
+		start = len(ids)
+	}
+	end := min(start+count, len(ids))
+	page := ids[start:end]
+	if page == nil {
+		page = []string{}
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func queryInt(r *http.Request, name string, def int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
+
+func (s *Server) match(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("matchId")
+	m, ok := s.fx.matches[id]
+	if !ok {
+		writeStatus(w, http.StatusNotFound, fmt.Sprintf("Data not found - match %s not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) platformData(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":           "NA1",
+		"name":         "North America",
+		"locales":      []string{"en_US"},
+		"maintenances": []any{},
+		"incidents":    []any{},
+	})
+}
+
+func (s *Server) versions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, []string{DefaultPatch, "14.24.1"})
+}
+
+func (s *Server) summonerSpells(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"type": "summoner", "version": r.PathValue("version"), "data": s.fx.spells})
+}
+
+func (s *Server) championList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.fx.champions)
+}
+
+func (s *Server) champion(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSuffix(r.PathValue("file"), ".json")
+	champ, ok := s.fx.champions[key]
+	if !ok {
+		writeStatus(w, http.StatusNotFound, "Champion not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, champ)
+}
+
+func (s *Server) getScenario(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"active":    s.Scenario().Name,
+		"available": ScenarioNames(),
+		"requests":  s.Requests(),
+	})
+}
+
+func (s *Server) putScenario(w http.ResponseWriter, r *http.Request) {
+	sc, ok := Scenarios[r.PathValue("name")]
+	if !ok {
+		writeStatus(w, http.StatusNotFound, "unknown scenario")
+		return
+	}
+	s.SetScenario(sc)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) putGame(w http.ResponseWriter, r *http.Request) {
+	if err := s.StartGame(r.PathValue("puuid")); err != nil {
+		writeStatus(w, http.StatusNotFound, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteGame(w http.ResponseWriter, r *http.Request) {
+	s.EndGame(r.PathValue("puuid"))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,262 @@
+package mockriot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/klnstprx/lolMatchup/client"
+)
+
+const (
+	zanzarahPUUID = "5O4UWTGk3J65dGoWrf1SDPumvDNqAw4Yv5nVAk3klS-IeT3Wv3nOuoH16RLv6dbUVldHWvKJX9_DYQ"
+	region        = "euw1"
+	apiKey        = "RGAPI-test"
+)
+
+func newTestServer(t *testing.T, sc Scenario) (*Server, *client.Client) {
+	t.Helper()
+	srv := New()
+	srv.SetScenario(sc)
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	c := ClientFor(ts.URL, ts.Client())
+	c.Keys = client.NewKeyProvider(apiKey, nil)
+	return srv, c
+}
+
+func TestServesFixturesForEveryClientCall(t *testing.T) {
+	_, c := newTestServer(t, Scenarios["ok"])
+	ctx := context.Background()
+
+	acct, err := c.FetchAccountByRiotID(ctx, "Zanzarah", "1996", region)
+	if err != nil || acct.PUUID != zanzarahPUUID {
+		t.Fatalf("FetchAccountByRiotID() = %+v, %v", acct, err)
+	}
+	if _, err := c.FetchAccountByRiotID(ctx, "Nobody", "0000", region); !errors.Is(err, client.ErrAccountNotFound) {
+		t.Errorf("unknown account: got %v, want ErrAccountNotFound", err)
+	}
+	if summ, err := c.FetchSummonerByPUUID(ctx, acct.PUUID, region); err != nil || summ.SummonerLevel == 0 {
+		t.Errorf("FetchSummonerByPUUID() = %+v, %v", summ, err)
+	}
+	if entries, err := c.FetchLeagueEntries(ctx, acct.PUUID, region); err != nil || len(entries) == 0 {
+		t.Errorf("FetchLeagueEntries() = %v, %v", entries, err)
+	}
+	if entries, err := c.FetchLeagueEntries(ctx, "unranked", region); err != nil || len(entries) != 0 {
+		t.Errorf("unranked FetchLeagueEntries() = %v, %v; want empty", entries, err)
+	}
+	if game, err := c.FetchCurrentGameByPUUID(ctx, acct.PUUID, region); err != nil || len(game.Participants) != 10 {
+		t.Errorf("FetchCurrentGameByPUUID() = %d participants, %v", len(game.Participants), err)
+	}
+
+	page1, err := c.FetchMatchIDs(ctx, "2KFHMSl0sgPO0lRev6cZkRjRAlTcG2200UhY8xKRK2tX7XxFbftqnnpgjaxrCFi1nb3Zk9wglmVKNw", region, 2, 0)
+	if err != nil || len(page1) != 2 {
+		t.Fatalf("FetchMatchIDs() = %v, %v", page1, err)
+	}
+	page2, _ := c.FetchMatchIDs(ctx, "2KFHMSl0sgPO0lRev6cZkRjRAlTcG2200UhY8xKRK2tX7XxFbftqnnpgjaxrCFi1nb3Zk9wglmVKNw", region, 2, 2)
+	if len(page2) != 2 || page2[0] == page1[0] {
+		t.Errorf("second page should continue after the first: %v then %v", page1, page2)
+	}
+	if match, err := c.FetchMatch(ctx, page1[1], region); err != nil || match.Metadata.MatchID != page1[1] {
+		t.Errorf("FetchMatch(%s) = %q, %v", page1[1], match.Metadata.MatchID, err)
+	}
+
+	if patch, err := c.FetchLatestPatch(ctx); err != nil || patch != DefaultPatch {
+		t.Errorf("FetchLatestPatch() = %q, %v", patch, err)
+	}
+	champs, err := c.FetchChampionList(ctx)
+	if err != nil || len(champs) == 0 {
+		t.Fatalf("FetchChampionList() = %d, %v", len(champs), err)
+	}
+	for key := range champs {
+		if champ, err := c.FetchChampionData(ctx, key); err != nil || champ.Key != key || champ.ID == 0 {
+			t.Errorf("FetchChampionData(%s) = %+v, %v", key, champ, err)
+		}
+		break
+	}
+	if status, err := c.ProbeAPIKey(ctx, region); status != client.KeyValid {
+		t.Errorf("ProbeAPIKey() = %v, %v", status, err)
+	}
+}
+
+func TestScenarioRateLimited(t *testing.T) {
+	srv, _ := newTestServer(t, Scenarios["rate-limited"])
+	h := http.Handler(srv)
+
+	var statuses []int
+	var retryAfter string
+	for range 3 {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/lol/summoner/v4/summoners/by-puuid/"+zanzarahPUUID, nil))
+		statuses = append(statuses, w.Code)
+		retryAfter = w.Header().Get("Retry-After")
+	}
+	if statuses[0] != 200 || statuses[1] != 200 || statuses[2] != http.StatusTooManyRequests {
+		t.Errorf("statuses: got %v, want [200 200 429]", statuses)
+	}
+	if retryAfter != "1" {
+		t.Errorf("Retry-After: got %q, want 1", retryAfter)
+	}
+
+	// DDragon and Meraki are not rate limited.
+	for range 3 {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, DDragonVersionsPath, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("versions: got %d, want 200", w.Code)
+		}
+	}
+}
+
+func TestScenarioExpiredKey(t *testing.T) {
+	_, c := newTestServer(t, Scenarios["expired-key"])
+	ctx := context.Background()
+
+	if _, err := c.FetchSummonerByPUUID(ctx, zanzarahPUUID, region); !errors.Is(err, client.ErrPermissionDenied) {
+		t.Errorf("FetchSummonerByPUUID(): got %v, want ErrPermissionDenied", err)
+	}
+	if status, _ := c.ProbeAPIKey(ctx, region); status != client.KeyInvalid {
+		t.Errorf("ProbeAPIKey(): got %v, want %v", status, client.KeyInvalid)
+	}
+	if !c.Keys.Expired() {
+		t.Error("a 403 should mark the key as expired")
+	}
+	if _, err := c.FetchLatestPatch(ctx); err != nil {
+		t.Errorf("FetchLatestPatch() should be unaffected: %v", err)
+	}
+}
+
+func TestFaultLimitAndServerErrors(t *testing.T) {
+	_, c := newTestServer(t, Scenario{Faults: []Fault{{Status: http.StatusServiceUnavailable, Limit: 2}}})
+	ctx := context.Background()
+
+	for i := range 2 {
+		var apiErr *client.APIError
+		if _, err := c.FetchLatestPatch(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("call %d: got %v, want 503", i, err)
+		}
+	}
+	if _, err := c.FetchLatestPatch(ctx); err != nil {
+		t.Errorf("after the limit: got %v, want success", err)
+	}
+}
+
+func TestScenarioLatency(t *testing.T) {
+	_, c := newTestServer(t, Scenario{Latency: 200 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.FetchLatestPatch(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+}
+
+func TestGameCycle(t *testing.T) {
+	_, c := newTestServer(t, Scenario{GameCycle: 2})
+	ctx := context.Background()
+
+	var inGame []bool
+	for range 5 {
+		_, err := c.FetchCurrentGameByPUUID(ctx, zanzarahPUUID, region)
+		if err != nil && !errors.Is(err, client.ErrGameNotFound) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		inGame = append(inGame, err == nil)
+	}
+	want := []bool{true, false, false, true, true}
+	for i := range want {
+		if inGame[i] != want[i] {
+			t.Fatalf("in game per poll: got %v, want %v", inGame, want)
+		}
+	}
+}
+
+func TestStartAndEndGame(t *testing.T) {
+	srv, c := newTestServer(t, Scenarios["ok"])
+	ctx := context.Background()
+
+	srv.EndGame(zanzarahPUUID)
+	if _, err := c.FetchCurrentGameByPUUID(ctx, zanzarahPUUID, region); !errors.Is(err, client.ErrGameNotFound) {
+		t.Fatalf("after EndGame: got %v, want ErrGameNotFound", err)
+	}
+
+	before := time.Now().UnixMilli()
+	if err := srv.StartGame(zanzarahPUUID); err != nil {
+		t.Fatalf("StartGame() error: %v", err)
+	}
+	game, err := c.FetchCurrentGameByPUUID(ctx, zanzarahPUUID, region)
+	if err != nil {
+		t.Fatalf("after StartGame: %v", err)
+	}
+	if game.GameStartTime < before {
+		t.Errorf("restarted game should start now, got %d (before %d)", game.GameStartTime, before)
+	}
+
+	if err := srv.StartGame("no-such-player"); !errors.Is(err, ErrNoGame) {
+		t.Errorf("StartGame(unknown): got %v, want ErrNoGame", err)
+	}
+}
+
+func TestControlEndpoints(t *testing.T) {
+	srv := New()
+	do := func(method, path string) int {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	if code := do(http.MethodPut, "/_mock/scenario/expired-key"); code != http.StatusNoContent {
+		t.Fatalf("PUT scenario: got %d", code)
+	}
+	if srv.Scenario().Name != "expired-key" {
+		t.Errorf("scenario: got %q, want expired-key", srv.Scenario().Name)
+	}
+	if code := do(http.MethodPut, "/_mock/scenario/nope"); code != http.StatusNotFound {
+		t.Errorf("unknown scenario: got %d, want 404", code)
+	}
+	if code := do(http.MethodDelete, "/_mock/games/"+zanzarahPUUID); code != http.StatusNoContent {
+		t.Errorf("DELETE game: got %d", code)
+	}
+	if code := do(http.MethodPut, "/_mock/games/"+zanzarahPUUID); code != http.StatusNoContent {
+		t.Errorf("PUT game: got %d", code)
+	}
+	if srv.Requests() != 0 {
+		t.Errorf("control requests should not be counted, got %d", srv.Requests())
+	}
+}
+
+func TestAPIKeyRequired(t *testing.T) {
+	srv := New()
+	srv.APIKey = apiKey
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	c := ClientFor(ts.URL, ts.Client())
+	ctx := context.Background()
+
+	tests := []struct {
+		key  string
+		want client.KeyStatus
+	}{
+		{apiKey, client.KeyValid},
+		{"RGAPI-wrong", client.KeyInvalid},
+	}
+	for _, tt := range tests {
+		c.Keys = client.NewKeyProvider(tt.key, nil)
+		if got, _ := c.ProbeAPIKey(ctx, region); got != tt.want {
+			t.Errorf("ProbeAPIKey(%q): got %v, want %v", tt.key, got, tt.want)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/lol/status/v4/platform-data", nil)
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("missing key: got %d, want 401", resp.StatusCode)
+	}
+}
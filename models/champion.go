@@ -8,10 +8,64 @@ type Champion struct {
 	Icon      string                       `json:"icon"`
 	Positions []string                     `json:"positions"`
 	Roles     []string                     `json:"roles"`
+	Resource  string                       `json:"resource"`     // e.g. MANA, ENERGY, NONE
+	Adaptive  string                       `json:"adaptiveType"` // PHYSICAL_DAMAGE or MAGIC_DAMAGE
 	Stats     ChampionStats                `json:"stats"`
 	Abilities map[string][]ChampionAbility `json:"abilities"`
 }
 
+// MeleeRangeMax is the longest base attack range counted as melee: a range at
+// or below 300 is melee, anything longer is ranged.
+const MeleeRangeMax = 300
+
+// Ranged reports whether the champion's base attack range is ranged.
+func (c Champion) Ranged() bool {
+	return c.Stats.AttackRange.Flat > MeleeRangeMax
+}
+
+// DamageType returns "physical" or "magic" from the champion's adaptive
+// damage type, or "" when Meraki does not say.
+func (c Champion) DamageType() string {
+	switch c.Adaptive {
+	case "PHYSICAL_DAMAGE":
+		return "physical"
+	case "MAGIC_DAMAGE":
+		return "magic"
+	}
+	return ""
+}
+
+// BaseStat is a level 1 champion stat the champion browser can sort by.
+type BaseStat struct {
+	Key   string // query value, e.g. "armor"
+	Label string
+	Value func(ChampionStats) float64
+}
+
+// BaseStats lists the sortable base stats in display order.
+var BaseStats = []BaseStat{
+	{"health", "Health", func(s ChampionStats) float64 { return s.Health.Flat }},
+	{"healthRegen", "Health regen", func(s ChampionStats) float64 { return s.HealthRegen.Flat }},
+	{"mana", "Mana", func(s ChampionStats) float64 { return s.Mana.Flat }},
+	{"manaRegen", "Mana regen", func(s ChampionStats) float64 { return s.ManaRegen.Flat }},
+	{"armor", "Armor", func(s ChampionStats) float64 { return s.Armor.Flat }},
+	{"magicResistance", "Magic resist", func(s ChampionStats) float64 { return s.MagicResistance.Flat }},
+	{"attackDamage", "Attack damage", func(s ChampionStats) float64 { return s.AttackDamage.Flat }},
+	{"attackSpeed", "Attack speed", func(s ChampionStats) float64 { return s.AttackSpeed.Flat }},
+	{"attackRange", "Attack range", func(s ChampionStats) float64 { return s.AttackRange.Flat }},
+	{"movespeed", "Move speed", func(s ChampionStats) float64 { return s.Movespeed.Flat }},
+}
+
+// LookupBaseStat returns the base stat with the given key.
+func LookupBaseStat(key string) (BaseStat, bool) {
+	for _, s := range BaseStats {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return BaseStat{}, false
+}
+
 type ChampionStats struct {
 	Health struct {
 		Flat     float64 `json:"flat"`
@@ -16,6 +16,7 @@ type MatchMetadata struct {
 type MatchInfo struct {
 	GameDuration       int64              `json:"gameDuration"`
 	GameMode           string             `json:"gameMode"`
+	GameVersion        string             `json:"gameVersion"`
 	GameStartTimestamp int64              `json:"gameStartTimestamp"`
 	GameEndTimestamp   int64              `json:"gameEndTimestamp"`
 	QueueID            int                `json:"queueId"`
@@ -36,6 +37,7 @@ type MatchParticipant struct {
 	Assists            int    `json:"assists"`
 	ChampLevel         int    `json:"champLevel"`
 	IndividualPosition string `json:"individualPosition"`
+	TeamPosition       string `json:"teamPosition"` // assigned role; IndividualPosition is Riot's per-game guess
 
 	// CS
 	TotalMinionsKilled   int `json:"totalMinionsKilled"`
@@ -96,6 +98,16 @@ type MatchParticipant struct {
 	Item6 int `json:"item6"`
 }
 
+// Position returns the role p was assigned. Riot's per-game guess,
+// IndividualPosition, is only used when there is none, since it misreads
+// roaming and lane-swapped players.
+func (p MatchParticipant) Position() string {
+	if p.TeamPosition != "" {
+		return p.TeamPosition
+	}
+	return p.IndividualPosition
+}
+
 // PlayerStatsContext holds computed data for the player stats modal.
 type PlayerStatsContext struct {
 	Player            MatchParticipant
@@ -152,3 +164,44 @@ type MatchSummary struct {
 	Items         [7]int
 	QueueID       int
 }
+
+// GamePerformance is a player's output in one game, scaled by game length
+// where it makes sense. In a RoleProfile it also holds averages.
+type GamePerformance struct {
+	CSPerMin          float64
+	DamagePerMin      float64 // to champions
+	DamageShare       float64 // of the team's damage to champions, 0-1
+	GoldPerMin        float64
+	VisionPerMin      float64
+	KillParticipation float64 // 0-1
+	MinutesPerDeath   float64 // minutes alive per death; the whole game when deathless
+	DeadShare         float64 // share of the game spent dead, 0-1
+}
+
+// RoleProfile is a player's performance in one role over their recent games.
+type RoleProfile struct {
+	Role    string // Riot position, e.g. MIDDLE
+	Games   int
+	Wins    int
+	Average GamePerformance
+	Rolling []GamePerformance // rolling averages, oldest game first
+}
+
+// ProfileMetric is a GamePerformance field shown in a role profile.
+type ProfileMetric struct {
+	Label   string
+	Percent bool // a 0-1 share shown as a percentage
+	Value   func(GamePerformance) float64
+}
+
+// ProfileMetrics lists the role profile metrics in display order.
+var ProfileMetrics = []ProfileMetric{
+	{"CS / min", false, func(g GamePerformance) float64 { return g.CSPerMin }},
+	{"Damage / min", false, func(g GamePerformance) float64 { return g.DamagePerMin }},
+	{"Damage share", true, func(g GamePerformance) float64 { return g.DamageShare }},
+	{"Gold / min", false, func(g GamePerformance) float64 { return g.GoldPerMin }},
+	{"Vision / min", false, func(g GamePerformance) float64 { return g.VisionPerMin }},
+	{"Kill participation", true, func(g GamePerformance) float64 { return g.KillParticipation }},
+	{"Minutes alive per death", false, func(g GamePerformance) float64 { return g.MinutesPerDeath }},
+	{"Time spent dead", true, func(g GamePerformance) float64 { return g.DeadShare }},
+}
@@ -0,0 +1,112 @@
+package notes
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Markdown renders the subset of markdown notes are written in as HTML:
+// paragraphs, "#" headings, "-"/"*" and numbered lists, **bold**, *italic*,
+// `code` and [links](https://...). Everything else, raw HTML included, is
+// shown as text, so the result is safe to embed in a page.
+func Markdown(src string) string {
+	var b strings.Builder
+	var para []string
+	list := "" // "ul" or "ol" while inside a list
+
+	flushPara := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>")
+			b.WriteString(strings.Join(para, "<br>"))
+			b.WriteString("</p>")
+			para = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			b.WriteString("</" + list + ">")
+			list = ""
+		}
+	}
+	openList := func(kind string) {
+		if list != kind {
+			closeList()
+			b.WriteString("<" + kind + ">")
+			list = kind
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flushPara()
+			closeList()
+		case strings.HasPrefix(trimmed, "#"):
+			flushPara()
+			closeList()
+			b.WriteString("<h4>" + inline(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))) + "</h4>")
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			flushPara()
+			openList("ul")
+			b.WriteString("<li>" + inline(strings.TrimSpace(trimmed[2:])) + "</li>")
+		case orderedItem.MatchString(trimmed):
+			flushPara()
+			openList("ol")
+			b.WriteString("<li>" + inline(orderedItem.ReplaceAllString(trimmed, "")) + "</li>")
+		default:
+			closeList()
+			para = append(para, inline(trimmed))
+		}
+	}
+	flushPara()
+	closeList()
+	return b.String()
+}
+
+var (
+	orderedItem = regexp.MustCompile(`^\d+[.)]\s+`)
+	codeSpan    = regexp.MustCompile("`([^`]+)`")
+	boldSpan    = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	italicSpan  = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	linkSpan    = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^\s)]+)\)`)
+)
+
+// inline escapes s and renders its inline markup. Code spans are left as they
+// are written.
+func inline(s string) string {
+	var b strings.Builder
+	rest := s
+	for {
+		loc := codeSpan.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			b.WriteString(emphasis(html.EscapeString(rest)))
+			return b.String()
+		}
+		b.WriteString(emphasis(html.EscapeString(rest[:loc[0]])))
+		b.WriteString("<code>" + html.EscapeString(rest[loc[2]:loc[3]]) + "</code>")
+		rest = rest[loc[1]:]
+	}
+}
+
+// emphasis renders links, bold and italic in already escaped text. Link
+// targets are kept out of the bold and italic rules.
+func emphasis(s string) string {
+	var b strings.Builder
+	for {
+		loc := linkSpan.FindStringSubmatchIndex(s)
+		if loc == nil {
+			b.WriteString(styled(s))
+			return b.String()
+		}
+		b.WriteString(styled(s[:loc[0]]))
+		b.WriteString(`<a href="` + s[loc[4]:loc[5]] + `" rel="noopener noreferrer nofollow" target="_blank">` + styled(s[loc[2]:loc[3]]) + "</a>")
+		s = s[loc[1]:]
+	}
+}
+
+func styled(s string) string {
+	s = boldSpan.ReplaceAllString(s, "<strong>$1</strong>")
+	return italicSpan.ReplaceAllString(s, "<em>$1$2</em>")
+}
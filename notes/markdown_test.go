@@ -0,0 +1,22 @@
+package notes
+
+import "testing"
+
+func TestMarkdown(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"Save **E** for his *ult*.", "<p>Save <strong>E</strong> for his <em>ult</em>.</p>"},
+		{"line one\nline two\n\nnext", "<p>line one<br>line two</p><p>next</p>"},
+		{"# Early\n- dodge `Q`\n- trade _after_ W\n1. first\n2. second", "<h4>Early</h4><ul><li>dodge <code>Q</code></li><li>trade <em>after</em> W</li></ul><ol><li>first</li><li>second</li></ol>"},
+		{"see [the guide](https://example.com/a_b_c?x=1&y=2)", `<p>see <a href="https://example.com/a_b_c?x=1&amp;y=2" rel="noopener noreferrer nofollow" target="_blank">the guide</a></p>`},
+		{"<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"[x](javascript:alert(1))", "<p>[x](javascript:alert(1))</p>"},
+		{`[x](https://e.com/"onmouseover=)`, `<p><a href="https://e.com/&#34;onmouseover=" rel="noopener noreferrer nofollow" target="_blank">x</a></p>`},
+		{"`**not bold**`", "<p><code>**not bold**</code></p>"},
+	} {
+		if got := Markdown(tc.in); got != tc.want {
+			t.Errorf("Markdown(%q)\n got %s\nwant %s", tc.in, got, tc.want)
+		}
+	}
+}
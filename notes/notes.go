@@ -0,0 +1,273 @@
+// Package notes keeps the team's matchup notes: short markdown tips about
+// playing one champion against another, optionally for a single role.
+package notes
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/klnstprx/lolMatchup/fileutil"
+)
+
+// MaxBodyLength is the longest note, in characters, Add and Update accept.
+const MaxBodyLength = 4000
+
+var (
+	ErrNotFound    = errors.New("note not found")
+	ErrEmptyBody   = errors.New("note is empty")
+	ErrLongBody    = fmt.Errorf("notes may be at most %d characters", MaxBodyLength)
+	ErrNoChampions = errors.New("a note needs a champion and an opponent")
+	ErrInvalidRole = errors.New("unknown role")
+)
+
+// Roles are the positions a note may be limited to, as Riot names them in
+// match data.
+var Roles = []string{"TOP", "JUNGLE", "MIDDLE", "BOTTOM", "UTILITY"}
+
+// ValidRole reports whether role is empty (any role) or one of Roles.
+func ValidRole(role string) bool {
+	return role == "" || slices.Contains(Roles, role)
+}
+
+// Note is a tip for playing Champion against Opponent, both champion keys.
+type Note struct {
+	ID        string    `json:"id"`
+	Champion  string    `json:"champion"`
+	Opponent  string    `json:"opponent"`
+	Role      string    `json:"role,omitempty"` // empty applies to every role
+	Body      string    `json:"body"`           // markdown
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+	EditedBy  string    `json:"edited_by,omitempty"`
+}
+
+// notesFile is the on-disk layout of the notes file.
+type notesFile struct {
+	Notes []Note `json:"notes"`
+}
+
+// Store holds notes in a JSON file, written back atomically on every change.
+// Its methods are safe for concurrent use and on a nil *Store, which holds
+// no notes and refuses changes.
+type Store struct {
+	path string
+
+	mu    sync.RWMutex
+	notes map[string]Note // by ID
+	now   func() time.Time
+}
+
+// Open loads the notes file at path. A missing file is an empty store; it is
+// created on the first change. An empty path keeps notes in memory only.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, notes: make(map[string]Note), now: time.Now}
+	if path == "" {
+		return s, nil
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading notes file: %w", err)
+	}
+	var f notesFile
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("decoding notes file %s: %w", path, err)
+	}
+	for _, n := range f.Notes {
+		if n.ID != "" {
+			s.notes[n.ID] = n
+		}
+	}
+	return s, nil
+}
+
+// Len returns the number of notes.
+func (s *Store) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.notes)
+}
+
+// Get returns the note with the given ID.
+func (s *Store) Get(id string) (Note, bool) {
+	if s == nil {
+		return Note{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n, ok := s.notes[id]
+	return n, ok
+}
+
+// For returns the notes on playing champion against opponent in role,
+// newest first. Notes for every role always apply; an empty role returns the
+// notes for all roles, and an empty opponent those against anyone.
+func (s *Store) For(champion, opponent, role string) []Note {
+	if s == nil || champion == "" {
+		return nil
+	}
+	s.mu.RLock()
+	var out []Note
+	for _, n := range s.notes {
+		if strings.EqualFold(n.Champion, champion) && (opponent == "" || strings.EqualFold(n.Opponent, opponent)) &&
+			(role == "" || n.Role == "" || n.Role == role) {
+			out = append(out, n)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// Add stores a new note written by author and returns it with its ID and
+// timestamp filled in.
+func (s *Store) Add(champion, opponent, role, body, author string) (Note, error) {
+	if s == nil {
+		return Note{}, errors.New("notes are not available")
+	}
+	body = strings.TrimSpace(body)
+	switch {
+	case champion == "" || opponent == "":
+		return Note{}, ErrNoChampions
+	case !ValidRole(role):
+		return Note{}, ErrInvalidRole
+	}
+	if err := checkBody(body); err != nil {
+		return Note{}, err
+	}
+	n := Note{
+		ID:        newID(),
+		Champion:  champion,
+		Opponent:  opponent,
+		Role:      role,
+		Body:      body,
+		Author:    author,
+		CreatedAt: s.now().UTC().Truncate(time.Second),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notes[n.ID] = n
+	if err := s.save(); err != nil {
+		delete(s.notes, n.ID)
+		return Note{}, err
+	}
+	return n, nil
+}
+
+// Update replaces the body and role of the note with the given ID, recording
+// editor and the time of the edit.
+func (s *Store) Update(id, role, body, editor string) (Note, error) {
+	if s == nil {
+		return Note{}, ErrNotFound
+	}
+	body = strings.TrimSpace(body)
+	if !ValidRole(role) {
+		return Note{}, ErrInvalidRole
+	}
+	if err := checkBody(body); err != nil {
+		return Note{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.notes[id]
+	if !ok {
+		return Note{}, ErrNotFound
+	}
+	n := old
+	n.Role, n.Body, n.EditedBy = role, body, editor
+	n.UpdatedAt = s.now().UTC().Truncate(time.Second)
+	s.notes[id] = n
+	if err := s.save(); err != nil {
+		s.notes[id] = old
+		return Note{}, err
+	}
+	return n, nil
+}
+
+// Delete removes the note with the given ID.
+func (s *Store) Delete(id string) error {
+	if s == nil {
+		return ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.notes[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.notes, id)
+	if err := s.save(); err != nil {
+		s.notes[id] = old
+		return err
+	}
+	return nil
+}
+
+// save writes the notes file atomically. s.mu must be held.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	f := notesFile{Notes: make([]Note, 0, len(s.notes))}
+	for _, n := range s.notes {
+		f.Notes = append(f.Notes, n)
+	}
+	sort.Slice(f.Notes, func(i, j int) bool {
+		a, b := f.Notes[i], f.Notes[j]
+		if a.Champion != b.Champion {
+			return a.Champion < b.Champion
+		}
+		if a.Opponent != b.Opponent {
+			return a.Opponent < b.Opponent
+		}
+		return a.ID < b.ID
+	})
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding notes file: %w", err)
+	}
+
+	if err := fileutil.WriteFileAtomic(s.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing notes file: %w", err)
+	}
+	return nil
+}
+
+func checkBody(body string) error {
+	switch {
+	case body == "":
+		return ErrEmptyBody
+	case utf8.RuneCountInString(body) > MaxBodyLength:
+		return ErrLongBody
+	}
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
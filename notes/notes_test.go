@@ -0,0 +1,118 @@
+package notes
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "notes.json"))
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	return s
+}
+
+func TestStore(t *testing.T) {
+	s := newTestStore(t)
+	clock := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return clock }
+
+	first, err := s.Add("Ahri", "Zed", "", "Save **E** for his ult.", "alice")
+	if err != nil {
+		t.Fatalf("Add() error: %v", err)
+	}
+	clock = clock.Add(time.Hour)
+	second, err := s.Add("Ahri", "Zed", "MIDDLE", "Buy Zhonya's early.", "bob")
+	if err != nil {
+		t.Fatalf("Add() error: %v", err)
+	}
+	if _, err := s.Add("Ahri", "Yasuo", "", "Bait the windwall.", "alice"); err != nil {
+		t.Fatalf("Add() error: %v", err)
+	}
+
+	got := s.For("ahri", "zed", "")
+	if len(got) != 2 || got[0].ID != second.ID || got[1].ID != first.ID {
+		t.Fatalf("For(ahri, zed) = %+v, want both Zed notes, newest first", got)
+	}
+	if got[1].Author != "alice" || !got[1].CreatedAt.Equal(clock.Add(-time.Hour)) {
+		t.Errorf("authorship not kept: %+v", got[1])
+	}
+	if n := len(s.For("Ahri", "", "")); n != 3 {
+		t.Errorf("For(Ahri, \"\") = %d notes, want 3", n)
+	}
+	if n := len(s.For("Zed", "Ahri", "")); n != 0 {
+		t.Errorf("notes are one-sided, got %d for Zed vs Ahri", n)
+	}
+	if got := s.For("Ahri", "Zed", "TOP"); len(got) != 1 || got[0].ID != first.ID {
+		t.Errorf("For(Ahri, Zed, TOP) = %+v, want only the note for every role", got)
+	}
+	if n := len(s.For("Ahri", "Zed", "MIDDLE")); n != 2 {
+		t.Errorf("For(Ahri, Zed, MIDDLE) = %d notes, want 2", n)
+	}
+
+	clock = clock.Add(time.Hour)
+	edited, err := s.Update(first.ID, "MIDDLE", "Save E for Death Mark.", "bob")
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if edited.Author != "alice" || edited.EditedBy != "bob" || !edited.UpdatedAt.Equal(clock) || edited.Role != "MIDDLE" {
+		t.Errorf("Update() = %+v", edited)
+	}
+
+	// Notes survive a reopen.
+	reopened, err := Open(s.path)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	if n, ok := reopened.Get(first.ID); !ok || n.Body != "Save E for Death Mark." {
+		t.Errorf("reopened note = %+v, %v", n, ok)
+	}
+
+	if err := s.Delete(second.ID); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if err := s.Delete(second.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+	}
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", s.Len())
+	}
+}
+
+func TestStore_Invalid(t *testing.T) {
+	s := newTestStore(t)
+	for _, tc := range []struct {
+		champion, opponent, role, body string
+		want                           error
+	}{
+		{"Ahri", "", "", "tip", ErrNoChampions},
+		{"Ahri", "Zed", "MID", "tip", ErrInvalidRole},
+		{"Ahri", "Zed", "", "   ", ErrEmptyBody},
+		{"Ahri", "Zed", "", strings.Repeat("x", MaxBodyLength+1), ErrLongBody},
+	} {
+		if _, err := s.Add(tc.champion, tc.opponent, tc.role, tc.body, "alice"); !errors.Is(err, tc.want) {
+			t.Errorf("Add(%q, %q, %q, %.10q) error = %v, want %v", tc.champion, tc.opponent, tc.role, tc.body, err, tc.want)
+		}
+	}
+	if _, err := s.Update("missing", "", "tip", "alice"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update() of a missing note error = %v", err)
+	}
+	if s.Len() != 0 {
+		t.Errorf("invalid notes were stored: %d", s.Len())
+	}
+}
+
+func TestStore_Nil(t *testing.T) {
+	var s *Store
+	if s.Len() != 0 || s.For("Ahri", "Zed", "") != nil {
+		t.Error("a nil store should hold no notes")
+	}
+	if _, err := s.Add("Ahri", "Zed", "", "tip", "alice"); err == nil {
+		t.Error("a nil store should refuse notes")
+	}
+}
@@ -0,0 +1,374 @@
+// Package replay provides an http.RoundTripper that records HTTP exchanges to
+// cassette files and replays them, so client and handler tests can run
+// against realistic upstream responses without a network or a fake server per
+// test case.
+//
+// Requests are matched on method, path and query; the host is ignored so a
+// cassette recorded against one base URL (a mock server, or Riot's regional
+// hosts) replays under any other. Identical requests are answered with their
+// recordings in the order they were made.
+package replay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CassetteVersion is the format version written to cassette files.
+const CassetteVersion = 1
+
+// Redacted replaces scrubbed header and query values.
+const Redacted = "REDACTED"
+
+// ErrUnexpectedRequest is returned in strict replay mode for requests that
+// are not in the cassette, or that were made more often than recorded.
+var ErrUnexpectedRequest = errors.New("replay: unexpected request")
+
+// Mode selects whether a Transport records or replays.
+type Mode int
+
+const (
+	// ModeReplay answers requests from the cassette without network access.
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests to Next and records the exchanges.
+	ModeRecord
+)
+
+// ScrubHeaders are request headers whose values are never written to a
+// cassette.
+var ScrubHeaders = []string{"X-Riot-Token", "Authorization", "Cookie"}
+
+// ScrubQueryParams are query parameters whose values are never written to a
+// cassette.
+var ScrubQueryParams = []string{"api_key"}
+
+// droppedResponseHeaders vary between runs and are not recorded.
+var droppedResponseHeaders = []string{"Date", "Content-Length", "Set-Cookie"}
+
+// Cassette is the on-disk list of recorded exchanges.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the recorded form of an outgoing request, with secrets scrubbed.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+}
+
+// Response is a recorded response. JSON bodies are stored as JSON so
+// cassettes stay readable; anything else is stored as text.
+type Response struct {
+	Status   int             `json:"status"`
+	Header   http.Header     `json:"header,omitempty"`
+	Body     json.RawMessage `json:"body,omitempty"`
+	BodyText string          `json:"body_text,omitempty"`
+}
+
+// Transport records or replays HTTP exchanges. It is safe for concurrent use.
+type Transport struct {
+	// Next performs real requests in ModeRecord; nil means http.DefaultTransport.
+	Next http.RoundTripper
+	// Strict makes replay fail with ErrUnexpectedRequest for requests that are
+	// not in the cassette, instead of answering 404. Repeats beyond the
+	// recorded count fail too, rather than reusing the last recording.
+	Strict bool
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+	keys     []string // match keys of the cassette's interactions, for replay
+	used     []bool
+}
+
+// New returns a Transport for the cassette at path. In ModeReplay the
+// cassette is loaded now; in ModeRecord it is written by Save. Paths ending in
+// ".gz" are gzip-compressed.
+func New(path string, mode Mode) (*Transport, error) {
+	t := &Transport{mode: mode, path: path, cassette: Cassette{Version: CassetteVersion}}
+	if mode == ModeRecord {
+		return t, nil
+	}
+	c, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	t.cassette = c
+	t.used = make([]bool, len(c.Interactions))
+	for _, in := range c.Interactions {
+		u, err := url.Parse(in.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("replay: cassette %s: %w", path, err)
+		}
+		t.keys = append(t.keys, matchKey(in.Request.Method, u))
+	}
+	return t, nil
+}
+
+// Mode returns whether the transport records or replays.
+func (t *Transport) Mode() Mode {
+	return t.mode
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.mode == ModeRecord {
+		return t.record(req)
+	}
+	return t.replay(req)
+}
+
+func (t *Transport) record(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("replay: reading response to record: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	rec := Response{Status: resp.StatusCode, Header: resp.Header.Clone()}
+	for _, h := range droppedResponseHeaders {
+		rec.Header.Del(h)
+	}
+	if len(rec.Header) == 0 {
+		rec.Header = nil
+	}
+	var compact bytes.Buffer
+	if json.Valid(body) && json.Compact(&compact, body) == nil {
+		rec.Body = compact.Bytes()
+	} else {
+		rec.BodyText = string(body)
+	}
+
+	t.mu.Lock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{Request: scrubRequest(req), Response: rec})
+	t.mu.Unlock()
+	return resp, nil
+}
+
+func (t *Transport) replay(req *http.Request) (*http.Response, error) {
+	key := matchKey(req.Method, req.URL)
+
+	t.mu.Lock()
+	found, last := -1, -1
+	for i, k := range t.keys {
+		if k != key {
+			continue
+		}
+		last = i
+		if !t.used[i] {
+			found = i
+			break
+		}
+	}
+	if found < 0 && !t.Strict {
+		found = last
+	}
+	if found >= 0 {
+		t.used[found] = true
+	}
+	t.mu.Unlock()
+
+	if found < 0 {
+		if t.Strict {
+			return nil, fmt.Errorf("%w: %s %s", ErrUnexpectedRequest, req.Method, scrubURL(req.URL))
+		}
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Status:     "404 Not Found",
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	}
+
+	rec := t.cassette.Interactions[found].Response
+	body := []byte(rec.Body)
+	if rec.Body == nil {
+		body = []byte(rec.BodyText)
+	}
+	header := rec.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		StatusCode:    rec.Status,
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Unused returns the recorded interactions that have not been replayed. It
+// returns nil in ModeRecord.
+func (t *Transport) Unused() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.mode == ModeRecord {
+		return nil
+	}
+	var out []Interaction
+	for i, in := range t.cassette.Interactions {
+		if !t.used[i] {
+			out = append(out, in)
+		}
+	}
+	return out
+}
+
+// Save writes the recorded interactions to the cassette file, creating its
+// directory if needed. Interactions are ordered by method and URL so that
+// re-recording concurrent requests gives a stable file; identical requests
+// keep their recorded order. Save does nothing in ModeReplay.
+func (t *Transport) Save() error {
+	if t.mode != ModeRecord {
+		return nil
+	}
+	t.mu.Lock()
+	c := Cassette{Version: CassetteVersion, Interactions: append([]Interaction(nil), t.cassette.Interactions...)}
+	t.mu.Unlock()
+	sort.SliceStable(c.Interactions, func(i, j int) bool {
+		a, b := c.Interactions[i].Request, c.Interactions[j].Request
+		if a.URL != b.URL {
+			return a.URL < b.URL
+		}
+		return a.Method < b.Method
+	})
+
+	// One interaction per line keeps large bodies compact and diffs readable.
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "{\"version\":%d,\"interactions\":[\n", c.Version)
+	var line bytes.Buffer
+	enc := json.NewEncoder(&line)
+	enc.SetEscapeHTML(false)
+	for i, in := range c.Interactions {
+		line.Reset()
+		if err := enc.Encode(in); err != nil {
+			return fmt.Errorf("replay: encoding cassette: %w", err)
+		}
+		out.Write(bytes.TrimSuffix(line.Bytes(), []byte("\n")))
+		if i < len(c.Interactions)-1 {
+			out.WriteByte(',')
+		}
+		out.WriteByte('\n')
+	}
+	out.WriteString("]}\n")
+	data := out.Bytes()
+	if strings.HasSuffix(t.path, ".gz") {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(data)
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("replay: compressing cassette: %w", err)
+		}
+		data = buf.Bytes()
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return fmt.Errorf("replay: creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(t.path, data, 0o644); err != nil {
+		return fmt.Errorf("replay: writing cassette: %w", err)
+	}
+	return nil
+}
+
+// load reads and decodes a cassette file.
+func load(path string) (Cassette, error) {
+	var c Cassette
+	f, err := os.Open(path)
+	if err != nil {
+		return c, fmt.Errorf("replay: opening cassette: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return c, fmt.Errorf("replay: decompressing cassette %s: %w", path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return c, fmt.Errorf("replay: decoding cassette %s: %w", path, err)
+	}
+	if c.Version != CassetteVersion {
+		return c, fmt.Errorf("replay: cassette %s has version %d, want %d", path, c.Version, CassetteVersion)
+	}
+	return c, nil
+}
+
+// matchKey identifies a request for replay: method, path and sorted query,
+// with scrubbed parameters ignored.
+func matchKey(method string, u *url.URL) string {
+	q := u.Query()
+	for _, p := range ScrubQueryParams {
+		q.Del(p)
+	}
+	return method + " " + u.EscapedPath() + "?" + q.Encode()
+}
+
+// scrubRequest returns the recorded form of req with secrets redacted.
+func scrubRequest(req *http.Request) Request {
+	rec := Request{Method: req.Method, URL: scrubURL(req.URL)}
+	if len(req.Header) > 0 {
+		rec.Header = req.Header.Clone()
+		for _, h := range ScrubHeaders {
+			if rec.Header.Get(h) != "" {
+				rec.Header.Set(h, Redacted)
+			}
+		}
+	}
+	return rec
+}
+
+// scrubURL returns u as a string with scrubbed query parameters redacted.
+func scrubURL(u *url.URL) string {
+	clean := *u
+	q := clean.Query()
+	changed := false
+	for _, p := range ScrubQueryParams {
+		if q.Has(p) {
+			q.Set(p, Redacted)
+			changed = true
+		}
+	}
+	if changed {
+		clean.RawQuery = q.Encode()
+	}
+	return clean.String()
+}
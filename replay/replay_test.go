@@ -0,0 +1,162 @@
+package replay
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newUpstream returns a server answering with a per-path counter, so repeated
+// requests get distinguishable responses.
+func newUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+	var n atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/text":
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, "slow down")
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{ "n": `+string(rune('0'+n.Add(1)))+` }`)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, c *http.Client, url, key string) (int, string, error) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	if key != "" {
+		req.Header.Set("X-Riot-Token", key)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), nil
+}
+
+func recordCassette(t *testing.T, path string) {
+	t.Helper()
+	ts := newUpstream(t)
+	rec, err := New(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Next = ts.Client().Transport
+	c := &http.Client{Transport: rec}
+
+	for _, u := range []string{"/a?x=1&api_key=secret", "/a?x=1&api_key=secret", "/b", "/text"} {
+		if _, _, err := get(t, c, ts.URL+u, "RGAPI-secret"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+}
+
+func TestRecordScrubsSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	recordCassette(t, path)
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "secret") {
+		t.Errorf("cassette contains a secret:\n%s", raw)
+	}
+	if !strings.Contains(string(raw), Redacted) {
+		t.Errorf("cassette should mark redacted values:\n%s", raw)
+	}
+	if !strings.Contains(string(raw), `"body":{"n":1}`) {
+		t.Errorf("JSON bodies should be stored compacted as JSON:\n%s", raw)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	for _, name := range []string{"c.json", "c.json.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			recordCassette(t, path)
+
+			tr, err := New(path, ModeReplay)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			tr.Strict = true
+			c := &http.Client{Transport: tr}
+
+			// A different host and key replay the same recordings, in order.
+			base := "http://elsewhere.test"
+			want := []struct {
+				url    string
+				status int
+				body   string
+			}{
+				{"/b", 200, `{"n":3}`},
+				{"/a?api_key=other&x=1", 200, `{"n":1}`},
+				{"/a?x=1", 200, `{"n":2}`},
+				{"/text", 429, "slow down"},
+			}
+			for _, w := range want {
+				status, body, err := get(t, c, base+w.url, "RGAPI-other")
+				if err != nil {
+					t.Fatalf("GET %s: %v", w.url, err)
+				}
+				if status != w.status || body != w.body {
+					t.Errorf("GET %s = %d %q, want %d %q", w.url, status, body, w.status, w.body)
+				}
+			}
+			if unused := tr.Unused(); len(unused) != 0 {
+				t.Errorf("Unused() = %v, want none", unused)
+			}
+
+			// Strict mode rejects repeats beyond the recording and unknown requests.
+			for _, u := range []string{"/b", "/missing"} {
+				if _, _, err := get(t, c, base+u, ""); !errors.Is(err, ErrUnexpectedRequest) {
+					t.Errorf("GET %s: got %v, want ErrUnexpectedRequest", u, err)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayNonStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	recordCassette(t, path)
+	tr, err := New(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &http.Client{Transport: tr}
+
+	get(t, c, "http://x.test/b", "")
+	if status, body, _ := get(t, c, "http://x.test/b", ""); status != 200 || body != `{"n":3}` {
+		t.Errorf("repeat: got %d %q, want the last recording", status, body)
+	}
+	if status, _, err := get(t, c, "http://x.test/missing", ""); err != nil || status != http.StatusNotFound {
+		t.Errorf("unknown request: got %d, %v; want 404", status, err)
+	}
+	if n := len(tr.Unused()); n != 3 {
+		t.Errorf("Unused(): got %d, want 3", n)
+	}
+}
+
+func TestNewMissingCassette(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "nope.json"), ModeReplay); err == nil {
+		t.Error("expected an error for a missing cassette")
+	}
+}
@@ -0,0 +1,46 @@
+package replay
+
+import (
+	"net/http"
+	"os"
+	"testing"
+)
+
+// RecordEnv is the environment variable that makes ForTest re-record
+// cassettes instead of replaying them.
+const RecordEnv = "LOLMATCHUP_RECORD"
+
+// Recording reports whether ForTest records (RecordEnv is set).
+func Recording() bool {
+	return os.Getenv(RecordEnv) != ""
+}
+
+// ForTest returns a transport for a test's cassette. When Recording, requests
+// go through next and the cassette is saved when the test finishes. Otherwise
+// the cassette is replayed in strict mode, and interactions left unused fail
+// the test.
+func ForTest(tb testing.TB, path string, next http.RoundTripper) *Transport {
+	tb.Helper()
+	if Recording() {
+		t, _ := New(path, ModeRecord)
+		t.Next = next
+		tb.Cleanup(func() {
+			if err := t.Save(); err != nil {
+				tb.Errorf("saving cassette: %v", err)
+			}
+		})
+		return t
+	}
+
+	t, err := New(path, ModeReplay)
+	if err != nil {
+		tb.Fatalf("%v (record it with %s=1)", err, RecordEnv)
+	}
+	t.Strict = true
+	tb.Cleanup(func() {
+		for _, in := range t.Unused() {
+			tb.Errorf("cassette %s: interaction not replayed: %s %s", path, in.Request.Method, in.Request.URL)
+		}
+	})
+	return t
+}
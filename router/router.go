@@ -2,30 +2,67 @@ package router
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/klnstprx/lolMatchup/auth"
 	"github.com/klnstprx/lolMatchup/client"
 	"github.com/klnstprx/lolMatchup/config"
+	"github.com/klnstprx/lolMatchup/data"
 	"github.com/klnstprx/lolMatchup/handlers"
+	"github.com/klnstprx/lolMatchup/metrics"
 	"github.com/klnstprx/lolMatchup/middleware"
+	"github.com/klnstprx/lolMatchup/notes"
 	"github.com/klnstprx/lolMatchup/renderer"
 	"github.com/klnstprx/lolMatchup/static"
 	"golang.org/x/time/rate"
 )
 
 // SetupRouter configures Gin, applying custom renderer and middleware,
-// then registers routes.
-func SetupRouter(cfg *config.AppConfig, apiClient *client.Client) *gin.Engine {
+// then registers routes. loader backs the admin cache refresh; authn decides
+// who may use the site and its admin actions; notesStore holds the team's
+// matchup notes.
+func SetupRouter(cfg *config.AppConfig, apiClient *client.Client, loader *data.DataLoader, authn *auth.Authenticator, notesStore *notes.Store) *gin.Engine {
 	r := gin.New()
 
-	// Middlewares: request ID first (so it's available to the logger), then logging and recovery
+	// Middlewares: request ID first (so it's available to the logger and tracer),
+	// then tracing, logging and recovery
 	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.TracingMiddleware())
 	r.Use(middleware.LoggerMiddleware(cfg.Logger))
 	r.Use(middleware.RecoveryMiddleware(cfg.Logger))
+	r.Use(middleware.SnapshotBannerMiddleware(cfg.SnapshotPatch))
+	r.Use(middleware.KeyExpiredBannerMiddleware(apiClient.Keys.Expired))
+	r.Use(middleware.AuthMiddleware(authn))
 
 	// Serve embedded static files under /static
 	r.StaticFS("/static", http.FS(static.FS))
 
+	// Prometheus scrape endpoint — admins or the debug token once either
+	// guards the site, unless metrics_public says otherwise
+	requireAdmin := middleware.RequireAdmin(authn, cfg.DebugToken, "lolmatchup debug")
+	metricsHandler := gin.WrapH(metrics.Handler())
+	if cfg.MetricsPublic || !authn.Enabled() && cfg.DebugToken == "" {
+		r.GET("/metrics", metricsHandler)
+	} else {
+		r.GET("/metrics", requireAdmin, metricsHandler)
+	}
+
+	// Health and diagnostics
+	healthHandler := handlers.NewHealthHandler(cfg, apiClient)
+	if loader != nil {
+		healthHandler.Refresh = loader.Refresh
+		healthHandler.Warmup = loader.WarmupProgress
+	}
+	r.GET("/healthz", healthHandler.HealthzGET)
+	r.GET("/readyz", healthHandler.ReadyzGET)
+
+	// Admin actions — signed-in admins or the debug token
+	admin := r.Group("/debug", requireAdmin)
+	admin.GET("/status", healthHandler.DebugStatusGET)
+	admin.POST("/key", healthHandler.KeyPOST)
+	admin.POST("/cache/refresh", healthHandler.CacheRefreshPOST)
+
 	// Wrap default gin HTML renderer in our custom templ renderer
 	defaultGinRenderer := r.HTMLRender
 	r.HTMLRender = &renderer.HTMLTemplRenderer{
@@ -37,28 +74,72 @@ func SetupRouter(cfg *config.AppConfig, apiClient *client.Client) *gin.Engine {
 	autocompleteHandler := handlers.NewAutocompleteHandler(cfg, apiClient)
 	playerHandler := handlers.NewPlayerHandler(cfg, apiClient)
 	liveGameHandler := handlers.NewLiveGameHandler(cfg, apiClient)
+	liveGameHandler.Notes = notesStore
+	notesHandler := handlers.NewNotesHandler(cfg, notesStore)
+	matchupsHandler := handlers.NewMatchupsHandler(cfg)
 	matchHandler := handlers.NewMatchHandler(cfg, apiClient)
 	pageHandler := handlers.NewPageHandler(cfg, championHandler, playerHandler)
+	authHandler := handlers.NewAuthHandler(cfg, authn)
+
+	// Cache policies — private once pages are per-user and behind sign-in
+	visibility := "public"
+	if authn.Enabled() {
+		visibility = "private"
+	}
+	pageCache := middleware.CacheControl(visibility + ", max-age=300")
+	championCache := middleware.CacheControl(visibility + ", max-age=3600")
+	autocompleteCache := middleware.CacheControl(visibility + ", max-age=30")
+
+	// Sign-in, throttled per IP and per account name against password
+	// guessing; nothing the client sends unchecked picks the bucket
+	loginLimit := middleware.RateLimitConfig{
+		ClientRate:  perMinute(loginAttemptsPerMinute),
+		ClientBurst: loginAttemptsPerMinute,
+	}
+	loginIPLimiter := middleware.NewRateLimiter(loginLimit)
+	loginIPLimiter.Key = middleware.IPKey
+	loginNameLimiter := middleware.NewRateLimiter(loginLimit)
+	loginNameLimiter.Key = middleware.FormKey("username")
+	r.GET("/login", authHandler.LoginGET)
+	r.POST("/login", loginIPLimiter.Handler(1), loginNameLimiter.Handler(1), authHandler.LoginPOST)
+	r.POST("/logout", authHandler.LogoutPOST)
 
-	// Cache policies
-	pageCache := middleware.CacheControl("public, max-age=300")
-	championCache := middleware.CacheControl("public, max-age=3600")
-	autocompleteCache := middleware.CacheControl("public, max-age=30")
+	// Everything below requires sign-in when it is enabled
+	site := r.Group("/", middleware.RequireLogin(authn))
+	site.GET("/account", authHandler.AccountGET)
+	site.POST("/account/tokens", authHandler.TokenPOST)
+	site.POST("/account/tokens/revoke", authHandler.TokenRevokePOST)
 
 	// Page routes — content-negotiated (HTMX fragment or full page)
-	r.GET("/", pageCache, pageHandler.HomePageGET)
-	r.GET("/search", pageHandler.SearchGET)
-	r.GET("/champion", championCache, championHandler.ChampionGET)
-	r.GET("/autocomplete", autocompleteCache, autocompleteHandler.AutocompleteGET)
-
-	// Routes that call Riot API — rate limited, no cache (real-time data)
-	riotLimiter := middleware.RateLimitMiddleware(rate.Limit(15), 20)
-	r.GET("/player", riotLimiter, playerHandler.PlayerGET)
-	r.GET("/player/matches", riotLimiter, playerHandler.PlayerMatchesGET)
-	r.GET("/player/livegame", riotLimiter, liveGameHandler.PlayerLiveGameGET)
-	r.GET("/livegame", riotLimiter, liveGameHandler.LiveGameGET)
-	r.GET("/match", riotLimiter, matchHandler.MatchGET)
-	r.GET("/match/player", riotLimiter, matchHandler.MatchPlayerGET)
+	site.GET("/", pageCache, pageHandler.HomePageGET)
+	site.GET("/search", pageHandler.SearchGET)
+	site.GET("/champion", championCache, championHandler.ChampionGET)
+	site.GET("/champions", pageCache, championHandler.ChampionsGET)
+	site.GET("/matchups/:champion", pageCache, matchupsHandler.MatchupsGET)
+	site.GET("/autocomplete", autocompleteCache, autocompleteHandler.AutocompleteGET)
+
+	// Matchup notes — shared and editable, so never cached
+	site.GET("/notes", notesHandler.NotesGET)
+	site.POST("/notes", notesHandler.NotePOST)
+	site.POST("/notes/edit", notesHandler.NoteEditPOST)
+	site.POST("/notes/delete", notesHandler.NoteDeletePOST)
+
+	// Routes that call Riot API — rate limited per client and against the
+	// shared quota, charged by their upstream cost; no cache (real-time data)
+	riotLimiter := middleware.NewRateLimiter(middleware.RateLimitConfig{
+		Rate:        perMinute(cfg.RateLimitPerMinute),
+		Burst:       cfg.RateLimitBurst,
+		ClientRate:  perMinute(cfg.ClientRateLimitPerMinute),
+		ClientBurst: cfg.ClientRateLimitBurst,
+		MaxWait:     time.Duration(cfg.RateLimitQueueMillis) * time.Millisecond,
+	})
+	site.GET("/player", riotLimiter.Handler(costPlayer), playerHandler.PlayerGET)
+	site.GET("/player/matches", riotLimiter.Handler(costMatchHistory), playerHandler.PlayerMatchesGET)
+	site.GET("/player/profile", riotLimiter.Handler(1+cfg.ProfileMatchCount()), playerHandler.PlayerProfileGET) // match IDs and each match
+	site.GET("/player/livegame", riotLimiter.Handler(costLiveGame), liveGameHandler.PlayerLiveGameGET)
+	site.GET("/livegame", riotLimiter.Handler(costLiveGame), liveGameHandler.LiveGameGET)
+	site.GET("/match", riotLimiter.Handler(costMatch), matchHandler.MatchGET)
+	site.GET("/match/player", riotLimiter.Handler(costMatch), matchHandler.MatchPlayerGET)
 
 	// Legacy redirects — preserve query string for old bookmarks
 	r.GET("/champion-search", redirectWithQuery("/champion"))
@@ -68,6 +149,23 @@ func SetupRouter(cfg *config.AppConfig, apiClient *client.Client) *gin.Engine {
 	return r
 }
 
+// Rate limit cost of each Riot route: the upstream calls it makes when nothing
+// is cached.
+const (
+	costPlayer       = 14 // account, summoner, league, match IDs and 10 matches
+	costMatchHistory = 11 // match IDs and 10 matches
+	costLiveGame     = 52 // account and spectator, then league, match IDs and 8 matches per opponent
+	costMatch        = 1
+)
+
+// loginAttemptsPerMinute caps sign-in attempts per IP and per account name.
+const loginAttemptsPerMinute = 10
+
+// perMinute converts a per-minute config value to a rate.Limit.
+func perMinute(n int) rate.Limit {
+	return rate.Limit(float64(n) / 60)
+}
+
 // redirectWithQuery returns a handler that redirects to target, preserving the query string.
 func redirectWithQuery(target string) gin.HandlerFunc {
 	return func(c *gin.Context) {
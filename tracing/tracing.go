@@ -0,0 +1,137 @@
+// Package tracing configures OpenTelemetry trace export and provides helpers
+// for starting spans that carry the originating HTTP request ID.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName is reported as service.name on every exported span.
+const ServiceName = "lolmatchup"
+
+// instrumentationName identifies the tracer used by Start.
+const instrumentationName = "github.com/klnstprx/lolMatchup"
+
+// Exporter names accepted by Options.Exporter.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// RequestIDKey is the span attribute holding the HTTP request ID.
+const RequestIDKey = attribute.Key("request.id")
+
+// Options selects where spans are exported.
+type Options struct {
+	// Exporter is one of "none" (or empty), "otlp", "stdout" or "file".
+	Exporter string
+	// Endpoint is the OTLP/HTTP collector, either host:port or a full URL.
+	// When empty the standard OTEL_EXPORTER_OTLP_* environment variables apply.
+	Endpoint string
+	// File is the path spans are appended to when Exporter is "file".
+	File string
+}
+
+// Setup installs a global TracerProvider and W3C trace-context propagator for
+// the configured exporter. The returned function flushes and stops the
+// exporter; it is safe to call when tracing is disabled.
+func Setup(ctx context.Context, opts Options) (shutdown func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+	switch strings.ToLower(opts.Exporter) {
+	case "", ExporterNone:
+		return noop, nil
+	case ExporterOTLP:
+		var clientOpts []otlptracehttp.Option
+		switch {
+		case strings.Contains(opts.Endpoint, "://"):
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		case opts.Endpoint != "":
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(opts.Endpoint), otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, clientOpts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		if opts.File == "" {
+			return noop, fmt.Errorf("tracing exporter %q requires a file path", opts.Exporter)
+		}
+		f, openErr := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if openErr != nil {
+			return noop, fmt.Errorf("failed to open trace file: %w", openErr)
+		}
+		closer = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return noop, fmt.Errorf("unknown tracing exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		if closer != nil {
+			closer.Close()
+		}
+		return noop, fmt.Errorf("failed to create %s trace exporter: %w", opts.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", ServiceName)))
+	if err != nil {
+		res = resource.Default()
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the HTTP request ID so spans started
+// from it via Start are tagged with request.id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored by WithRequestID, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Start begins a span from the global TracerProvider. The request ID carried
+// by ctx, if any, is added to the span's attributes.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if id := RequestID(ctx); id != "" {
+		attrs = append(attrs, RequestIDKey.String(id))
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
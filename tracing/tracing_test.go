@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"disabled by default", Options{}, false},
+		{"explicit none", Options{Exporter: "none"}, false},
+		{"unknown exporter", Options{Exporter: "zipkin"}, true},
+		{"file without path", Options{Exporter: "file"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Setup error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if shutdown == nil {
+				t.Fatal("shutdown func should never be nil")
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Errorf("shutdown error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSetup_FileExporter(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown, err := Setup(context.Background(), Options{Exporter: ExporterFile, File: path})
+	if err != nil {
+		t.Fatalf("Setup error: %v", err)
+	}
+
+	_, span := Start(WithRequestID(context.Background(), "req-1"), "test.span")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	for _, want := range []string{"test.span", "req-1", ServiceName} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("trace file missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestStart_AddsRequestID(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	_, span := Start(context.Background(), "no.id")
+	span.End()
+	_, span = Start(WithRequestID(context.Background(), "abc"), "with.id")
+	span.End()
+
+	ended := rec.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(ended))
+	}
+	for _, kv := range ended[0].Attributes() {
+		if kv.Key == RequestIDKey {
+			t.Errorf("span without request ID should not carry %s", RequestIDKey)
+		}
+	}
+	var got string
+	for _, kv := range ended[1].Attributes() {
+		if kv.Key == RequestIDKey {
+			got = kv.Value.AsString()
+		}
+	}
+	if got != "abc" {
+		t.Errorf("request.id: got %q, want %q", got, "abc")
+	}
+}